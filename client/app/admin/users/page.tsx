@@ -9,11 +9,13 @@ interface AdminUser {
   display_name: string | null;
   bio: string | null;
   avatar_url: string | null;
+  role: string;
   created_at: string;
   updated_at: string;
 }
 
 const API_URL = process.env.NEXT_PUBLIC_API_URL || "http://localhost:8080";
+const ROLES = ["user", "moderator", "admin"];
 
 export default function AdminUsersPage() {
   const [users, setUsers] = useState<AdminUser[]>([]);
@@ -29,13 +31,18 @@ export default function AdminUsersPage() {
     setError(null);
 
     try {
-      const res = await fetch(`${API_URL}/api/admin/users`);
-      if (!res.ok) throw new Error("Failed to load users");
+      const res = await fetch(`${API_URL}/api/admin/users`, { credentials: "include" });
+      if (!res.ok) {
+        const payload = (await res.json().catch(() => null)) as
+          | { error?: string }
+          | null;
+        throw new Error(payload?.error || "Failed to load users");
+      }
       const data: AdminUser[] = await res.json();
       setUsers(data);
     } catch (err) {
       console.error("List users error:", err);
-      setError("Failed to load users");
+      setError(err instanceof Error ? err.message : "Failed to load users");
     } finally {
       setLoading(false);
     }
@@ -60,6 +67,7 @@ export default function AdminUsersPage() {
     try {
       const res = await fetch(`${API_URL}/api/admin/users`, {
         method: "POST",
+        credentials: "include",
         headers: {
           "Content-Type": "application/json",
         },
@@ -94,6 +102,7 @@ export default function AdminUsersPage() {
     try {
       const res = await fetch(`${API_URL}/api/admin/users/${id}`, {
         method: "DELETE",
+        credentials: "include",
       });
 
       if (!res.ok) {
@@ -113,6 +122,35 @@ export default function AdminUsersPage() {
     }
   };
 
+  const onChangeRole = async (id: number, targetUsername: string, role: string) => {
+    setError(null);
+    setSuccess(null);
+
+    try {
+      const res = await fetch(`${API_URL}/api/admin/users/${id}/role`, {
+        method: "PUT",
+        credentials: "include",
+        headers: {
+          "Content-Type": "application/json",
+        },
+        body: JSON.stringify({ role }),
+      });
+
+      if (!res.ok) {
+        const payload = (await res.json().catch(() => null)) as
+          | { error?: string }
+          | null;
+        throw new Error(payload?.error || "Failed to change role");
+      }
+
+      setSuccess(`Made "${targetUsername}" a ${role}`);
+      await fetchUsers();
+    } catch (err) {
+      console.error("Change role error:", err);
+      setError(err instanceof Error ? err.message : "Failed to change role");
+    }
+  };
+
   return (
     <div className="min-h-screen bg-zinc-50 px-4 py-10 dark:bg-zinc-950">
       <div className="mx-auto w-full max-w-3xl">
@@ -130,10 +168,10 @@ export default function AdminUsersPage() {
             My Log
           </Link>
           <Link
-            href="/admin/login"
+            href="/login"
             className="rounded-md border border-zinc-300 bg-white px-3 py-1.5 text-zinc-700 hover:bg-zinc-100 dark:border-zinc-700 dark:bg-zinc-900 dark:text-zinc-200 dark:hover:bg-zinc-800"
           >
-            Admin Login
+            Log In
           </Link>
           <Link
             href="/admin/users"
@@ -217,14 +255,27 @@ export default function AdminUsersPage() {
                       {new Date(user.created_at).toLocaleString()}
                     </p>
                   </div>
-                  <button
-                    type="button"
-                    disabled={deletingId === user.id}
-                    onClick={() => onDelete(user.id, user.username)}
-                    className="rounded-md border border-red-200 px-3 py-1.5 text-sm font-medium text-red-700 transition-colors hover:bg-red-50 disabled:cursor-not-allowed disabled:opacity-50 dark:border-red-900/60 dark:text-red-300 dark:hover:bg-red-950/40"
-                  >
-                    {deletingId === user.id ? "Deleting..." : "Delete"}
-                  </button>
+                  <div className="flex items-center gap-2">
+                    <select
+                      value={user.role}
+                      onChange={(e) => onChangeRole(user.id, user.username, e.target.value)}
+                      className="rounded-md border border-zinc-300 bg-white px-2 py-1.5 text-sm text-zinc-700 dark:border-zinc-600 dark:bg-zinc-900 dark:text-zinc-200"
+                    >
+                      {ROLES.map((role) => (
+                        <option key={role} value={role}>
+                          {role}
+                        </option>
+                      ))}
+                    </select>
+                    <button
+                      type="button"
+                      disabled={deletingId === user.id}
+                      onClick={() => onDelete(user.id, user.username)}
+                      className="rounded-md border border-red-200 px-3 py-1.5 text-sm font-medium text-red-700 transition-colors hover:bg-red-50 disabled:cursor-not-allowed disabled:opacity-50 dark:border-red-900/60 dark:text-red-300 dark:hover:bg-red-950/40"
+                    >
+                      {deletingId === user.id ? "Deleting..." : "Delete"}
+                    </button>
+                  </div>
                 </li>
               ))}
             </ul>
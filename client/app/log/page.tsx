@@ -20,8 +20,12 @@ interface MovieLogEntry {
   updated_at: string;
 }
 
+interface MovieLogPage {
+  entries: MovieLogEntry[];
+  next_cursor: string | null;
+}
+
 const API_URL = process.env.NEXT_PUBLIC_API_URL || "http://localhost:8080";
-const ACTIVE_USER_KEY = "moviestack_active_user";
 
 export default function MovieLogPage() {
   const [activeUser, setActiveUser] = useState<ActiveUser | null>(null);
@@ -31,38 +35,45 @@ export default function MovieLogPage() {
   const [deletingLogId, setDeletingLogId] = useState<number | null>(null);
 
   useEffect(() => {
-    const raw = window.localStorage.getItem(ACTIVE_USER_KEY);
-    if (!raw) {
-      setLoading(false);
-      return;
-    }
-
-    try {
-      const parsed = JSON.parse(raw) as ActiveUser;
-      if (parsed && typeof parsed.id === "number" && typeof parsed.username === "string") {
-        setActiveUser(parsed);
-      } else {
-        window.localStorage.removeItem(ACTIVE_USER_KEY);
+    const fetchCurrentUser = async () => {
+      try {
+        const res = await fetch(`${API_URL}/api/auth/me`, { credentials: "include" });
+        if (res.ok) {
+          setActiveUser((await res.json()) as ActiveUser);
+        }
+      } catch (err) {
+        console.error("Load current user error:", err);
+      } finally {
+        setLoading(false);
       }
-    } catch {
-      window.localStorage.removeItem(ACTIVE_USER_KEY);
-    } finally {
-      setLoading(false);
-    }
+    };
+
+    fetchCurrentUser();
   }, []);
 
   const fetchLog = async (userId: number) => {
     setLoading(true);
     setError(null);
     try {
-      const res = await fetch(`${API_URL}/api/users/${userId}/log`);
-      if (!res.ok) {
-        const payload = (await res.json().catch(() => null)) as { error?: string } | null;
-        throw new Error(payload?.error || "Failed to load movie log");
-      }
+      const loaded: MovieLogEntry[] = [];
+      let cursor: string | null = null;
+      do {
+        const params = new URLSearchParams({ limit: "200" });
+        if (cursor) params.set("cursor", cursor);
+
+        const res = await fetch(`${API_URL}/api/users/${userId}/log?${params}`, {
+          credentials: "include",
+        });
+        if (!res.ok) {
+          const payload = (await res.json().catch(() => null)) as { error?: string } | null;
+          throw new Error(payload?.error || "Failed to load movie log");
+        }
 
-      const data: MovieLogEntry[] = await res.json();
-      setEntries(data);
+        const page: MovieLogPage = await res.json();
+        loaded.push(...page.entries);
+        cursor = page.next_cursor;
+      } while (cursor);
+      setEntries(loaded);
     } catch (err) {
       console.error("Load movie log error:", err);
       setError(err instanceof Error ? err.message : "Failed to load movie log");
@@ -94,6 +105,7 @@ export default function MovieLogPage() {
     try {
       const res = await fetch(`${API_URL}/api/users/${activeUser.id}/log/${entry.log_id}`, {
         method: "DELETE",
+        credentials: "include",
       });
       if (!res.ok) {
         const payload = (await res.json().catch(() => null)) as { error?: string } | null;
@@ -126,10 +138,10 @@ export default function MovieLogPage() {
             My Log
           </Link>
           <Link
-            href="/admin/login"
+            href="/login"
             className="rounded-md border border-zinc-300 bg-white px-3 py-1.5 text-zinc-700 hover:bg-zinc-100 dark:border-zinc-700 dark:bg-zinc-900 dark:text-zinc-200 dark:hover:bg-zinc-800"
           >
-            Admin Login
+            Log In
           </Link>
           <Link
             href="/admin/users"
@@ -145,12 +157,12 @@ export default function MovieLogPage() {
 
         {!activeUser && !loading ? (
           <p className="rounded-xl border border-zinc-200 bg-white px-4 py-3 text-sm text-zinc-600 dark:border-zinc-700 dark:bg-zinc-900 dark:text-zinc-400">
-            No active user selected. Go to <Link href="/admin/login" className="underline">/admin/login</Link>{" "}
-            to pick one.
+            Not logged in. <Link href="/login" className="underline">Log in</Link> to see your
+            log.
           </p>
         ) : (
           <p className="mb-4 text-sm text-zinc-600 dark:text-zinc-400">
-            Viewing as <span className="font-semibold">{activeUser?.username}</span>
+            Logged in as <span className="font-semibold">{activeUser?.username}</span>
           </p>
         )}
 
@@ -0,0 +1,185 @@
+"use client";
+
+import Link from "next/link";
+import { useEffect, useState } from "react";
+
+interface AuthUser {
+  id: number;
+  username: string;
+}
+
+type Mode = "login" | "signup";
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || "http://localhost:8080";
+
+export default function LoginPage() {
+  const [mode, setMode] = useState<Mode>("login");
+  const [username, setUsername] = useState("");
+  const [password, setPassword] = useState("");
+  const [submitting, setSubmitting] = useState(false);
+  const [error, setError] = useState<string | null>(null);
+  const [currentUser, setCurrentUser] = useState<AuthUser | null>(null);
+  const [ssoEnabled, setSSOEnabled] = useState(false);
+
+  useEffect(() => {
+    const fetchCurrentUser = async () => {
+      try {
+        const res = await fetch(`${API_URL}/api/auth/me`, { credentials: "include" });
+        if (res.ok) {
+          setCurrentUser((await res.json()) as AuthUser);
+        }
+      } catch (err) {
+        console.error("Load current user error:", err);
+      }
+    };
+
+    const fetchSSOStatus = async () => {
+      try {
+        const res = await fetch(`${API_URL}/api/auth/oidc`);
+        if (res.ok) {
+          const payload = (await res.json()) as { enabled: boolean };
+          setSSOEnabled(payload.enabled);
+        }
+      } catch (err) {
+        console.error("Load SSO status error:", err);
+      }
+    };
+
+    fetchCurrentUser();
+    fetchSSOStatus();
+  }, []);
+
+  const onSubmit = async (e: React.FormEvent<HTMLFormElement>) => {
+    e.preventDefault();
+    setSubmitting(true);
+    setError(null);
+    try {
+      const res = await fetch(`${API_URL}/api/auth/${mode}`, {
+        method: "POST",
+        credentials: "include",
+        headers: {
+          "Content-Type": "application/json",
+        },
+        body: JSON.stringify({ username, password }),
+      });
+      if (!res.ok) {
+        const payload = (await res.json().catch(() => null)) as { error?: string } | null;
+        throw new Error(payload?.error || (mode === "login" ? "Failed to log in" : "Failed to sign up"));
+      }
+
+      window.location.href = "/";
+    } catch (err) {
+      console.error("Auth error:", err);
+      setError(err instanceof Error ? err.message : "Failed to log in");
+    } finally {
+      setSubmitting(false);
+    }
+  };
+
+  const onLogout = async () => {
+    try {
+      await fetch(`${API_URL}/api/auth/logout`, { method: "POST", credentials: "include" });
+      setCurrentUser(null);
+    } catch (err) {
+      console.error("Logout error:", err);
+    }
+  };
+
+  return (
+    <div className="min-h-screen bg-zinc-50 px-4 py-10 dark:bg-zinc-950">
+      <div className="mx-auto w-full max-w-md">
+        <div className="mb-4 flex flex-wrap gap-2 text-sm">
+          <Link
+            href="/"
+            className="rounded-md border border-zinc-300 bg-white px-3 py-1.5 text-zinc-700 hover:bg-zinc-100 dark:border-zinc-700 dark:bg-zinc-900 dark:text-zinc-200 dark:hover:bg-zinc-800"
+          >
+            Movie Search
+          </Link>
+          <Link
+            href="/log"
+            className="rounded-md border border-zinc-300 bg-white px-3 py-1.5 text-zinc-700 hover:bg-zinc-100 dark:border-zinc-700 dark:bg-zinc-900 dark:text-zinc-200 dark:hover:bg-zinc-800"
+          >
+            My Log
+          </Link>
+        </div>
+
+        <h1 className="mb-6 text-3xl font-bold tracking-tight text-zinc-900 dark:text-zinc-100">
+          {mode === "login" ? "Log In" : "Sign Up"}
+        </h1>
+
+        {currentUser && (
+          <div className="mb-4 flex items-center justify-between rounded-xl border border-zinc-200 bg-white px-4 py-3 shadow-sm dark:border-zinc-700 dark:bg-zinc-900">
+            <p className="text-sm text-zinc-700 dark:text-zinc-300">
+              Logged in as <span className="font-semibold">{currentUser.username}</span>
+            </p>
+            <button
+              type="button"
+              onClick={onLogout}
+              className="rounded-md border border-zinc-300 px-3 py-1.5 text-sm font-medium text-zinc-700 hover:bg-zinc-100 dark:border-zinc-600 dark:text-zinc-200 dark:hover:bg-zinc-800"
+            >
+              Log out
+            </button>
+          </div>
+        )}
+
+        {error && (
+          <p className="mb-4 rounded-lg border border-red-200 bg-red-50 px-3 py-2 text-sm text-red-700 dark:border-red-900/60 dark:bg-red-950/40 dark:text-red-300">
+            {error}
+          </p>
+        )}
+
+        <form
+          onSubmit={onSubmit}
+          className="space-y-3 rounded-xl border border-zinc-200 bg-white p-4 shadow-sm dark:border-zinc-700 dark:bg-zinc-900"
+        >
+          <input
+            type="text"
+            value={username}
+            onChange={(e) => setUsername(e.target.value)}
+            placeholder="Username"
+            autoComplete="username"
+            className="w-full rounded-md border border-zinc-300 bg-white px-3 py-2 text-sm text-zinc-900 outline-none focus:border-zinc-500 dark:border-zinc-600 dark:bg-zinc-950 dark:text-zinc-100"
+          />
+          <input
+            type="password"
+            value={password}
+            onChange={(e) => setPassword(e.target.value)}
+            placeholder="Password"
+            autoComplete={mode === "login" ? "current-password" : "new-password"}
+            className="w-full rounded-md border border-zinc-300 bg-white px-3 py-2 text-sm text-zinc-900 outline-none focus:border-zinc-500 dark:border-zinc-600 dark:bg-zinc-950 dark:text-zinc-100"
+          />
+          <button
+            type="submit"
+            disabled={submitting}
+            className="w-full rounded-md bg-zinc-900 px-3 py-2 text-sm font-medium text-white hover:bg-zinc-700 disabled:cursor-not-allowed disabled:opacity-50 dark:bg-zinc-100 dark:text-zinc-900 dark:hover:bg-zinc-300"
+          >
+            {submitting ? "Please wait..." : mode === "login" ? "Log in" : "Create account"}
+          </button>
+        </form>
+
+        {ssoEnabled && (
+          <a
+            href={`${API_URL}/api/auth/oidc/login`}
+            className="mt-3 block w-full rounded-md border border-zinc-300 bg-white px-3 py-2 text-center text-sm font-medium text-zinc-700 hover:bg-zinc-100 dark:border-zinc-600 dark:bg-zinc-900 dark:text-zinc-200 dark:hover:bg-zinc-800"
+          >
+            Sign in with SSO
+          </a>
+        )}
+
+        <p className="mt-4 text-sm text-zinc-600 dark:text-zinc-400">
+          {mode === "login" ? "No account yet? " : "Already have an account? "}
+          <button
+            type="button"
+            onClick={() => {
+              setMode(mode === "login" ? "signup" : "login");
+              setError(null);
+            }}
+            className="underline"
+          >
+            {mode === "login" ? "Sign up" : "Log in"}
+          </button>
+        </p>
+      </div>
+    </div>
+  );
+}
@@ -18,7 +18,6 @@ interface ActiveUser {
 }
 
 const API_URL = process.env.NEXT_PUBLIC_API_URL || "http://localhost:8080";
-const ACTIVE_USER_KEY = "moviestack_active_user";
 
 export default function Home() {
   const [query, setQuery] = useState("");
@@ -32,16 +31,18 @@ export default function Home() {
   const abortRef = useRef<AbortController | null>(null);
 
   useEffect(() => {
-    const raw = window.localStorage.getItem(ACTIVE_USER_KEY);
-    if (!raw) return;
-    try {
-      const parsed = JSON.parse(raw) as ActiveUser;
-      if (parsed && typeof parsed.id === "number" && typeof parsed.username === "string") {
-        setActiveUser(parsed);
+    const fetchCurrentUser = async () => {
+      try {
+        const res = await fetch(`${API_URL}/api/auth/me`, { credentials: "include" });
+        if (res.ok) {
+          setActiveUser((await res.json()) as ActiveUser);
+        }
+      } catch (err) {
+        console.error("Load current user error:", err);
       }
-    } catch {
-      window.localStorage.removeItem(ACTIVE_USER_KEY);
-    }
+    };
+
+    fetchCurrentUser();
   }, []);
 
   useEffect(() => {
@@ -77,9 +78,13 @@ export default function Home() {
     return () => clearTimeout(timeout);
   }, [query]);
 
-  const onClearUser = () => {
-    window.localStorage.removeItem(ACTIVE_USER_KEY);
-    setActiveUser(null);
+  const onLogout = async () => {
+    try {
+      await fetch(`${API_URL}/api/auth/logout`, { method: "POST", credentials: "include" });
+      setActiveUser(null);
+    } catch (err) {
+      console.error("Logout error:", err);
+    }
   };
 
   const addToLog = async (movie: Movie) => {
@@ -91,6 +96,7 @@ export default function Home() {
     try {
       const res = await fetch(`${API_URL}/api/users/${activeUser.id}/log`, {
         method: "POST",
+        credentials: "include",
         headers: {
           "Content-Type": "application/json",
         },
@@ -131,10 +137,10 @@ export default function Home() {
           My Log
         </Link>
         <Link
-          href="/admin/login"
+          href="/login"
           className="rounded-md border border-zinc-300 bg-white px-3 py-1.5 text-zinc-700 hover:bg-zinc-100 dark:border-zinc-700 dark:bg-zinc-900 dark:text-zinc-200 dark:hover:bg-zinc-800"
         >
-          Admin Login
+          Log In
         </Link>
         <Link
           href="/admin/users"
@@ -148,20 +154,19 @@ export default function Home() {
         {activeUser ? (
           <div className="flex items-center justify-between gap-3">
             <p className="text-zinc-700 dark:text-zinc-300">
-              Viewing as <span className="font-semibold">{activeUser.username}</span> (ID:{" "}
-              {activeUser.id})
+              Logged in as <span className="font-semibold">{activeUser.username}</span>
             </p>
             <button
               type="button"
-              onClick={onClearUser}
+              onClick={onLogout}
               className="rounded-md border border-zinc-300 px-2.5 py-1 text-zinc-700 hover:bg-zinc-100 dark:border-zinc-600 dark:text-zinc-200 dark:hover:bg-zinc-800"
             >
-              Clear
+              Log out
             </button>
           </div>
         ) : (
           <p className="text-zinc-600 dark:text-zinc-400">
-            No active user selected. Go to <code>/admin/login</code> to pick one before adding
+            Not logged in. <Link href="/login" className="underline">Log in</Link> before adding
             movies to your log.
           </p>
         )}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// createUser creates a user without a password, as admins do. The user can
+// sign in once an admin gives them one with setUserPassword.
+func createUser(ctx context.Context, pool *pgxpool.Pool, queries *db.Queries, source auditSource, username string) (db.User, error) {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return db.User{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := queries.WithTx(tx)
+	user, err := qtx.CreateUser(ctx, username)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "users_username_lower_unique" {
+			return db.User{}, errUsernameTaken
+		}
+		return db.User{}, fmt.Errorf("create user: %w", err)
+	}
+	if err := recordAudit(ctx, qtx, source, auditEvent{
+		Action:     auditUserCreate,
+		TargetType: "user",
+		TargetID:   auditTarget(user.ID),
+		After:      user,
+	}); err != nil {
+		return db.User{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return db.User{}, fmt.Errorf("commit transaction: %w", err)
+	}
+	return user, nil
+}
+
+// deleteUser deletes a user and everything they own, keeping a snapshot of
+// the user in the audit log.
+func deleteUser(ctx context.Context, pool *pgxpool.Pool, queries *db.Queries, source auditSource, userID int64) error {
+	return withUserTx(ctx, pool, queries, userID, func(qtx *db.Queries) error {
+		user, err := qtx.GetUser(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("get user: %w", err)
+		}
+		if _, err := qtx.DeleteUser(ctx, userID); err != nil {
+			return fmt.Errorf("delete user: %w", err)
+		}
+		return recordAudit(ctx, qtx, source, auditEvent{
+			Action:     auditUserDelete,
+			TargetType: "user",
+			TargetID:   auditTarget(userID),
+			Before:     user,
+		})
+	})
+}
+
+// setUserPassword gives a user a password, replacing any they had, and signs
+// them out everywhere. The audit record leaves the password hash out.
+func setUserPassword(ctx context.Context, pool *pgxpool.Pool, queries *db.Queries, source auditSource, userID int64, password string) error {
+	if err := checkPassword(password); err != nil {
+		return err
+	}
+	passwordHash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	return withUserTx(ctx, pool, queries, userID, func(qtx *db.Queries) error {
+		if err := qtx.SetUserCredentials(ctx, db.SetUserCredentialsParams{
+			UserID:       userID,
+			PasswordHash: passwordHash,
+		}); err != nil {
+			return fmt.Errorf("set credentials: %w", err)
+		}
+		if err := qtx.DeleteUserSessionsByUser(ctx, userID); err != nil {
+			return fmt.Errorf("delete sessions: %w", err)
+		}
+		return recordAudit(ctx, qtx, source, auditEvent{
+			Action:     auditUserPasswordSet,
+			TargetType: "user",
+			TargetID:   auditTarget(userID),
+		})
+	})
+}
@@ -0,0 +1,426 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// The archive format is versioned so restore can refuse documents it does
+// not understand instead of half-loading them.
+const (
+	accountArchiveFormat  = "moviestack-archive"
+	accountArchiveVersion = 1
+)
+
+// maxAccountArchiveSize bounds the restore request body, which is decoded in
+// memory.
+const maxAccountArchiveSize = 64 << 20
+
+var errArchiveUnsupported = errors.New("archive format or version is not supported")
+
+// buildAccountArchive collects the user's profile, whole log, and the
+// comments and likes they left. qtx should read from a single snapshot so
+// entries, viewings and ranks agree.
+func buildAccountArchive(ctx context.Context, qtx *db.Queries, userID int64) (AccountArchive, error) {
+	user, err := qtx.GetUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return AccountArchive{}, errUserNotFound
+		}
+		return AccountArchive{}, fmt.Errorf("get user: %w", err)
+	}
+
+	viewingRows, err := qtx.ListMovieLogViewingsByUser(ctx, userID)
+	if err != nil {
+		return AccountArchive{}, fmt.Errorf("list viewings: %w", err)
+	}
+	viewings := make(map[int64][]AccountArchiveViewing)
+	for _, viewing := range viewingRows {
+		viewings[viewing.LogID] = append(viewings[viewing.LogID], AccountArchiveViewing{
+			WatchedOn: dateISO(viewing.WatchedOn),
+			Note:      textPtr(viewing.Note),
+		})
+	}
+
+	archive := AccountArchive{
+		Format:     accountArchiveFormat,
+		Version:    accountArchiveVersion,
+		ExportedAt: time.Now().UTC().Format(time.RFC3339),
+		Profile: AccountArchiveProfile{
+			Username:      user.Username,
+			DisplayName:   textPtr(user.DisplayName),
+			Bio:           textPtr(user.Bio),
+			AvatarURL:     textPtr(user.AvatarUrl),
+			LogVisibility: &user.LogVisibility,
+			HideNotes:     user.HideNotes,
+			CreatedAt:     timestamptzRFC3339(user.CreatedAt),
+		},
+		Entries: []AccountArchiveEntry{},
+	}
+
+	following, err := qtx.ListFollowing(ctx, userID)
+	if err != nil {
+		return AccountArchive{}, fmt.Errorf("list following: %w", err)
+	}
+	archive.Following = make([]string, len(following))
+	for i, followee := range following {
+		archive.Following[i] = followee.Username
+	}
+
+	comments, err := qtx.ListLogCommentsByAuthor(ctx, userID)
+	if err != nil {
+		return AccountArchive{}, fmt.Errorf("list comments: %w", err)
+	}
+	archive.Comments = make([]AccountArchiveComment, len(comments))
+	for i, comment := range comments {
+		archive.Comments[i] = AccountArchiveComment{
+			Owner:         comment.OwnerUsername,
+			TMDBID:        comment.MovieID,
+			OriginalTitle: comment.OriginalTitle,
+			ReplyTo:       textPtr(comment.ReplyTo),
+			Body:          comment.Body,
+			CreatedAt:     timestamptzRFC3339(comment.CreatedAt),
+			UpdatedAt:     timestamptzRFC3339(comment.UpdatedAt),
+		}
+	}
+
+	likes, err := qtx.ListLogLikesByUser(ctx, userID)
+	if err != nil {
+		return AccountArchive{}, fmt.Errorf("list likes: %w", err)
+	}
+	archive.Likes = make([]AccountArchiveLike, len(likes))
+	for i, like := range likes {
+		archive.Likes[i] = AccountArchiveLike{
+			Owner:         like.OwnerUsername,
+			TMDBID:        like.MovieID,
+			OriginalTitle: like.OriginalTitle,
+			CreatedAt:     timestamptzRFC3339(like.CreatedAt),
+		}
+	}
+
+	var afterID int64
+	for {
+		rows, err := qtx.ExportMovieLogPage(ctx, db.ExportMovieLogPageParams{
+			ShowNotes:    true,
+			UserID:       userID,
+			AfterID:      afterID,
+			Visibilities: logVisibilities,
+			PageSize:     exportPageSize,
+		})
+		if err != nil {
+			return AccountArchive{}, fmt.Errorf("export log page: %w", err)
+		}
+
+		for _, row := range rows {
+			entryViewings := viewings[row.LogID]
+			if entryViewings == nil {
+				entryViewings = []AccountArchiveViewing{}
+			}
+			archive.Entries = append(archive.Entries, AccountArchiveEntry{
+				TMDBID:        row.MovieID,
+				OriginalTitle: row.OriginalTitle,
+				WatchedOn:     dateISO(row.WatchedOn),
+				Note:          textPtr(row.Note),
+				Rating:        numericFloatPtr(row.Rating),
+				Sentiment:     textPtr(row.Sentiment),
+				RankPosition:  int4Ptr(row.RankPosition),
+				Tags:          tagNames(row.Tags),
+				Viewings:      entryViewings,
+				Visibility:    textPtr(row.Visibility),
+				CreatedAt:     timestamptzRFC3339(row.CreatedAt),
+			})
+			afterID = row.LogID
+		}
+
+		if len(rows) < exportPageSize {
+			break
+		}
+	}
+
+	return archive, nil
+}
+
+// restoredRank remembers where a restored entry sat in the archived ranking
+// so ranks can be rebuilt once every entry exists.
+type restoredRank struct {
+	logID     int64
+	sentiment string
+	position  int32
+}
+
+// restoreAccountArchive merges an archive into the user's account. Movies
+// already in the log are left untouched, profile fields are only filled where
+// the account has none, privacy settings only ever get stricter, and ranked
+// entries are appended to the end of their
+// sentiment band in archived order, so restoring into a fresh account
+// reproduces the original ranking exactly. Follows are restored as follow
+// requests, since the other users have to accept them again; the ids of the
+// users requested are returned so the caller can notify them once the restore
+// commits. Comments and likes are exported for the record but not restored:
+// the entries they were left on belong to other accounts, which may not exist
+// on this instance.
+func restoreAccountArchive(ctx context.Context, qtx *db.Queries, userID int64, archive AccountArchive) (ArchiveRestoreResponse, []int64, error) {
+	response := ArchiveRestoreResponse{
+		EntryCount: len(archive.Entries),
+		Unrestored: []UnrestoredEntryResponse{},
+	}
+
+	profile := archive.Profile
+	if _, err := qtx.FillUserProfile(ctx, db.FillUserProfileParams{
+		DisplayName: trimmedText(profile.DisplayName),
+		Bio:         trimmedText(profile.Bio),
+		AvatarUrl:   trimmedText(profile.AvatarURL),
+		ID:          userID,
+	}); err != nil {
+		return ArchiveRestoreResponse{}, nil, fmt.Errorf("fill profile: %w", err)
+	}
+	if err := restoreArchivePrivacy(ctx, qtx, userID, profile); err != nil {
+		return ArchiveRestoreResponse{}, nil, err
+	}
+
+	movieIDs := make([]int32, len(archive.Entries))
+	for i, entry := range archive.Entries {
+		movieIDs[i] = entry.TMDBID
+	}
+	knownMovies := make(map[int32]bool)
+	if len(movieIDs) > 0 {
+		movies, err := qtx.ListMoviesByIDs(ctx, movieIDs)
+		if err != nil {
+			return ArchiveRestoreResponse{}, nil, fmt.Errorf("list archived movies: %w", err)
+		}
+		for _, movie := range movies {
+			knownMovies[movie.ID] = true
+		}
+	}
+
+	var ranks []restoredRank
+	for _, entry := range archive.Entries {
+		unrestored := func(reason string) {
+			response.Unrestored = append(response.Unrestored, UnrestoredEntryResponse{
+				TMDBID:        entry.TMDBID,
+				OriginalTitle: entry.OriginalTitle,
+				Reason:        reason,
+			})
+		}
+
+		if !knownMovies[entry.TMDBID] {
+			unrestored("movie not found")
+			continue
+		}
+
+		logID, err := restoreArchiveEntry(ctx, qtx, userID, entry)
+		if err != nil {
+			var invalid archiveEntryError
+			if errors.As(err, &invalid) {
+				unrestored(invalid.reason)
+				continue
+			}
+			return ArchiveRestoreResponse{}, nil, err
+		}
+		response.RestoredCount++
+
+		if entry.Sentiment == nil || sentimentBand(*entry.Sentiment) < 0 {
+			continue
+		}
+		if entry.RankPosition == nil {
+			if err := qtx.SetMovieLogSentiment(ctx, db.SetMovieLogSentimentParams{
+				Sentiment: pgtype.Text{String: *entry.Sentiment, Valid: true},
+				ID:        logID,
+				UserID:    userID,
+			}); err != nil {
+				return ArchiveRestoreResponse{}, nil, fmt.Errorf("set sentiment: %w", err)
+			}
+			continue
+		}
+		ranks = append(ranks, restoredRank{
+			logID:     logID,
+			sentiment: *entry.Sentiment,
+			position:  *entry.RankPosition,
+		})
+	}
+
+	requested, err := restoreArchiveFollowing(ctx, qtx, userID, archive.Following)
+	if err != nil {
+		return ArchiveRestoreResponse{}, nil, err
+	}
+	response.FollowRequestCount = len(requested)
+
+	sort.SliceStable(ranks, func(i, j int) bool {
+		return ranks[i].position < ranks[j].position
+	})
+	for _, rank := range ranks {
+		if _, err := insertAtRank(ctx, qtx, userID, rank.logID, rank.sentiment, math.MaxInt32); err != nil {
+			return ArchiveRestoreResponse{}, nil, err
+		}
+	}
+
+	return response, requested, nil
+}
+
+// archiveEntryError explains why one archived entry was skipped without
+// failing the rest of the restore.
+type archiveEntryError struct {
+	reason string
+}
+
+func (e archiveEntryError) Error() string {
+	return e.reason
+}
+
+// restoreArchiveEntry creates a log entry with its viewings and tags. The
+// entry is validated before anything is written, so a rejected entry leaves
+// no trace.
+func restoreArchiveEntry(ctx context.Context, qtx *db.Queries, userID int64, entry AccountArchiveEntry) (int64, error) {
+	watchedOn, err := parseWatchedOn(&entry.WatchedOn)
+	if err != nil {
+		return 0, archiveEntryError{"watched_on must be in YYYY-MM-DD format"}
+	}
+
+	viewingDates := make([]pgtype.Date, len(entry.Viewings))
+	for i, viewing := range entry.Viewings {
+		viewingDates[i], err = parseWatchedOn(&viewing.WatchedOn)
+		if err != nil || strings.TrimSpace(viewing.WatchedOn) == "" {
+			return 0, archiveEntryError{"viewing watched_on must be in YYYY-MM-DD format"}
+		}
+	}
+
+	rating, err := parseRating(entry.Rating)
+	if err != nil {
+		return 0, archiveEntryError{err.Error()}
+	}
+
+	visibility, err := parseVisibility(entry.Visibility)
+	if err != nil {
+		return 0, archiveEntryError{err.Error()}
+	}
+
+	names, err := normalizeTagNames(entry.Tags)
+	if err != nil {
+		return 0, archiveEntryError{err.Error()}
+	}
+
+	note := trimmedText(entry.Note)
+	created, err := qtx.CreateMovieLogEntry(ctx, db.CreateMovieLogEntryParams{
+		UserID:     userID,
+		MovieID:    entry.TMDBID,
+		WatchedOn:  watchedOn,
+		Note:       note,
+		Rating:     rating,
+		Visibility: visibility,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, archiveEntryError{"already logged"}
+		}
+		return 0, fmt.Errorf("create log entry: %w", err)
+	}
+
+	if len(entry.Viewings) == 0 {
+		if err := qtx.AddFirstMovieLogViewing(ctx, db.AddFirstMovieLogViewingParams{
+			LogID:     created.ID,
+			WatchedOn: watchedOn,
+			Note:      note,
+		}); err != nil {
+			return 0, fmt.Errorf("create viewing: %w", err)
+		}
+	}
+	for i, viewing := range entry.Viewings {
+		if _, err := qtx.CreateMovieLogViewing(ctx, db.CreateMovieLogViewingParams{
+			LogID:     created.ID,
+			WatchedOn: viewingDates[i],
+			Note:      trimmedText(viewing.Note),
+		}); err != nil {
+			return 0, fmt.Errorf("create viewing: %w", err)
+		}
+	}
+
+	if _, err := qtx.SyncMovieLogWatchedOn(ctx, created.ID); err != nil {
+		return 0, fmt.Errorf("sync watched_on: %w", err)
+	}
+
+	if err := addMovieLogTags(ctx, qtx, userID, created.ID, names); err != nil {
+		return 0, err
+	}
+	return created.ID, nil
+}
+
+// restoreArchivePrivacy applies the archived privacy settings where they are
+// stricter than the account's, so restoring never exposes entries the archive
+// kept hidden.
+func restoreArchivePrivacy(ctx context.Context, qtx *db.Queries, userID int64, profile AccountArchiveProfile) error {
+	archived, err := parseVisibility(profile.LogVisibility)
+	if err != nil {
+		return err
+	}
+
+	current, err := qtx.GetUserPrivacy(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("get user privacy: %w", err)
+	}
+
+	var update db.UpdateUserPrivacyParams
+	if archived.Valid && slices.Index(logVisibilities, archived.String) > slices.Index(logVisibilities, current.LogVisibility) {
+		update.LogVisibility = archived
+	}
+	if profile.HideNotes && !current.HideNotes {
+		update.HideNotes = pgtype.Bool{Bool: true, Valid: true}
+	}
+	if !update.LogVisibility.Valid && !update.HideNotes.Valid {
+		return nil
+	}
+
+	update.ID = userID
+	if _, err := qtx.UpdateUserPrivacy(ctx, update); err != nil {
+		return fmt.Errorf("update user privacy: %w", err)
+	}
+	return nil
+}
+
+// restoreArchiveFollowing sends a follow request to every archived followee
+// that has an account here, returning the ids of the users requested.
+// Usernames that are unknown, already followed or already requested are
+// skipped.
+func restoreArchiveFollowing(ctx context.Context, qtx *db.Queries, userID int64, usernames []string) ([]int64, error) {
+	if len(usernames) == 0 {
+		return nil, nil
+	}
+
+	lowered := make([]string, len(usernames))
+	for i, username := range usernames {
+		lowered[i] = strings.ToLower(strings.TrimSpace(username))
+	}
+	users, err := qtx.ListUserIDsByUsernames(ctx, lowered)
+	if err != nil {
+		return nil, fmt.Errorf("list archived followees: %w", err)
+	}
+
+	var requested []int64
+	for _, user := range users {
+		if user.ID == userID {
+			continue
+		}
+		_, err := qtx.CreateFollowRequest(ctx, db.CreateFollowRequestParams{
+			FollowerID: userID,
+			FolloweeID: user.ID,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("create follow request: %w", err)
+		}
+		requested = append(requested, user.ID)
+	}
+	return requested, nil
+}
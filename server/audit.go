@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultAuditPageSize = 50
+	maxAuditPageSize     = 200
+)
+
+// Audited actions. Targets are users, movie_log entries, log_import rows, or
+// the movie_import and imdb_id_import catalog jobs, which have no id.
+const (
+	auditUserCreate      = "user.create"
+	auditUserDelete      = "user.delete"
+	auditUserRoleChange  = "user.role_change"
+	auditUserPasswordSet = "user.password_set"
+	auditLogDelete       = "log.delete"
+	auditImportStart     = "import.start"
+	auditImportFinish    = "import.finish"
+)
+
+// auditSource is who an audited action was done by and which request it came
+// from. Background work keeps the source of the request that started it;
+// maintenance commands have an empty source.
+type auditSource struct {
+	ActorID       pgtype.Int8
+	ActorUsername pgtype.Text
+	RequestID     pgtype.Text
+	IP            pgtype.Text
+}
+
+// auditEvent is one action to record. Before and After are snapshots of the
+// target, stored as JSON; either is left out when it is nil.
+type auditEvent struct {
+	Action     string
+	TargetType string
+	TargetID   pgtype.Int8
+	Before     any
+	After      any
+}
+
+func auditSourceFrom(c echo.Context) auditSource {
+	source := auditSource{
+		RequestID: optionalText(c.Response().Header().Get(echo.HeaderXRequestID)),
+		IP:        optionalText(c.RealIP()),
+	}
+	if user, ok := sessionUser(c); ok {
+		source.ActorID = pgtype.Int8{Int64: user.ID, Valid: true}
+		source.ActorUsername = pgtype.Text{String: user.Username, Valid: true}
+	}
+	return source
+}
+
+func optionalText(value string) pgtype.Text {
+	return pgtype.Text{String: value, Valid: value != ""}
+}
+
+func auditTarget(id int64) pgtype.Int8 {
+	return pgtype.Int8{Int64: id, Valid: true}
+}
+
+// recordAudit appends an event to the audit log. Callers pass the queries of
+// the transaction making the change, so the change and its record commit
+// together.
+func recordAudit(ctx context.Context, queries *db.Queries, source auditSource, event auditEvent) error {
+	before, err := auditSnapshot(event.Before)
+	if err != nil {
+		return err
+	}
+	after, err := auditSnapshot(event.After)
+	if err != nil {
+		return err
+	}
+
+	if err := queries.CreateAuditEvent(ctx, db.CreateAuditEventParams{
+		ActorID:       source.ActorID,
+		ActorUsername: source.ActorUsername,
+		Action:        event.Action,
+		TargetType:    event.TargetType,
+		TargetID:      event.TargetID,
+		RequestID:     source.RequestID,
+		Ip:            source.IP,
+		Before:        before,
+		After:         after,
+	}); err != nil {
+		return fmt.Errorf("record %s audit event: %w", event.Action, err)
+	}
+	return nil
+}
+
+// recordAuditOrLog records an event for a change that is not made in a
+// database transaction, such as a catalog import starting or an import
+// finishing in the background. A failure is logged since there is nothing
+// left to roll back.
+func recordAuditOrLog(queries *db.Queries, source auditSource, event auditEvent) {
+	if err := recordAudit(context.Background(), queries, source, event); err != nil {
+		log.Printf("%v", err)
+	}
+}
+
+func auditSnapshot(value any) ([]byte, error) {
+	if value == nil {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("encode audit snapshot: %w", err)
+	}
+	return encoded, nil
+}
+
+// parseAuditParams reads the filters, limit and cursor for a page of the
+// audit log. PageSize is set one past limit so the caller can tell whether
+// another page follows.
+func parseAuditParams(c echo.Context) (db.ListAuditEventsParams, int, error) {
+	var params db.ListAuditEventsParams
+
+	limit := defaultAuditPageSize
+	if raw := c.QueryParam("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxAuditPageSize {
+			return params, 0, fmt.Errorf("limit must be between 1 and %d", maxAuditPageSize)
+		}
+		limit = parsed
+	}
+	params.PageSize = int32(limit + 1)
+
+	var err error
+	if params.ActorID, err = parseAuditIDParam(c, "actor_id"); err != nil {
+		return params, 0, err
+	}
+	if params.TargetID, err = parseAuditIDParam(c, "target_id"); err != nil {
+		return params, 0, err
+	}
+	params.Action = optionalText(c.QueryParam("action"))
+	params.TargetType = optionalText(c.QueryParam("target_type"))
+	if params.Since, err = parseAuditTimeParam(c, "since"); err != nil {
+		return params, 0, err
+	}
+	if params.Until, err = parseAuditTimeParam(c, "until"); err != nil {
+		return params, 0, err
+	}
+
+	if raw := c.QueryParam("cursor"); raw != "" {
+		params.CursorCreatedAt, params.CursorID, err = decodeTimelineCursor(raw)
+		if err != nil {
+			return params, 0, err
+		}
+	}
+
+	return params, limit, nil
+}
+
+func parseAuditIDParam(c echo.Context, name string) (pgtype.Int8, error) {
+	raw := c.QueryParam(name)
+	if raw == "" {
+		return pgtype.Int8{}, nil
+	}
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return pgtype.Int8{}, fmt.Errorf("%s must be an integer", name)
+	}
+	return pgtype.Int8{Int64: id, Valid: true}, nil
+}
+
+func parseAuditTimeParam(c echo.Context, name string) (pgtype.Timestamptz, error) {
+	raw := c.QueryParam(name)
+	if raw == "" {
+		return pgtype.Timestamptz{}, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return pgtype.Timestamptz{}, fmt.Errorf("%s must be an RFC3339 timestamp", name)
+	}
+	return pgtype.Timestamptz{Time: parsed, Valid: true}, nil
+}
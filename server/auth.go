@@ -0,0 +1,359 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/labstack/echo/v4"
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	sessionCookieName = "moviestack_session"
+	sessionLifetime   = 30 * 24 * time.Hour
+	sessionUserKey    = "sessionUser"
+
+	minPasswordLength = 8
+	maxPasswordLength = 256
+)
+
+// argon2id parameters, following the OWASP password storage minimums. They
+// are stored in each hash, so raising them later only affects new passwords.
+const (
+	argonTime       = 2
+	argonMemory     = 19 * 1024
+	argonThreads    = 1
+	argonKeyLength  = 32
+	argonSaltLength = 16
+)
+
+var (
+	errInvalidCredentials = errors.New("invalid username or password")
+	errInvalidPassword    = fmt.Errorf("password must be between %d and %d characters", minPasswordLength, maxPasswordLength)
+	errUsernameTaken      = errors.New("username already exists")
+	errMalformedHash      = errors.New("malformed password hash")
+)
+
+// sharedUserPaths are routes under a user where :userId names whose log entry
+// is being commented on or liked rather than who is acting. Their handlers
+// take the actor from the session instead.
+var sharedUserPaths = []string{
+	"/api/users/:userId/log/:logId/comments",
+	"/api/users/:userId/log/:logId/likes",
+}
+
+// privateUserPaths are routes under a user that only the user may read.
+var privateUserPaths = []string{
+	"/api/users/:userId/feed",
+	"/api/users/:userId/notifications",
+	"/api/users/:userId/privacy",
+	"/api/users/:userId/imports",
+	"/api/users/:userId/rank",
+	"/api/users/:userId/friends/requests",
+	"/api/users/:userId/tokens",
+	"/api/users/:userId/archive",
+}
+
+// dummyPasswordHash is checked against when a login names an unknown user, so
+// the response takes as long as a wrong password would.
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hash, err := hashPassword("moviestack-dummy-password")
+	if err != nil {
+		panic(err)
+	}
+	return hash
+})
+
+// hashPassword encodes password as an argon2id hash in the PHC string format.
+func hashPassword(password string) (string, error) {
+	salt := make([]byte, argonSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("generate salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, argonTime, argonMemory, argonThreads, argonKeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, argonMemory, argonTime, argonThreads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// verifyPassword reports whether password matches a hash made by
+// hashPassword, using the parameters recorded in the hash.
+func verifyPassword(password, encoded string) (bool, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return false, errMalformedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, errMalformedHash
+	}
+	var memory, iterations uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &threads); err != nil {
+		return false, errMalformedHash
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, errMalformedHash
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, errMalformedHash
+	}
+
+	got := argon2.IDKey([]byte(password), salt, iterations, memory, threads, uint32(len(want)))
+	return subtle.ConstantTimeCompare(got, want) == 1, nil
+}
+
+func checkPassword(password string) error {
+	length := utf8.RuneCountInString(password)
+	if length < minPasswordLength || length > maxPasswordLength {
+		return errInvalidPassword
+	}
+	return nil
+}
+
+// randomToken returns a new secret for a session or API token.
+func randomToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// hashToken is how session and API tokens are stored, so a leaked table
+// cannot be replayed as credentials.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// signUp creates a user with a password.
+func signUp(ctx context.Context, pool *pgxpool.Pool, queries *db.Queries, source auditSource, username, password string) (db.User, error) {
+	if err := checkPassword(password); err != nil {
+		return db.User{}, err
+	}
+	passwordHash, err := hashPassword(password)
+	if err != nil {
+		return db.User{}, err
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return db.User{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := queries.WithTx(tx)
+	user, err := qtx.CreateUser(ctx, username)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "users_username_lower_unique" {
+			return db.User{}, errUsernameTaken
+		}
+		return db.User{}, fmt.Errorf("create user: %w", err)
+	}
+	if err := qtx.CreateUserCredentials(ctx, db.CreateUserCredentialsParams{
+		UserID:       user.ID,
+		PasswordHash: passwordHash,
+	}); err != nil {
+		return db.User{}, fmt.Errorf("create credentials: %w", err)
+	}
+	if err := recordAudit(ctx, qtx, source, auditEvent{
+		Action:     auditUserCreate,
+		TargetType: "user",
+		TargetID:   auditTarget(user.ID),
+		After:      user,
+	}); err != nil {
+		return db.User{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return db.User{}, fmt.Errorf("commit transaction: %w", err)
+	}
+	return user, nil
+}
+
+// logIn checks a username and password and returns the user's id. Unknown
+// users and wrong passwords fail the same way.
+func logIn(ctx context.Context, queries *db.Queries, username, password string) (int64, error) {
+	credentials, err := queries.GetUserCredentialsByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			verifyPassword(password, dummyPasswordHash())
+			return 0, errInvalidCredentials
+		}
+		return 0, fmt.Errorf("get credentials: %w", err)
+	}
+
+	ok, err := verifyPassword(password, credentials.PasswordHash)
+	if err != nil {
+		return 0, fmt.Errorf("verify password for user %d: %w", credentials.ID, err)
+	}
+	if !ok {
+		return 0, errInvalidCredentials
+	}
+	return credentials.ID, nil
+}
+
+// startSession stores a new session for userID and sets its cookie.
+func startSession(c echo.Context, queries *db.Queries, userID int64, secureCookies bool) error {
+	token, err := randomToken()
+	if err != nil {
+		return err
+	}
+	expiresAt := time.Now().Add(sessionLifetime)
+
+	ctx := c.Request().Context()
+	if err := queries.DeleteExpiredUserSessions(ctx, userID); err != nil {
+		return fmt.Errorf("delete expired sessions: %w", err)
+	}
+	if err := queries.CreateUserSession(ctx, db.CreateUserSessionParams{
+		TokenHash: hashToken(token),
+		UserID:    userID,
+		UserAgent: pgtype.Text{String: c.Request().UserAgent(), Valid: c.Request().UserAgent() != ""},
+		Ip:        pgtype.Text{String: c.RealIP(), Valid: c.RealIP() != ""},
+		ExpiresAt: pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	}); err != nil {
+		return fmt.Errorf("create session: %w", err)
+	}
+
+	c.SetCookie(sessionCookie(token, expiresAt, secureCookies))
+	return nil
+}
+
+// endSession deletes the request's session, if any, and clears its cookie.
+func endSession(c echo.Context, queries *db.Queries, secureCookies bool) error {
+	if cookie, err := c.Cookie(sessionCookieName); err == nil && cookie.Value != "" {
+		if err := queries.DeleteUserSession(c.Request().Context(), hashToken(cookie.Value)); err != nil {
+			return fmt.Errorf("delete session: %w", err)
+		}
+	}
+
+	c.SetCookie(sessionCookie("", time.Unix(0, 0), secureCookies))
+	return nil
+}
+
+func sessionCookie(token string, expiresAt time.Time, secure bool) *http.Cookie {
+	return &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    token,
+		Path:     "/",
+		Expires:  expiresAt,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// authenticate resolves the request's credentials to the signed-in user.
+// An API token in the Authorization header takes precedence over the session
+// cookie. Requests without a valid session carry on anonymously, but a bad
+// token is rejected outright so scripts notice.
+func authenticate(queries *db.Queries) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if token, ok := bearerToken(c); ok {
+				return authenticateAPIToken(c, queries, token, next)
+			}
+
+			cookie, err := c.Cookie(sessionCookieName)
+			if err != nil || cookie.Value == "" {
+				return next(c)
+			}
+
+			user, err := queries.GetSessionUser(c.Request().Context(), hashToken(cookie.Value))
+			if err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return next(c)
+				}
+				log.Printf("get session user error: %v", err)
+				return c.JSON(http.StatusInternalServerError, map[string]string{
+					"error": "failed to verify session",
+				})
+			}
+
+			c.Set(sessionUserKey, user)
+			return next(c)
+		}
+	}
+}
+
+// requireAccountOwner only lets the signed-in user change anything under
+// their own /api/users/:userId, or read its private parts.
+func requireAccountOwner(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		path := c.Path()
+		if !strings.HasPrefix(path, "/api/users/:userId") || matchesPathPrefix(path, sharedUserPaths) {
+			return next(c)
+		}
+		switch c.Request().Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			if !matchesPathPrefix(path, privateUserPaths) {
+				return next(c)
+			}
+		}
+
+		user, ok := sessionUser(c)
+		if !ok {
+			return authRequiredResponse(c)
+		}
+		if c.Param("userId") != fmt.Sprint(user.ID) {
+			return c.JSON(http.StatusForbidden, map[string]string{
+				"error": "not allowed to access another user's account",
+			})
+		}
+		return next(c)
+	}
+}
+
+func matchesPathPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// sessionUser returns the signed-in user, if any.
+func sessionUser(c echo.Context) (db.GetSessionUserRow, bool) {
+	user, ok := c.Get(sessionUserKey).(db.GetSessionUserRow)
+	return user, ok
+}
+
+// viewerID is the signed-in user reading a log, or invalid for an anonymous
+// reader.
+func viewerID(c echo.Context) pgtype.Int8 {
+	user, ok := sessionUser(c)
+	return pgtype.Int8{Int64: user.ID, Valid: ok}
+}
+
+func authRequiredResponse(c echo.Context) error {
+	return c.JSON(http.StatusUnauthorized, map[string]string{
+		"error": "authentication required",
+	})
+}
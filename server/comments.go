@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// maxCommentLength bounds a comment body, counted in characters.
+const maxCommentLength = 2000
+
+var (
+	errCommentNotFound       = errors.New("comment not found")
+	errCommentParentNotFound = errors.New("parent comment not found")
+	errCommentForbidden      = errors.New("not allowed to change this comment")
+	errInvalidCommentBody    = fmt.Errorf("body is required and must be at most %d characters", maxCommentLength)
+	errLogLikeNotFound       = errors.New("like not found")
+)
+
+// checkLogEntryActor makes sure the log entry belongs to ownerID, that the
+// user commenting or liking it exists, and that they may see the entry.
+func checkLogEntryActor(ctx context.Context, queries *db.Queries, ownerID, logID, actorID int64) error {
+	userExists, err := queries.UserExists(ctx, actorID)
+	if err != nil {
+		return fmt.Errorf("user exists: %w", err)
+	}
+	if !userExists {
+		return errUserNotFound
+	}
+
+	access, err := resolveLogAccess(ctx, queries, ownerID, pgtype.Int8{Int64: actorID, Valid: true})
+	if err != nil {
+		return err
+	}
+	return checkLogEntryVisible(ctx, queries, access, ownerID, logID)
+}
+
+// commentBody trims a comment body and checks its length.
+func commentBody(raw string) (string, error) {
+	body := strings.TrimSpace(raw)
+	if body == "" || utf8.RuneCountInString(body) > maxCommentLength {
+		return "", errInvalidCommentBody
+	}
+	return body, nil
+}
+
+// createLogComment adds a comment to a log entry. A reply must point at a
+// comment on the same entry.
+func createLogComment(ctx context.Context, queries *db.Queries, ownerID, logID, actorID int64, req CreateLogCommentRequest) (db.LogComment, error) {
+	body, err := commentBody(req.Body)
+	if err != nil {
+		return db.LogComment{}, err
+	}
+	if err := checkLogEntryActor(ctx, queries, ownerID, logID, actorID); err != nil {
+		return db.LogComment{}, err
+	}
+
+	var parent db.LogComment
+	if req.ParentID != nil {
+		parent, err = queries.GetLogComment(ctx, db.GetLogCommentParams{
+			ID:    *req.ParentID,
+			LogID: logID,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return db.LogComment{}, errCommentParentNotFound
+			}
+			return db.LogComment{}, fmt.Errorf("get parent comment: %w", err)
+		}
+	}
+
+	comment, err := queries.CreateLogComment(ctx, db.CreateLogCommentParams{
+		LogID:    logID,
+		UserID:   actorID,
+		ParentID: pgtype.Int8{Int64: parent.ID, Valid: req.ParentID != nil},
+		Body:     body,
+	})
+	if err != nil {
+		return db.LogComment{}, fmt.Errorf("create comment: %w", err)
+	}
+
+	notifyLogComment(ctx, queries, ownerID, comment, parent.UserID)
+	return comment, nil
+}
+
+// editLogComment replaces a comment's body. Only its author may edit it.
+func editLogComment(ctx context.Context, queries *db.Queries, ownerID, logID, commentID, actorID int64, req UpdateLogCommentRequest) (db.LogComment, error) {
+	body, err := commentBody(req.Body)
+	if err != nil {
+		return db.LogComment{}, err
+	}
+
+	comment, err := getLogComment(ctx, queries, ownerID, logID, commentID)
+	if err != nil {
+		return db.LogComment{}, err
+	}
+	if comment.UserID != actorID {
+		return db.LogComment{}, errCommentForbidden
+	}
+
+	updated, err := queries.UpdateLogComment(ctx, db.UpdateLogCommentParams{
+		Body:  body,
+		ID:    commentID,
+		LogID: logID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.LogComment{}, errCommentNotFound
+		}
+		return db.LogComment{}, fmt.Errorf("update comment: %w", err)
+	}
+	return updated, nil
+}
+
+// deleteLogComment removes a comment and its replies. The comment's author,
+// the owner of the log entry and moderators may delete it.
+func deleteLogComment(ctx context.Context, queries *db.Queries, ownerID, logID, commentID int64, actor db.GetSessionUserRow) error {
+	comment, err := getLogComment(ctx, queries, ownerID, logID, commentID)
+	if err != nil {
+		return err
+	}
+	if comment.UserID != actor.ID && ownerID != actor.ID && !hasRole(actor.Role, "moderator") {
+		return errCommentForbidden
+	}
+
+	deleted, err := queries.DeleteLogComment(ctx, db.DeleteLogCommentParams{
+		ID:    commentID,
+		LogID: logID,
+	})
+	if err != nil {
+		return fmt.Errorf("delete comment: %w", err)
+	}
+	if deleted == 0 {
+		return errCommentNotFound
+	}
+	return nil
+}
+
+// getLogComment loads a comment on one of ownerID's log entries.
+func getLogComment(ctx context.Context, queries *db.Queries, ownerID, logID, commentID int64) (db.LogComment, error) {
+	entryExists, err := queries.MovieLogEntryExists(ctx, db.MovieLogEntryExistsParams{
+		ID:     logID,
+		UserID: ownerID,
+	})
+	if err != nil {
+		return db.LogComment{}, fmt.Errorf("movie log entry exists: %w", err)
+	}
+	if !entryExists {
+		return db.LogComment{}, errLogEntryNotFound
+	}
+
+	comment, err := queries.GetLogComment(ctx, db.GetLogCommentParams{
+		ID:    commentID,
+		LogID: logID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.LogComment{}, errCommentNotFound
+		}
+		return db.LogComment{}, fmt.Errorf("get comment: %w", err)
+	}
+	return comment, nil
+}
+
+// buildCommentThreads nests replies under their parents. Rows arrive oldest
+// first, so every thread reads in the order it was written.
+func buildCommentThreads(rows []db.ListLogCommentsRow) []LogCommentResponse {
+	children := make(map[int64][]db.ListLogCommentsRow)
+	var roots []db.ListLogCommentsRow
+	for _, row := range rows {
+		if row.ParentID.Valid {
+			children[row.ParentID.Int64] = append(children[row.ParentID.Int64], row)
+			continue
+		}
+		roots = append(roots, row)
+	}
+
+	var build func(rows []db.ListLogCommentsRow) []LogCommentResponse
+	build = func(rows []db.ListLogCommentsRow) []LogCommentResponse {
+		threads := make([]LogCommentResponse, len(rows))
+		for i, row := range rows {
+			threads[i] = toLogCommentThreadResponse(row)
+			threads[i].Replies = build(children[row.ID])
+		}
+		return threads
+	}
+	return build(roots)
+}
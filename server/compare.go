@@ -0,0 +1,132 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	defaultCompareLimit = 10
+	maxCompareLimit     = 50
+)
+
+// highlyRankedScore is the rank-derived score from which an entry counts as
+// one of a user's favourites when suggesting movies to the other user.
+const highlyRankedScore = 8.0
+
+// rankedPair is one movie both users ranked.
+type rankedPair struct {
+	user  RankEntryResponse
+	other RankEntryResponse
+}
+
+// compareRankings measures how much two rankings agree. Each user's entries
+// must be in rank order and already limited to what the other may see;
+// userLogged and otherLogged hold every movie each has logged, ranked or not.
+func compareRankings(user, other []RankEntryResponse, userLogged, otherLogged map[int32]bool, limit int) CompareUsersResponse {
+	response := CompareUsersResponse{
+		Disagreements: []RankComparisonResponse{},
+		UnseenByOther: highlyRankedUnseen(user, otherLogged, limit),
+		UnseenByUser:  highlyRankedUnseen(other, userLogged, limit),
+	}
+	for movieID := range userLogged {
+		if otherLogged[movieID] {
+			response.OverlapCount++
+		}
+	}
+
+	otherByMovie := make(map[int32]RankEntryResponse, len(other))
+	for _, entry := range other {
+		otherByMovie[entry.MovieID] = entry
+	}
+	var pairs []rankedPair
+	for _, entry := range user {
+		if otherEntry, ok := otherByMovie[entry.MovieID]; ok {
+			pairs = append(pairs, rankedPair{user: entry, other: otherEntry})
+		}
+	}
+	response.RankedOverlapCount = len(pairs)
+
+	if len(pairs) >= 2 {
+		tau, rho := rankCorrelations(pairs)
+		compatibility := int(math.Round((tau + 1) * 50))
+		response.KendallTau = &tau
+		response.SpearmanRho = &rho
+		response.Compatibility = &compatibility
+	}
+
+	for _, pair := range pairs {
+		if pair.user.Score == nil || pair.other.Score == nil {
+			continue
+		}
+		response.Disagreements = append(response.Disagreements, RankComparisonResponse{
+			MovieID:           pair.user.MovieID,
+			OriginalTitle:     pair.user.OriginalTitle,
+			RankPosition:      *pair.user.RankPosition,
+			OtherRankPosition: *pair.other.RankPosition,
+			Score:             *pair.user.Score,
+			OtherScore:        *pair.other.Score,
+			Difference:        math.Round((*pair.user.Score-*pair.other.Score)*10) / 10,
+		})
+	}
+	sort.SliceStable(response.Disagreements, func(i, j int) bool {
+		return math.Abs(response.Disagreements[i].Difference) > math.Abs(response.Disagreements[j].Difference)
+	})
+	response.Disagreements = response.Disagreements[:min(limit, len(response.Disagreements))]
+
+	return response
+}
+
+// rankCorrelations returns Kendall's tau and Spearman's rho for the shared
+// movies, re-ranked 1..n within the overlap. Rank positions are unique, so
+// there are no ties to correct for. pairs must be in the user's rank order.
+func rankCorrelations(pairs []rankedPair) (tau, rho float64) {
+	n := len(pairs)
+
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return *pairs[order[i]].other.RankPosition < *pairs[order[j]].other.RankPosition
+	})
+	otherRanks := make([]int, n)
+	for rank, i := range order {
+		otherRanks[i] = rank
+	}
+
+	// The user's ranks are 0..n-1 in slice order, so every pair the other
+	// user ranks the opposite way is a discordant pair.
+	var discordant, squaredDifference float64
+	for i := range n {
+		difference := float64(i - otherRanks[i])
+		squaredDifference += difference * difference
+		for j := i + 1; j < n; j++ {
+			if otherRanks[i] > otherRanks[j] {
+				discordant++
+			}
+		}
+	}
+
+	size := float64(n)
+	pairCount := size * (size - 1) / 2
+	tau = math.Round((1-2*discordant/pairCount)*1000) / 1000
+	rho = math.Round((1-6*squaredDifference/(size*(size*size-1)))*1000) / 1000
+	return tau, rho
+}
+
+// highlyRankedUnseen picks the entries scored at least highlyRankedScore whose
+// movies the other user has not logged, best first.
+func highlyRankedUnseen(entries []RankEntryResponse, otherLogged map[int32]bool, limit int) []RankEntryResponse {
+	unseen := []RankEntryResponse{}
+	for _, entry := range entries {
+		if len(unseen) == limit {
+			break
+		}
+		if entry.Score == nil || *entry.Score < highlyRankedScore || otherLogged[entry.MovieID] {
+			continue
+		}
+		unseen = append(unseen, entry)
+	}
+	return unseen
+}
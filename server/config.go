@@ -6,7 +6,9 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 )
 
 func loadDotEnv(path string) error {
@@ -76,3 +78,133 @@ func buildDatabaseURL() string {
 
 	return databaseURL
 }
+
+// resolveSecureCookies reports whether session cookies are marked Secure.
+// Local development runs over plain HTTP, so it is off unless
+// SESSION_COOKIE_SECURE is set.
+func resolveSecureCookies() bool {
+	secure, _ := strconv.ParseBool(os.Getenv("SESSION_COOKIE_SECURE"))
+	return secure
+}
+
+// oidcConfig configures login through an OpenID Connect provider. It is
+// read from OIDC_* environment variables; login is off without an issuer.
+type oidcConfig struct {
+	IssuerURL     string
+	ClientID      string
+	ClientSecret  string
+	RedirectURL   string
+	Scopes        []string
+	UsernameClaim string
+	PostLoginURL  string
+}
+
+func loadOIDCConfig() (oidcConfig, bool) {
+	config := oidcConfig{
+		IssuerURL:     os.Getenv("OIDC_ISSUER_URL"),
+		ClientID:      os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret:  os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:   os.Getenv("OIDC_REDIRECT_URL"),
+		Scopes:        strings.Fields(os.Getenv("OIDC_SCOPES")),
+		UsernameClaim: os.Getenv("OIDC_USERNAME_CLAIM"),
+		PostLoginURL:  os.Getenv("OIDC_POST_LOGIN_URL"),
+	}
+	if config.IssuerURL == "" {
+		return config, false
+	}
+
+	if config.RedirectURL == "" {
+		config.RedirectURL = "http://localhost:8080/api/auth/oidc/callback"
+	}
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "profile", "email"}
+	}
+	if config.UsernameClaim == "" {
+		config.UsernameClaim = "preferred_username"
+	}
+	if config.PostLoginURL == "" {
+		config.PostLoginURL = "http://localhost:3000/"
+	}
+	return config, true
+}
+
+// rateLimitConfig chooses where token buckets live and how big they are for
+// each route group. Limits are read from RATE_LIMIT_<GROUP>_PER_IP and
+// RATE_LIMIT_<GROUP>_PER_USER as "<requests>/<period>", such as "60/1m", or
+// "off".
+type rateLimitConfig struct {
+	Store  string
+	Groups map[string]rateLimitGroupLimits
+}
+
+// defaultRateLimits stop scripted search and write floods without getting
+// in the way of normal use. Signed-in requests count against both limits, so
+// the per-IP one also caps each user.
+var defaultRateLimits = map[string]rateLimitGroupLimits{
+	"search": {
+		PerIP:   rateLimit{Burst: 30, Period: time.Minute},
+		PerUser: rateLimit{Burst: 60, Period: time.Minute},
+	},
+	"write": {
+		PerIP:   rateLimit{Burst: 30, Period: time.Minute},
+		PerUser: rateLimit{Burst: 120, Period: time.Minute},
+	},
+}
+
+func loadRateLimitConfig() (rateLimitConfig, error) {
+	config := rateLimitConfig{
+		Store:  strings.ToLower(strings.TrimSpace(os.Getenv("RATE_LIMIT_STORE"))),
+		Groups: map[string]rateLimitGroupLimits{},
+	}
+	switch config.Store {
+	case "":
+		config.Store = "memory"
+	case "memory", "postgres":
+	default:
+		return rateLimitConfig{}, fmt.Errorf("RATE_LIMIT_STORE must be memory or postgres, got %q", config.Store)
+	}
+
+	for group, limits := range defaultRateLimits {
+		prefix := "RATE_LIMIT_" + strings.ToUpper(group)
+		var err error
+		if limits.PerIP, err = parseRateLimitEnv(prefix+"_PER_IP", limits.PerIP); err != nil {
+			return rateLimitConfig{}, err
+		}
+		if limits.PerUser, err = parseRateLimitEnv(prefix+"_PER_USER", limits.PerUser); err != nil {
+			return rateLimitConfig{}, err
+		}
+		config.Groups[group] = limits
+	}
+	return config, nil
+}
+
+func parseRateLimitEnv(key string, fallback rateLimit) (rateLimit, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return fallback, nil
+	}
+	if strings.EqualFold(value, "off") {
+		return rateLimit{}, nil
+	}
+
+	count, period, found := strings.Cut(value, "/")
+	burst, err := strconv.Atoi(count)
+	if !found || err != nil || burst < 1 {
+		return rateLimit{}, fmt.Errorf("%s must look like 60/1m or be off, got %q", key, value)
+	}
+	duration, err := time.ParseDuration(period)
+	if err != nil || duration <= 0 {
+		return rateLimit{}, fmt.Errorf("%s must look like 60/1m or be off, got %q", key, value)
+	}
+	return rateLimit{Burst: burst, Period: duration}, nil
+}
+
+// idleAfter is how long a bucket can go unused before it would be full
+// again, after which it can be forgotten.
+func (c rateLimitConfig) idleAfter() time.Duration {
+	idle := time.Minute
+	for _, limits := range c.Groups {
+		idle = max(idle, limits.PerIP.Period, limits.PerUser.Period)
+	}
+	return idle
+}
@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS rank_sessions (
+    id                BIGSERIAL   NOT NULL PRIMARY KEY,
+    user_id           BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    log_id            BIGINT      NOT NULL REFERENCES movie_log (id) ON DELETE CASCADE,
+    low_position      INTEGER     NOT NULL,
+    high_position     INTEGER     NOT NULL,
+    comparison_log_id BIGINT      REFERENCES movie_log (id) ON DELETE SET NULL,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT rank_sessions_log_unique UNIQUE (log_id),
+    CONSTRAINT rank_sessions_low_positive CHECK (low_position > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rank_sessions_user_id ON rank_sessions (user_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_rank_sessions_user_id;
+DROP TABLE IF EXISTS rank_sessions;
@@ -0,0 +1,19 @@
+-- +goose Up
+ALTER TABLE movie_log ADD COLUMN IF NOT EXISTS sentiment TEXT;
+
+-- Entries ranked before bands existed all land in the middle band.
+UPDATE movie_log
+SET sentiment = 'fine'
+WHERE rank_position IS NOT NULL AND sentiment IS NULL;
+
+ALTER TABLE movie_log
+    ADD CONSTRAINT movie_log_sentiment_valid
+        CHECK (sentiment IS NULL OR sentiment IN ('liked', 'fine', 'disliked')),
+    ADD CONSTRAINT movie_log_ranked_sentiment
+        CHECK (rank_position IS NULL OR sentiment IS NOT NULL);
+
+-- +goose Down
+ALTER TABLE movie_log
+    DROP CONSTRAINT IF EXISTS movie_log_ranked_sentiment,
+    DROP CONSTRAINT IF EXISTS movie_log_sentiment_valid;
+ALTER TABLE movie_log DROP COLUMN IF EXISTS sentiment;
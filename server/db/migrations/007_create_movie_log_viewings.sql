@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS movie_log_viewings (
+    id         BIGSERIAL   NOT NULL PRIMARY KEY,
+    log_id     BIGINT      NOT NULL REFERENCES movie_log (id) ON DELETE CASCADE,
+    watched_on DATE        NOT NULL DEFAULT CURRENT_DATE,
+    note       TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_movie_log_viewings_log_watched_on
+    ON movie_log_viewings (log_id, watched_on DESC);
+
+-- Each existing entry becomes a log entry with a single viewing.
+INSERT INTO movie_log_viewings (log_id, watched_on, note, created_at, updated_at)
+SELECT id, watched_on, note, created_at, updated_at
+FROM movie_log;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_movie_log_viewings_log_watched_on;
+DROP TABLE IF EXISTS movie_log_viewings;
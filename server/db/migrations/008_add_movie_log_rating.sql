@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE movie_log ADD COLUMN IF NOT EXISTS rating NUMERIC(2, 1);
+
+ALTER TABLE movie_log
+    ADD CONSTRAINT movie_log_rating_half_stars
+        CHECK (rating IS NULL OR (rating BETWEEN 0.5 AND 5.0 AND rating * 2 = trunc(rating * 2)));
+
+CREATE INDEX IF NOT EXISTS idx_movie_log_user_rating ON movie_log (user_id, rating) WHERE rating IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_movie_log_user_rating;
+ALTER TABLE movie_log DROP CONSTRAINT IF EXISTS movie_log_rating_half_stars;
+ALTER TABLE movie_log DROP COLUMN IF EXISTS rating;
@@ -0,0 +1,25 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS tags (
+    id         BIGSERIAL   NOT NULL PRIMARY KEY,
+    user_id    BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name       TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT tags_name_not_blank CHECK (name <> '')
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS tags_user_name_unique ON tags (user_id, name);
+
+CREATE TABLE IF NOT EXISTS movie_log_tags (
+    log_id     BIGINT      NOT NULL REFERENCES movie_log (id) ON DELETE CASCADE,
+    tag_id     BIGINT      NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (log_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_movie_log_tags_tag_id ON movie_log_tags (tag_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_movie_log_tags_tag_id;
+DROP TABLE IF EXISTS movie_log_tags;
+DROP INDEX IF EXISTS tags_user_name_unique;
+DROP TABLE IF EXISTS tags;
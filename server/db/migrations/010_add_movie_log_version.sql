@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE movie_log ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+-- +goose Down
+ALTER TABLE movie_log DROP COLUMN IF EXISTS version;
@@ -0,0 +1,39 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS log_imports (
+    id             BIGSERIAL   NOT NULL PRIMARY KEY,
+    user_id        BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    source         TEXT        NOT NULL,
+    film_count     INTEGER     NOT NULL DEFAULT 0,
+    imported_count INTEGER     NOT NULL DEFAULT 0,
+    queued_count   INTEGER     NOT NULL DEFAULT 0,
+    existing_count INTEGER     NOT NULL DEFAULT 0,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_log_imports_user_id ON log_imports (user_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS log_import_reviews (
+    id                  BIGSERIAL     NOT NULL PRIMARY KEY,
+    import_id           BIGINT        NOT NULL REFERENCES log_imports (id) ON DELETE CASCADE,
+    user_id             BIGINT        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    title               TEXT          NOT NULL,
+    release_year        INTEGER,
+    watched_dates       DATE[]        NOT NULL,
+    rating              NUMERIC(2, 1),
+    review              TEXT,
+    candidate_movie_ids INTEGER[]     NOT NULL DEFAULT '{}',
+    status              TEXT          NOT NULL DEFAULT 'pending',
+    movie_id            INTEGER       REFERENCES movie_ids (id) ON DELETE SET NULL,
+    log_id              BIGINT        REFERENCES movie_log (id) ON DELETE SET NULL,
+    created_at          TIMESTAMPTZ   NOT NULL DEFAULT now(),
+    updated_at          TIMESTAMPTZ   NOT NULL DEFAULT now(),
+    CONSTRAINT log_import_reviews_status_valid CHECK (status IN ('pending', 'confirmed', 'dismissed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_log_import_reviews_user_status ON log_import_reviews (user_id, status, id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_log_import_reviews_user_status;
+DROP TABLE IF EXISTS log_import_reviews;
+DROP INDEX IF EXISTS idx_log_imports_user_id;
+DROP TABLE IF EXISTS log_imports;
@@ -0,0 +1,28 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS imdb_movie_ids (
+    imdb_id  TEXT    NOT NULL PRIMARY KEY,
+    movie_id INTEGER NOT NULL REFERENCES movie_ids (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_imdb_movie_ids_movie_id ON imdb_movie_ids (movie_id);
+
+-- Imports that finished before background imports existed are backfilled as
+-- succeeded; new imports start out running.
+ALTER TABLE log_imports ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'succeeded';
+ALTER TABLE log_imports ALTER COLUMN status SET DEFAULT 'running';
+ALTER TABLE log_imports ADD COLUMN IF NOT EXISTS processed_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE log_imports ADD COLUMN IF NOT EXISTS ranked_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE log_imports ADD COLUMN IF NOT EXISTS error TEXT;
+ALTER TABLE log_imports ADD COLUMN IF NOT EXISTS finished_at TIMESTAMPTZ;
+UPDATE log_imports SET processed_count = film_count, finished_at = created_at WHERE finished_at IS NULL;
+ALTER TABLE log_imports ADD CONSTRAINT log_imports_status_valid CHECK (status IN ('running', 'succeeded', 'failed'));
+
+-- +goose Down
+ALTER TABLE log_imports DROP CONSTRAINT IF EXISTS log_imports_status_valid;
+ALTER TABLE log_imports DROP COLUMN IF EXISTS finished_at;
+ALTER TABLE log_imports DROP COLUMN IF EXISTS error;
+ALTER TABLE log_imports DROP COLUMN IF EXISTS ranked_count;
+ALTER TABLE log_imports DROP COLUMN IF EXISTS processed_count;
+ALTER TABLE log_imports DROP COLUMN IF EXISTS status;
+DROP INDEX IF EXISTS idx_imdb_movie_ids_movie_id;
+DROP TABLE IF EXISTS imdb_movie_ids;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS friendships (
+    follower_id BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    followee_id BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    status      TEXT        NOT NULL DEFAULT 'pending',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (follower_id, followee_id),
+    CONSTRAINT friendships_not_self CHECK (follower_id <> followee_id),
+    CONSTRAINT friendships_status_valid CHECK (status IN ('pending', 'accepted'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_friendships_followee_status ON friendships (followee_id, status);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_friendships_followee_status;
+DROP TABLE IF EXISTS friendships;
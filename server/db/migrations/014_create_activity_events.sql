@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS activity_events (
+    id            BIGSERIAL     NOT NULL PRIMARY KEY,
+    user_id       BIGINT        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    log_id        BIGINT        NOT NULL REFERENCES movie_log (id) ON DELETE CASCADE,
+    kind          TEXT          NOT NULL,
+    note          TEXT,
+    rating        NUMERIC(2, 1),
+    sentiment     TEXT,
+    rank_position INTEGER,
+    watched_on    DATE,
+    created_at    TIMESTAMPTZ   NOT NULL DEFAULT now(),
+    CONSTRAINT activity_events_kind_valid CHECK (kind IN ('logged', 'noted', 'ranked', 'rewatched'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_activity_events_user_created ON activity_events (user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_activity_events_log_id ON activity_events (log_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_activity_events_log_id;
+DROP INDEX IF EXISTS idx_activity_events_user_created;
+DROP TABLE IF EXISTS activity_events;
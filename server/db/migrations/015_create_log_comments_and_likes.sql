@@ -0,0 +1,28 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS log_comments (
+    id         BIGSERIAL   NOT NULL PRIMARY KEY,
+    log_id     BIGINT      NOT NULL REFERENCES movie_log (id) ON DELETE CASCADE,
+    user_id    BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    parent_id  BIGINT      REFERENCES log_comments (id) ON DELETE CASCADE,
+    body       TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT log_comments_body_not_empty CHECK (body <> '')
+);
+
+CREATE INDEX IF NOT EXISTS idx_log_comments_log_id ON log_comments (log_id, created_at, id);
+
+CREATE TABLE IF NOT EXISTS log_likes (
+    log_id     BIGINT      NOT NULL REFERENCES movie_log (id) ON DELETE CASCADE,
+    user_id    BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (log_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_log_likes_user_id ON log_likes (user_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_log_likes_user_id;
+DROP TABLE IF EXISTS log_likes;
+DROP INDEX IF EXISTS idx_log_comments_log_id;
+DROP TABLE IF EXISTS log_comments;
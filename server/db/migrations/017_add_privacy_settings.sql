@@ -0,0 +1,20 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN IF NOT EXISTS log_visibility TEXT NOT NULL DEFAULT 'public';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS hide_notes BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE users
+    ADD CONSTRAINT users_log_visibility_valid
+        CHECK (log_visibility IN ('public', 'friends', 'private'));
+
+ALTER TABLE movie_log ADD COLUMN IF NOT EXISTS visibility TEXT;
+
+ALTER TABLE movie_log
+    ADD CONSTRAINT movie_log_visibility_valid
+        CHECK (visibility IS NULL OR visibility IN ('public', 'friends', 'private'));
+
+-- +goose Down
+ALTER TABLE movie_log DROP CONSTRAINT IF EXISTS movie_log_visibility_valid;
+ALTER TABLE movie_log DROP COLUMN IF EXISTS visibility;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_log_visibility_valid;
+ALTER TABLE users DROP COLUMN IF EXISTS hide_notes;
+ALTER TABLE users DROP COLUMN IF EXISTS log_visibility;
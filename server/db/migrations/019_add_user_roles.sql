@@ -0,0 +1,10 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT 'user';
+
+ALTER TABLE users
+    ADD CONSTRAINT users_role_valid
+        CHECK (role IN ('user', 'moderator', 'admin'));
+
+-- +goose Down
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_valid;
+ALTER TABLE users DROP COLUMN IF EXISTS role;
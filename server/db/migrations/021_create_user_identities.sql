@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS user_identities (
+    issuer     TEXT        NOT NULL,
+    subject    TEXT        NOT NULL,
+    user_id    BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    email      TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (issuer, subject),
+    CONSTRAINT user_identities_user_issuer_unique UNIQUE (user_id, issuer)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS user_identities;
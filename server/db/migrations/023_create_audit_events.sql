@@ -0,0 +1,38 @@
+-- +goose Up
+-- actor_id has no foreign key so events outlive the users they mention.
+CREATE TABLE IF NOT EXISTS audit_events (
+    id             BIGSERIAL   NOT NULL PRIMARY KEY,
+    actor_id       BIGINT,
+    actor_username TEXT,
+    action         TEXT        NOT NULL,
+    target_type    TEXT        NOT NULL,
+    target_id      BIGINT,
+    request_id     TEXT,
+    ip             TEXT,
+    before         JSONB,
+    after          JSONB,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_created ON audit_events (created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor ON audit_events (actor_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_events_target ON audit_events (target_type, target_id, created_at DESC);
+
+-- Audit events are append-only.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+DROP TRIGGER IF EXISTS audit_events_append_only ON audit_events;
+CREATE TRIGGER audit_events_append_only
+    BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_events
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_events_append_only();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS audit_events_append_only ON audit_events;
+DROP FUNCTION IF EXISTS audit_events_append_only();
+DROP TABLE IF EXISTS audit_events;
@@ -0,0 +1,21 @@
+-- +goose Up
+ALTER TABLE rank_sessions ADD COLUMN IF NOT EXISTS sentiment TEXT;
+
+-- Sessions started before the band was stored take the entry's band. Ones
+-- for entries without a band could never finish and are dropped.
+UPDATE rank_sessions rs
+SET sentiment = ml.sentiment
+FROM movie_log ml
+WHERE ml.id = rs.log_id AND rs.sentiment IS NULL;
+
+DELETE FROM rank_sessions WHERE sentiment IS NULL;
+
+ALTER TABLE rank_sessions ALTER COLUMN sentiment SET NOT NULL;
+
+ALTER TABLE rank_sessions
+    ADD CONSTRAINT rank_sessions_sentiment_valid
+        CHECK (sentiment IN ('liked', 'fine', 'disliked'));
+
+-- +goose Down
+ALTER TABLE rank_sessions DROP CONSTRAINT IF EXISTS rank_sessions_sentiment_valid;
+ALTER TABLE rank_sessions DROP COLUMN IF EXISTS sentiment;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Log listings sort by (key, id), so the id tiebreaker belongs in the index
+-- for a page to be read straight off it.
+DROP INDEX IF EXISTS idx_movie_log_user_watched_on;
+CREATE INDEX IF NOT EXISTS idx_movie_log_user_watched_on ON movie_log (user_id, watched_on DESC, id DESC);
+
+DROP INDEX IF EXISTS idx_movie_log_user_created_at;
+CREATE INDEX IF NOT EXISTS idx_movie_log_user_created_at ON movie_log (user_id, created_at DESC, id DESC);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_movie_log_user_created_at;
+CREATE INDEX IF NOT EXISTS idx_movie_log_user_created_at ON movie_log (user_id, created_at DESC);
+
+DROP INDEX IF EXISTS idx_movie_log_user_watched_on;
+CREATE INDEX IF NOT EXISTS idx_movie_log_user_watched_on ON movie_log (user_id, watched_on DESC);
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Emails stored before this column existed were never checked, so they are
+-- all treated as unverified.
+ALTER TABLE user_identities ADD COLUMN IF NOT EXISTS email_verified BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_verified_email
+    ON user_identities (lower(email)) WHERE email_verified;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_user_identities_verified_email;
+ALTER TABLE user_identities DROP COLUMN IF EXISTS email_verified;
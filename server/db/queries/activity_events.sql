@@ -0,0 +1,43 @@
+-- name: RecordActivityEvent :exec
+INSERT INTO activity_events (user_id, log_id, kind, note, rating, sentiment, rank_position, watched_on)
+VALUES (@user_id, @log_id, @kind, @note, @rating, @sentiment, @rank_position, @watched_on);
+
+-- name: ListFeedEvents :many
+-- Logged and noted events show the entry's current note, so an edited or
+-- cleared note does not live on in the feed. A rewatch's note belongs to the
+-- viewing and is kept from the event.
+SELECT ae.id, ae.user_id, u.username, u.display_name, u.avatar_url, ae.kind, ae.log_id,
+       ml.movie_id, mi.original_title,
+       CASE
+           WHEN u.hide_notes THEN NULL
+           WHEN ae.kind IN ('logged', 'noted') THEN ml.note
+           ELSE ae.note
+       END AS note,
+       ae.rating, ae.sentiment, ae.rank_position,
+       ae.watched_on, ae.created_at
+FROM activity_events ae
+JOIN friendships f ON f.followee_id = ae.user_id
+JOIN users u ON u.id = ae.user_id
+JOIN movie_log ml ON ml.id = ae.log_id
+JOIN movie_ids mi ON mi.id = ml.movie_id
+WHERE f.follower_id = @user_id
+  AND f.status = 'accepted'
+  AND (
+      COALESCE(ml.visibility, u.log_visibility) = 'public'
+      OR (
+          COALESCE(ml.visibility, u.log_visibility) = 'friends'
+          AND EXISTS (
+              SELECT 1
+              FROM friendships back
+              WHERE back.follower_id = ae.user_id
+                AND back.followee_id = @user_id
+                AND back.status = 'accepted'
+          )
+      )
+  )
+  AND (
+      sqlc.narg(cursor_created_at)::timestamptz IS NULL
+      OR (ae.created_at, ae.id) < (sqlc.narg(cursor_created_at)::timestamptz, @cursor_id::bigint)
+  )
+ORDER BY ae.created_at DESC, ae.id DESC
+LIMIT @page_size::int;
@@ -0,0 +1,28 @@
+-- name: CreateAPIToken :one
+INSERT INTO api_tokens (user_id, name, token_hash, scopes, expires_at)
+VALUES (@user_id, @name, @token_hash, @scopes::text[], sqlc.narg(expires_at))
+RETURNING id, user_id, name, token_hash, scopes, expires_at, last_used_at, created_at;
+
+-- name: ListAPITokens :many
+SELECT id, user_id, name, token_hash, scopes, expires_at, last_used_at, created_at
+FROM api_tokens
+WHERE user_id = @user_id
+ORDER BY created_at DESC, id DESC;
+
+-- name: DeleteAPIToken :execrows
+DELETE FROM api_tokens
+WHERE id = @id
+  AND user_id = @user_id;
+
+-- name: GetAPITokenUser :one
+SELECT t.id AS token_id, t.scopes, u.id, u.username, u.display_name, u.avatar_url, u.role
+FROM api_tokens t
+JOIN users u ON u.id = t.user_id
+WHERE t.token_hash = @token_hash
+  AND (t.expires_at IS NULL OR t.expires_at > now());
+
+-- name: TouchAPIToken :exec
+UPDATE api_tokens
+SET last_used_at = now()
+WHERE id = @id
+  AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute');
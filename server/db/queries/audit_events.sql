@@ -0,0 +1,24 @@
+-- name: CreateAuditEvent :exec
+INSERT INTO audit_events (
+    actor_id, actor_username, action, target_type, target_id, request_id, ip, before, after
+)
+VALUES (
+    @actor_id, @actor_username, @action, @target_type, @target_id, @request_id, @ip, @before, @after
+);
+
+-- name: ListAuditEvents :many
+SELECT id, actor_id, actor_username, action, target_type, target_id, request_id, ip,
+       before, after, created_at
+FROM audit_events
+WHERE (sqlc.narg(actor_id)::bigint IS NULL OR actor_id = sqlc.narg(actor_id)::bigint)
+  AND (sqlc.narg(action)::text IS NULL OR action = sqlc.narg(action)::text)
+  AND (sqlc.narg(target_type)::text IS NULL OR target_type = sqlc.narg(target_type)::text)
+  AND (sqlc.narg(target_id)::bigint IS NULL OR target_id = sqlc.narg(target_id)::bigint)
+  AND (sqlc.narg(since)::timestamptz IS NULL OR created_at >= sqlc.narg(since)::timestamptz)
+  AND (sqlc.narg(until)::timestamptz IS NULL OR created_at < sqlc.narg(until)::timestamptz)
+  AND (
+      sqlc.narg(cursor_created_at)::timestamptz IS NULL
+      OR (created_at, id) < (sqlc.narg(cursor_created_at)::timestamptz, @cursor_id::bigint)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT @page_size::int;
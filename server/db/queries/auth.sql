@@ -0,0 +1,69 @@
+-- name: CreateUserCredentials :exec
+INSERT INTO user_credentials (user_id, password_hash)
+VALUES (@user_id, @password_hash);
+
+-- name: SetUserCredentials :exec
+INSERT INTO user_credentials (user_id, password_hash)
+VALUES (@user_id, @password_hash)
+ON CONFLICT (user_id) DO UPDATE
+SET password_hash = EXCLUDED.password_hash,
+    updated_at = now();
+
+-- name: GetUserCredentialsByUsername :one
+SELECT u.id, u.username, uc.password_hash
+FROM users u
+JOIN user_credentials uc ON uc.user_id = u.id
+WHERE lower(u.username) = lower(@username::text);
+
+-- name: CreateUserSession :exec
+INSERT INTO user_sessions (token_hash, user_id, user_agent, ip, expires_at)
+VALUES (@token_hash, @user_id, sqlc.narg(user_agent), sqlc.narg(ip), @expires_at);
+
+-- name: GetSessionUser :one
+SELECT u.id, u.username, u.display_name, u.avatar_url, u.role, s.expires_at
+FROM user_sessions s
+JOIN users u ON u.id = s.user_id
+WHERE s.token_hash = @token_hash
+  AND s.expires_at > now();
+
+-- name: DeleteUserSession :exec
+DELETE FROM user_sessions
+WHERE token_hash = @token_hash;
+
+-- name: DeleteUserSessionsByUser :exec
+DELETE FROM user_sessions
+WHERE user_id = @user_id;
+
+-- name: DeleteExpiredUserSessions :exec
+DELETE FROM user_sessions
+WHERE user_id = @user_id
+  AND expires_at <= now();
+
+-- name: GetUserIdentity :one
+SELECT user_id
+FROM user_identities
+WHERE issuer = @issuer
+  AND subject = @subject;
+
+-- name: CreateUserIdentity :exec
+INSERT INTO user_identities (issuer, subject, user_id, email, email_verified)
+VALUES (@issuer, @subject, @user_id, sqlc.narg(email), @email_verified);
+
+-- name: ListUserIDsByVerifiedEmail :many
+SELECT user_id
+FROM user_identities
+WHERE lower(email) = lower(@email::text)
+  AND email_verified
+GROUP BY user_id;
+
+-- name: GetOIDCLinkStatus :one
+SELECT u.role,
+       EXISTS (SELECT 1 FROM user_credentials uc WHERE uc.user_id = u.id) AS has_password,
+       EXISTS (
+           SELECT 1
+           FROM user_identities ui
+           WHERE ui.user_id = u.id
+             AND ui.issuer = @issuer
+       ) AS has_issuer_identity
+FROM users u
+WHERE u.id = @user_id;
@@ -0,0 +1,80 @@
+-- name: CreateFollowRequest :one
+INSERT INTO friendships (follower_id, followee_id)
+VALUES (@follower_id, @followee_id)
+ON CONFLICT (follower_id, followee_id) DO NOTHING
+RETURNING follower_id, followee_id, status, created_at, updated_at;
+
+-- name: AcceptFollowRequest :one
+UPDATE friendships
+SET status = 'accepted',
+    updated_at = now()
+WHERE follower_id = @follower_id AND followee_id = @followee_id AND status = 'pending'
+RETURNING follower_id, followee_id, status, created_at, updated_at;
+
+-- name: DeleteFollowRequest :execrows
+DELETE FROM friendships
+WHERE follower_id = @follower_id AND followee_id = @followee_id AND status = 'pending';
+
+-- name: DeleteFollow :execrows
+DELETE FROM friendships
+WHERE follower_id = @follower_id AND followee_id = @followee_id AND status = 'accepted';
+
+-- name: ListFollowers :many
+SELECT u.id, u.username, u.display_name, u.avatar_url, f.updated_at AS since
+FROM friendships f
+JOIN users u ON u.id = f.follower_id
+WHERE f.followee_id = @user_id AND f.status = 'accepted'
+ORDER BY f.updated_at DESC, u.id ASC;
+
+-- name: ListFollowing :many
+SELECT u.id, u.username, u.display_name, u.avatar_url, f.updated_at AS since
+FROM friendships f
+JOIN users u ON u.id = f.followee_id
+WHERE f.follower_id = @user_id AND f.status = 'accepted'
+ORDER BY f.updated_at DESC, u.id ASC;
+
+-- name: ListFriends :many
+SELECT u.id, u.username, u.display_name, u.avatar_url, GREATEST(f.updated_at, r.updated_at)::timestamptz AS since
+FROM friendships f
+JOIN friendships r ON r.follower_id = f.followee_id AND r.followee_id = f.follower_id
+JOIN users u ON u.id = f.followee_id
+WHERE f.follower_id = @user_id AND f.status = 'accepted' AND r.status = 'accepted'
+ORDER BY u.username ASC;
+
+-- name: ListIncomingFollowRequests :many
+SELECT u.id, u.username, u.display_name, u.avatar_url, f.created_at AS since
+FROM friendships f
+JOIN users u ON u.id = f.follower_id
+WHERE f.followee_id = @user_id AND f.status = 'pending'
+ORDER BY f.created_at DESC, u.id ASC;
+
+-- name: ListOutgoingFollowRequests :many
+SELECT u.id, u.username, u.display_name, u.avatar_url, f.created_at AS since
+FROM friendships f
+JOIN users u ON u.id = f.followee_id
+WHERE f.follower_id = @user_id AND f.status = 'pending'
+ORDER BY f.created_at DESC, u.id ASC;
+
+-- name: GetRelationship :one
+SELECT
+    COALESCE((
+        SELECT status
+        FROM friendships
+        WHERE follower_id = @user_id AND followee_id = @other_user_id
+    ), '')::text AS outgoing_status,
+    COALESCE((
+        SELECT status
+        FROM friendships
+        WHERE follower_id = @other_user_id AND followee_id = @user_id
+    ), '')::text AS incoming_status;
+
+-- name: AreFriends :one
+SELECT EXISTS (
+    SELECT 1
+    FROM friendships f
+    JOIN friendships back ON back.follower_id = f.followee_id AND back.followee_id = f.follower_id
+    WHERE f.follower_id = @user_id
+      AND f.followee_id = @other_user_id
+      AND f.status = 'accepted'
+      AND back.status = 'accepted'
+);
@@ -0,0 +1,70 @@
+-- name: CreateLogComment :one
+INSERT INTO log_comments (log_id, user_id, parent_id, body)
+VALUES (@log_id, @user_id, @parent_id, @body)
+RETURNING id, log_id, user_id, parent_id, body, created_at, updated_at;
+
+-- name: GetLogComment :one
+SELECT id, log_id, user_id, parent_id, body, created_at, updated_at
+FROM log_comments
+WHERE id = @id AND log_id = @log_id;
+
+-- name: UpdateLogComment :one
+UPDATE log_comments
+SET body = @body,
+    updated_at = now()
+WHERE id = @id AND log_id = @log_id
+RETURNING id, log_id, user_id, parent_id, body, created_at, updated_at;
+
+-- name: DeleteLogComment :execrows
+DELETE FROM log_comments
+WHERE id = @id AND log_id = @log_id;
+
+-- name: ListLogComments :many
+SELECT lc.id, lc.log_id, lc.user_id, u.username, u.display_name, u.avatar_url,
+       lc.parent_id, lc.body, lc.created_at, lc.updated_at
+FROM log_comments lc
+JOIN users u ON u.id = lc.user_id
+WHERE lc.log_id = @log_id
+ORDER BY lc.created_at ASC, lc.id ASC;
+
+-- name: LikeMovieLogEntry :execrows
+INSERT INTO log_likes (log_id, user_id)
+VALUES (@log_id, @user_id)
+ON CONFLICT (log_id, user_id) DO NOTHING;
+
+-- name: UnlikeMovieLogEntry :execrows
+DELETE FROM log_likes
+WHERE log_id = @log_id AND user_id = @user_id;
+
+-- name: ListMovieLogLikes :many
+SELECT u.id, u.username, u.display_name, u.avatar_url, ll.created_at
+FROM log_likes ll
+JOIN users u ON u.id = ll.user_id
+WHERE ll.log_id = @log_id
+ORDER BY ll.created_at DESC, u.id ASC;
+
+-- name: GetMovieLogEngagement :one
+SELECT
+    (SELECT COUNT(*) FROM log_likes ll WHERE ll.log_id = @log_id) AS like_count,
+    (SELECT COUNT(*) FROM log_comments lc WHERE lc.log_id = @log_id) AS comment_count;
+
+-- name: ListLogCommentsByAuthor :many
+SELECT owner.username AS owner_username, mi.original_title, ml.movie_id,
+       parent_author.username AS reply_to, lc.body, lc.created_at, lc.updated_at
+FROM log_comments lc
+JOIN movie_log ml ON ml.id = lc.log_id
+JOIN movie_ids mi ON mi.id = ml.movie_id
+JOIN users owner ON owner.id = ml.user_id
+LEFT JOIN log_comments parent ON parent.id = lc.parent_id
+LEFT JOIN users parent_author ON parent_author.id = parent.user_id
+WHERE lc.user_id = @user_id
+ORDER BY lc.created_at ASC, lc.id ASC;
+
+-- name: ListLogLikesByUser :many
+SELECT owner.username AS owner_username, mi.original_title, ml.movie_id, ll.created_at
+FROM log_likes ll
+JOIN movie_log ml ON ml.id = ll.log_id
+JOIN movie_ids mi ON mi.id = ml.movie_id
+JOIN users owner ON owner.id = ml.user_id
+WHERE ll.user_id = @user_id
+ORDER BY ll.created_at ASC, ll.log_id ASC;
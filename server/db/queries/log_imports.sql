@@ -0,0 +1,85 @@
+-- name: CreateLogImport :one
+INSERT INTO log_imports (user_id, source, film_count)
+VALUES (@user_id, @source, @film_count)
+RETURNING id, user_id, source, film_count, imported_count, queued_count, existing_count,
+          status, processed_count, ranked_count, error, finished_at, created_at;
+
+-- name: UpdateLogImportProgress :exec
+UPDATE log_imports
+SET processed_count = @processed_count,
+    imported_count = @imported_count,
+    queued_count = @queued_count,
+    existing_count = @existing_count,
+    ranked_count = @ranked_count
+WHERE id = @id;
+
+-- name: FinishLogImport :one
+UPDATE log_imports
+SET status = 'succeeded',
+    processed_count = @processed_count,
+    imported_count = @imported_count,
+    queued_count = @queued_count,
+    existing_count = @existing_count,
+    ranked_count = @ranked_count,
+    finished_at = now()
+WHERE id = @id
+RETURNING id, user_id, source, film_count, imported_count, queued_count, existing_count,
+          status, processed_count, ranked_count, error, finished_at, created_at;
+
+-- name: FailLogImport :exec
+UPDATE log_imports
+SET status = 'failed',
+    error = @error,
+    finished_at = now()
+WHERE id = @id;
+
+-- name: FailInterruptedLogImports :execrows
+UPDATE log_imports
+SET status = 'failed',
+    error = 'interrupted by a server restart',
+    finished_at = now()
+WHERE status = 'running';
+
+-- name: GetLogImport :one
+SELECT id, user_id, source, film_count, imported_count, queued_count, existing_count,
+       status, processed_count, ranked_count, error, finished_at, created_at
+FROM log_imports
+WHERE id = @id AND user_id = @user_id;
+
+-- name: RunningLogImportExists :one
+SELECT EXISTS (
+    SELECT 1
+    FROM log_imports
+    WHERE user_id = @user_id AND status = 'running'
+);
+
+-- name: CreateLogImportReview :exec
+INSERT INTO log_import_reviews (
+    import_id, user_id, title, release_year, watched_dates, rating, review, candidate_movie_ids
+)
+VALUES (
+    @import_id, @user_id, @title, @release_year, @watched_dates, @rating, @review, @candidate_movie_ids
+);
+
+-- name: ListLogImportReviews :many
+SELECT id, import_id, user_id, title, release_year, watched_dates, rating, review,
+       candidate_movie_ids, status, movie_id, log_id, created_at, updated_at
+FROM log_import_reviews
+WHERE user_id = @user_id AND status = @status
+ORDER BY id ASC;
+
+-- name: GetLogImportReview :one
+SELECT id, import_id, user_id, title, release_year, watched_dates, rating, review,
+       candidate_movie_ids, status, movie_id, log_id, created_at, updated_at
+FROM log_import_reviews
+WHERE id = @id AND user_id = @user_id;
+
+-- name: ResolveLogImportReview :one
+UPDATE log_import_reviews
+SET status = @status,
+    movie_id = @movie_id,
+    log_id = @log_id,
+    updated_at = now()
+WHERE id = @id AND user_id = @user_id
+RETURNING id, import_id, user_id, title, release_year, watched_dates, rating, review,
+          candidate_movie_ids, status, movie_id, log_id, created_at, updated_at;
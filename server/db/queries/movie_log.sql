@@ -1,20 +1,261 @@
--- name: ListMovieLogByUser :many
+-- name: ListMovieLogIDs :many
+-- Pages through a user's log in the order sort_by and descending ask for,
+-- with id as the tiebreaker. The cursor is the last entry of the previous
+-- page: its id, and its sort key in the cursor column for sort_by; the other
+-- cursor columns are NULL. ListMovieLogByIDs then loads the entries on the
+-- page.
+SELECT ml.id
+FROM movie_log ml
+JOIN movie_ids mi ON mi.id = ml.movie_id
+JOIN users u ON u.id = ml.user_id
+WHERE ml.user_id = @user_id
+  AND COALESCE(ml.visibility, u.log_visibility) = ANY(@visibilities::text[])
+  AND (
+      COALESCE(cardinality(@tag_names::text[]), 0) = 0
+      OR (
+          SELECT COUNT(*)
+          FROM movie_log_tags mlt
+          JOIN tags t ON t.id = mlt.tag_id
+          WHERE mlt.log_id = ml.id AND t.name = ANY(@tag_names::text[])
+      ) >= CASE WHEN @match_all::boolean THEN cardinality(@tag_names::text[]) ELSE 1 END
+  )
+  AND (sqlc.narg(watched_from)::date IS NULL OR ml.watched_on >= sqlc.narg(watched_from)::date)
+  AND (sqlc.narg(watched_to)::date IS NULL OR ml.watched_on <= sqlc.narg(watched_to)::date)
+  AND (sqlc.narg(ranked)::boolean IS NULL OR (ml.rank_position IS NOT NULL) = sqlc.narg(ranked)::boolean)
+  AND (
+      sqlc.narg(search)::text IS NULL
+      OR mi.original_title ILIKE sqlc.narg(search)::text
+      OR (@show_notes::boolean AND ml.note ILIKE sqlc.narg(search)::text)
+  )
+  AND (
+      sqlc.narg(cursor_id)::bigint IS NULL
+      OR CASE
+          WHEN @sort_by::text = 'watched_on' AND @descending::boolean
+              THEN (ml.watched_on, ml.id) < (sqlc.narg(cursor_watched_on)::date, sqlc.narg(cursor_id)::bigint)
+          WHEN @sort_by::text = 'watched_on'
+              THEN (ml.watched_on, ml.id) > (sqlc.narg(cursor_watched_on)::date, sqlc.narg(cursor_id)::bigint)
+          WHEN @sort_by::text = 'created_at' AND @descending::boolean
+              THEN (ml.created_at, ml.id) < (sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::bigint)
+          WHEN @sort_by::text = 'created_at'
+              THEN (ml.created_at, ml.id) > (sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::bigint)
+          WHEN @sort_by::text = 'title' AND @descending::boolean
+              THEN (mi.original_title, ml.id) < (sqlc.narg(cursor_title)::text, sqlc.narg(cursor_id)::bigint)
+          WHEN @sort_by::text = 'title'
+              THEN (mi.original_title, ml.id) > (sqlc.narg(cursor_title)::text, sqlc.narg(cursor_id)::bigint)
+          WHEN @descending::boolean
+              THEN (COALESCE(ml.rank_position, 2147483647), ml.id) < (sqlc.narg(cursor_rank)::integer, sqlc.narg(cursor_id)::bigint)
+          ELSE (COALESCE(ml.rank_position, 2147483647), ml.id) > (sqlc.narg(cursor_rank)::integer, sqlc.narg(cursor_id)::bigint)
+      END
+  )
+ORDER BY
+    CASE WHEN @sort_by::text = 'watched_on' AND NOT @descending::boolean THEN ml.watched_on END ASC,
+    CASE WHEN @sort_by::text = 'watched_on' AND @descending::boolean THEN ml.watched_on END DESC,
+    CASE WHEN @sort_by::text = 'created_at' AND NOT @descending::boolean THEN ml.created_at END ASC,
+    CASE WHEN @sort_by::text = 'created_at' AND @descending::boolean THEN ml.created_at END DESC,
+    CASE WHEN @sort_by::text = 'title' AND NOT @descending::boolean THEN mi.original_title END ASC,
+    CASE WHEN @sort_by::text = 'title' AND @descending::boolean THEN mi.original_title END DESC,
+    CASE WHEN @sort_by::text = 'rank' AND NOT @descending::boolean THEN COALESCE(ml.rank_position, 2147483647) END ASC,
+    CASE WHEN @sort_by::text = 'rank' AND @descending::boolean THEN COALESCE(ml.rank_position, 2147483647) END DESC,
+    CASE WHEN @descending::boolean THEN ml.id END DESC,
+    ml.id ASC
+LIMIT @page_size::integer;
+
+-- name: ListMovieLogByIDs :many
 SELECT ml.id AS log_id, ml.user_id, ml.movie_id, mi.original_title, ml.watched_on,
-       ml.note, ml.rank_position, ml.created_at, ml.updated_at
+       CASE WHEN @show_notes::boolean THEN ml.note END AS note,
+       ml.rank_position, ml.sentiment, ml.rating, ml.version, ml.visibility, ml.created_at, ml.updated_at,
+       (SELECT COUNT(*) FROM movie_log_viewings mlv WHERE mlv.log_id = ml.id) AS viewing_count,
+       ARRAY(
+           SELECT t.name
+           FROM movie_log_tags mlt
+           JOIN tags t ON t.id = mlt.tag_id
+           WHERE mlt.log_id = ml.id
+           ORDER BY t.name
+       )::text[] AS tags,
+       (SELECT COUNT(*) FROM log_likes ll WHERE ll.log_id = ml.id) AS like_count,
+       (SELECT COUNT(*) FROM log_comments lc WHERE lc.log_id = ml.id) AS comment_count,
+       EXISTS (
+           SELECT 1
+           FROM log_likes ll
+           WHERE ll.log_id = ml.id AND ll.user_id = sqlc.narg(viewer_id)::bigint
+       ) AS liked_by_me
 FROM movie_log ml
 JOIN movie_ids mi ON mi.id = ml.movie_id
 WHERE ml.user_id = @user_id
-ORDER BY (ml.rank_position IS NULL), ml.rank_position ASC NULLS LAST, ml.created_at DESC;
-
--- name: UpsertMovieLogEntry :one
-INSERT INTO movie_log (user_id, movie_id, watched_on, note, rank_position)
-VALUES (@user_id, @movie_id, @watched_on, @note, NULL)
-ON CONFLICT (user_id, movie_id) DO UPDATE
-SET watched_on = EXCLUDED.watched_on,
-    note = EXCLUDED.note,
+  AND ml.id = ANY(@log_ids::bigint[]);
+
+-- name: ExportMovieLogPage :many
+SELECT ml.id AS log_id, ml.movie_id, mi.original_title, ml.watched_on,
+       CASE WHEN @show_notes::boolean THEN ml.note END AS note,
+       ml.rank_position, ml.sentiment, ml.rating, ml.visibility, ml.created_at, ml.updated_at,
+       (SELECT COUNT(*) FROM movie_log_viewings mlv WHERE mlv.log_id = ml.id) AS viewing_count,
+       ARRAY(
+           SELECT t.name
+           FROM movie_log_tags mlt
+           JOIN tags t ON t.id = mlt.tag_id
+           WHERE mlt.log_id = ml.id
+           ORDER BY t.name
+       )::text[] AS tags
+FROM movie_log ml
+JOIN movie_ids mi ON mi.id = ml.movie_id
+JOIN users u ON u.id = ml.user_id
+WHERE ml.user_id = @user_id
+  AND ml.id > @after_id::bigint
+  AND COALESCE(ml.visibility, u.log_visibility) = ANY(@visibilities::text[])
+ORDER BY ml.id ASC
+LIMIT @page_size::integer;
+
+-- name: CreateMovieLogEntry :one
+INSERT INTO movie_log (user_id, movie_id, watched_on, note, rating, visibility)
+VALUES (@user_id, @movie_id, @watched_on, @note, @rating, @visibility)
+ON CONFLICT (user_id, movie_id) DO NOTHING
+RETURNING id, user_id, movie_id, watched_on, note, rank_position, sentiment, rating, version, visibility, created_at, updated_at;
+
+-- name: PatchMovieLogEntry :one
+UPDATE movie_log
+SET note = CASE WHEN @set_note::boolean THEN sqlc.narg(note)::text ELSE note END,
+    rating = CASE WHEN @set_rating::boolean THEN sqlc.narg(rating)::numeric ELSE rating END,
+    visibility = CASE WHEN @set_visibility::boolean THEN sqlc.narg(visibility)::text ELSE visibility END,
+    version = version + 1,
     updated_at = now()
-RETURNING id, user_id, movie_id, watched_on, note, rank_position, created_at, updated_at;
+WHERE id = @id AND user_id = @user_id
+RETURNING id, user_id, movie_id, watched_on, note, rank_position, sentiment, rating, version, visibility, created_at, updated_at;
 
--- name: DeleteMovieLogEntry :execrows
+-- name: GetMovieLogVersion :one
+SELECT version
+FROM movie_log
+WHERE id = @id AND user_id = @user_id;
+
+-- name: TouchMovieLogEntry :exec
+UPDATE movie_log
+SET version = version + 1,
+    updated_at = now()
+WHERE id = @id AND user_id = @user_id;
+
+-- name: MovieLogEntryExists :one
+SELECT EXISTS (
+    SELECT 1
+    FROM movie_log
+    WHERE id = @id AND user_id = @user_id
+);
+
+-- name: SyncMovieLogWatchedOn :one
+UPDATE movie_log ml
+SET watched_on = latest.watched_on,
+    version = ml.version + 1,
+    updated_at = now()
+FROM (
+    SELECT MAX(watched_on) AS watched_on
+    FROM movie_log_viewings
+    WHERE log_id = @id
+) latest
+WHERE ml.id = @id AND latest.watched_on IS NOT NULL
+RETURNING ml.watched_on, ml.version;
+
+-- name: DeleteMovieLogEntry :one
 DELETE FROM movie_log
+WHERE id = @id AND user_id = @user_id
+RETURNING id, user_id, movie_id, watched_on, note, rank_position, sentiment, rating,
+          version, visibility, created_at, updated_at;
+
+-- name: GetMovieLogRankEntry :one
+SELECT ml.id AS log_id, ml.movie_id, mi.original_title, ml.rank_position, ml.sentiment
+FROM movie_log ml
+JOIN movie_ids mi ON mi.id = ml.movie_id
+WHERE ml.id = @id AND ml.user_id = @user_id;
+
+-- name: GetMovieLogEntryAtRank :one
+SELECT ml.id AS log_id, ml.movie_id, mi.original_title, ml.rank_position, ml.sentiment
+FROM movie_log ml
+JOIN movie_ids mi ON mi.id = ml.movie_id
+WHERE ml.user_id = @user_id AND ml.rank_position = @rank_position::int;
+
+-- name: CountRankedMovieLog :one
+SELECT COUNT(*)
+FROM movie_log
+WHERE user_id = @user_id AND rank_position IS NOT NULL;
+
+-- name: CountRankedMovieLogBySentiment :many
+SELECT sentiment, COUNT(*) AS ranked_count
+FROM movie_log
+WHERE user_id = @user_id AND rank_position IS NOT NULL
+GROUP BY sentiment;
+
+-- name: SetMovieLogRankPosition :exec
+UPDATE movie_log
+SET rank_position = @rank_position,
+    version = version + 1,
+    updated_at = now()
+WHERE id = @id AND user_id = @user_id;
+
+-- name: SetMovieLogSentiment :exec
+UPDATE movie_log
+SET sentiment = @sentiment,
+    version = version + 1,
+    updated_at = now()
+WHERE id = @id AND user_id = @user_id;
+
+-- name: ParkRankPositions :exec
+UPDATE movie_log
+SET rank_position = rank_position + 1000000
+WHERE user_id = @user_id
+  AND rank_position BETWEEN @from_position::int AND @to_position::int;
+
+-- name: UnparkRankPositions :exec
+UPDATE movie_log
+SET rank_position = rank_position - 1000000 + @delta::int,
+    version = version + 1
+WHERE user_id = @user_id
+  AND rank_position > 1000000;
+
+-- name: ListRankedMovieLogByUser :many
+SELECT ml.id AS log_id, ml.movie_id, mi.original_title, ml.rank_position, ml.sentiment, ml.rating, ml.version,
+       ml.visibility,
+       ROW_NUMBER() OVER (PARTITION BY ml.sentiment ORDER BY ml.rank_position) AS band_position,
+       COUNT(*) OVER (PARTITION BY ml.sentiment) AS band_size
+FROM movie_log ml
+JOIN movie_ids mi ON mi.id = ml.movie_id
+WHERE ml.user_id = @user_id AND ml.rank_position IS NOT NULL
+ORDER BY ml.rank_position ASC;
+
+-- name: ClearRankPositions :exec
+UPDATE movie_log
+SET rank_position = NULL,
+    version = version + 1,
+    updated_at = now()
+WHERE user_id = @user_id AND rank_position IS NOT NULL;
+
+-- name: ListMovieLogSentiments :many
+SELECT id, sentiment
+FROM movie_log
+WHERE user_id = @user_id AND id = ANY(@log_ids::bigint[]);
+
+-- name: ApplyRankOrder :execrows
+UPDATE movie_log ml
+SET rank_position = o.position::int,
+    version = ml.version + 1,
+    updated_at = now()
+FROM unnest(@log_ids::bigint[]) WITH ORDINALITY AS o (log_id, position)
+WHERE ml.id = o.log_id AND ml.user_id = @user_id;
+
+-- name: RatingHistogramByUser :many
+SELECT ml.rating, COUNT(*) AS rating_count
+FROM movie_log ml
+JOIN users u ON u.id = ml.user_id
+WHERE ml.user_id = @user_id
+  AND ml.rating IS NOT NULL
+  AND COALESCE(ml.visibility, u.log_visibility) = ANY(@visibilities::text[])
+GROUP BY ml.rating
+ORDER BY ml.rating ASC;
+
+-- name: GetMovieLogVisibility :one
+SELECT visibility
+FROM movie_log
 WHERE id = @id AND user_id = @user_id;
+
+-- name: ListLoggedMovieIDsByUser :many
+SELECT ml.movie_id
+FROM movie_log ml
+JOIN users u ON u.id = ml.user_id
+WHERE ml.user_id = @user_id
+  AND COALESCE(ml.visibility, u.log_visibility) = ANY(@visibilities::text[]);
@@ -0,0 +1,55 @@
+-- name: ListMovieLogViewings :many
+SELECT id, log_id, watched_on, note, created_at, updated_at
+FROM movie_log_viewings
+WHERE log_id = @log_id
+ORDER BY watched_on DESC, id DESC;
+
+-- name: CreateMovieLogViewing :one
+INSERT INTO movie_log_viewings (log_id, watched_on, note)
+VALUES (@log_id, @watched_on, @note)
+RETURNING id, log_id, watched_on, note, created_at, updated_at;
+
+-- name: AddFirstMovieLogViewing :exec
+INSERT INTO movie_log_viewings (log_id, watched_on, note)
+SELECT @log_id, @watched_on, @note
+WHERE NOT EXISTS (
+    SELECT 1
+    FROM movie_log_viewings
+    WHERE log_id = @log_id
+);
+
+-- name: SetLatestMovieLogViewingDate :exec
+UPDATE movie_log_viewings
+SET watched_on = @watched_on,
+    updated_at = now()
+WHERE id = (
+    SELECT latest.id
+    FROM movie_log_viewings latest
+    WHERE latest.log_id = @log_id
+    ORDER BY latest.watched_on DESC, latest.id DESC
+    LIMIT 1
+);
+
+-- name: CountMovieLogViewings :one
+SELECT COUNT(*)
+FROM movie_log_viewings
+WHERE log_id = @log_id;
+
+-- name: DeleteMovieLogViewing :execrows
+DELETE FROM movie_log_viewings
+WHERE id = @id AND log_id = @log_id;
+
+-- name: ListMovieLogViewingsByUser :many
+SELECT mlv.id, mlv.log_id, mlv.watched_on, mlv.note, mlv.created_at, mlv.updated_at
+FROM movie_log_viewings mlv
+JOIN movie_log ml ON ml.id = mlv.log_id
+WHERE ml.user_id = @user_id
+ORDER BY mlv.log_id ASC, mlv.watched_on ASC, mlv.id ASC;
+
+-- name: ListMovieLogViewingDates :many
+SELECT mlv.log_id, mlv.watched_on
+FROM movie_log_viewings mlv
+JOIN movie_log ml ON ml.id = mlv.log_id
+WHERE ml.user_id = @user_id
+  AND mlv.log_id = ANY(@log_ids::bigint[])
+ORDER BY mlv.log_id ASC, mlv.watched_on ASC, mlv.id ASC;
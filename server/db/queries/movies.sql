@@ -12,3 +12,13 @@ SELECT EXISTS (
     FROM movie_ids
     WHERE id = @id
 );
+
+-- name: ListMoviesByIDs :many
+SELECT id, original_title, popularity
+FROM movie_ids
+WHERE id = ANY(@ids::int[]);
+
+-- name: ListMovieIDsByIMDbIDs :many
+SELECT imdb_id, movie_id
+FROM imdb_movie_ids
+WHERE imdb_id = ANY(@imdb_ids::text[]);
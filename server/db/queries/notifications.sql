@@ -0,0 +1,57 @@
+-- name: CreateNotification :execrows
+INSERT INTO notifications (user_id, actor_id, kind, log_id, comment_id)
+SELECT @user_id::bigint, @actor_id::bigint, @kind::text, sqlc.narg(log_id)::bigint, sqlc.narg(comment_id)::bigint
+WHERE @user_id::bigint <> @actor_id::bigint
+  AND NOT EXISTS (
+      SELECT 1
+      FROM notification_preferences np
+      WHERE np.user_id = @user_id::bigint
+        AND np.kind = @kind::text
+        AND NOT np.enabled
+  );
+
+-- name: ListNotifications :many
+SELECT n.id, n.kind, n.actor_id, u.username, u.display_name, u.avatar_url,
+       n.log_id, ml.movie_id, mi.original_title, n.comment_id, lc.body AS comment_body,
+       n.read_at, n.created_at
+FROM notifications n
+JOIN users u ON u.id = n.actor_id
+LEFT JOIN movie_log ml ON ml.id = n.log_id
+LEFT JOIN movie_ids mi ON mi.id = ml.movie_id
+LEFT JOIN log_comments lc ON lc.id = n.comment_id
+WHERE n.user_id = @user_id
+  AND (NOT @unread_only::boolean OR n.read_at IS NULL)
+  AND (
+      sqlc.narg(cursor_created_at)::timestamptz IS NULL
+      OR (n.created_at, n.id) < (sqlc.narg(cursor_created_at)::timestamptz, @cursor_id::bigint)
+  )
+ORDER BY n.created_at DESC, n.id DESC
+LIMIT @page_size::int;
+
+-- name: CountUnreadNotifications :one
+SELECT COUNT(*)
+FROM notifications
+WHERE user_id = @user_id AND read_at IS NULL;
+
+-- name: MarkNotificationRead :execrows
+UPDATE notifications
+SET read_at = COALESCE(read_at, now())
+WHERE id = @id AND user_id = @user_id;
+
+-- name: MarkAllNotificationsRead :execrows
+UPDATE notifications
+SET read_at = now()
+WHERE user_id = @user_id AND read_at IS NULL;
+
+-- name: ListNotificationPreferences :many
+SELECT kind, enabled
+FROM notification_preferences
+WHERE user_id = @user_id
+ORDER BY kind;
+
+-- name: SetNotificationPreference :exec
+INSERT INTO notification_preferences (user_id, kind, enabled)
+VALUES (@user_id, @kind, @enabled)
+ON CONFLICT (user_id, kind) DO UPDATE
+SET enabled = EXCLUDED.enabled,
+    updated_at = now();
@@ -0,0 +1,33 @@
+-- name: ListRankSessionsByUser :many
+SELECT id, user_id, log_id, low_position, high_position, comparison_log_id, sentiment, created_at, updated_at
+FROM rank_sessions
+WHERE user_id = @user_id
+ORDER BY created_at DESC;
+
+-- name: GetRankSession :one
+SELECT id, user_id, log_id, low_position, high_position, comparison_log_id, sentiment, created_at, updated_at
+FROM rank_sessions
+WHERE id = @id AND user_id = @user_id;
+
+-- name: UpsertRankSession :one
+INSERT INTO rank_sessions (user_id, log_id, low_position, high_position, comparison_log_id, sentiment)
+VALUES (@user_id, @log_id, @low_position, @high_position, @comparison_log_id, @sentiment)
+ON CONFLICT (log_id) DO UPDATE
+SET low_position = EXCLUDED.low_position,
+    high_position = EXCLUDED.high_position,
+    comparison_log_id = EXCLUDED.comparison_log_id,
+    sentiment = EXCLUDED.sentiment,
+    updated_at = now()
+RETURNING id, user_id, log_id, low_position, high_position, comparison_log_id, sentiment, created_at, updated_at;
+
+-- name: DeleteRankSession :execrows
+DELETE FROM rank_sessions
+WHERE id = @id AND user_id = @user_id;
+
+-- name: DeleteRankSessionByLogID :exec
+DELETE FROM rank_sessions
+WHERE log_id = @log_id;
+
+-- name: DeleteRankSessionsByLogIDs :exec
+DELETE FROM rank_sessions
+WHERE log_id = ANY(@log_ids::bigint[]);
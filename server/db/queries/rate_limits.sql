@@ -0,0 +1,20 @@
+-- name: EnsureRateLimitBucket :exec
+INSERT INTO rate_limit_buckets (key, tokens, updated_at)
+VALUES (@key, @tokens, now())
+ON CONFLICT (key) DO NOTHING;
+
+-- name: LockRateLimitBucket :one
+SELECT key, tokens, updated_at, now()::timestamptz AS now
+FROM rate_limit_buckets
+WHERE key = @key
+FOR UPDATE;
+
+-- name: UpdateRateLimitBucket :exec
+UPDATE rate_limit_buckets
+SET tokens = @tokens,
+    updated_at = @updated_at
+WHERE key = @key;
+
+-- name: DeleteIdleRateLimitBuckets :execrows
+DELETE FROM rate_limit_buckets
+WHERE updated_at < now() - make_interval(secs => @idle_seconds::double precision);
@@ -0,0 +1,51 @@
+-- name: ListTagsByUser :many
+SELECT t.id, t.name, COUNT(ml.id) AS log_count
+FROM tags t
+JOIN users u ON u.id = t.user_id
+LEFT JOIN movie_log_tags mlt ON mlt.tag_id = t.id
+LEFT JOIN movie_log ml ON ml.id = mlt.log_id
+    AND COALESCE(ml.visibility, u.log_visibility) = ANY(@visibilities::text[])
+WHERE t.user_id = @user_id
+GROUP BY t.id, t.name
+HAVING @include_unused::boolean OR COUNT(ml.id) > 0
+ORDER BY log_count DESC, t.name ASC;
+
+-- name: ListMovieLogTagNames :many
+SELECT t.name
+FROM movie_log_tags mlt
+JOIN tags t ON t.id = mlt.tag_id
+WHERE mlt.log_id = @log_id
+ORDER BY t.name ASC;
+
+-- name: UpsertTag :one
+INSERT INTO tags (user_id, name)
+VALUES (@user_id, @name)
+ON CONFLICT (user_id, name) DO UPDATE
+SET name = EXCLUDED.name
+RETURNING id, user_id, name, created_at;
+
+-- name: AddMovieLogTag :exec
+INSERT INTO movie_log_tags (log_id, tag_id)
+VALUES (@log_id, @tag_id)
+ON CONFLICT (log_id, tag_id) DO NOTHING;
+
+-- name: RemoveMovieLogTag :execrows
+DELETE FROM movie_log_tags mlt
+USING tags t
+WHERE mlt.tag_id = t.id
+  AND mlt.log_id = @log_id
+  AND t.user_id = @user_id
+  AND t.name = @name;
+
+-- name: ClearMovieLogTags :exec
+DELETE FROM movie_log_tags
+WHERE log_id = @log_id;
+
+-- name: DeleteUnusedTags :exec
+DELETE FROM tags t
+WHERE t.user_id = @user_id
+  AND NOT EXISTS (
+      SELECT 1
+      FROM movie_log_tags mlt
+      WHERE mlt.tag_id = t.id
+  );
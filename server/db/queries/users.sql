@@ -1,12 +1,12 @@
 -- name: ListUsers :many
-SELECT id, username, display_name, bio, avatar_url, created_at, updated_at
+SELECT id, username, display_name, bio, avatar_url, log_visibility, hide_notes, role, created_at, updated_at
 FROM users
 ORDER BY id DESC;
 
 -- name: CreateUser :one
 INSERT INTO users (username)
 VALUES (@username)
-RETURNING id, username, display_name, bio, avatar_url, created_at, updated_at;
+RETURNING id, username, display_name, bio, avatar_url, log_visibility, hide_notes, role, created_at, updated_at;
 
 -- name: DeleteUser :execrows
 DELETE FROM users
@@ -18,3 +18,53 @@ SELECT EXISTS (
     FROM users
     WHERE id = @id
 );
+
+-- name: LockUser :one
+SELECT id
+FROM users
+WHERE id = @id
+FOR UPDATE;
+
+-- name: GetUser :one
+SELECT id, username, display_name, bio, avatar_url, log_visibility, hide_notes, role, created_at, updated_at
+FROM users
+WHERE id = @id;
+
+-- name: FillUserProfile :one
+UPDATE users
+SET display_name = COALESCE(display_name, sqlc.narg(display_name)::text),
+    bio = COALESCE(bio, sqlc.narg(bio)::text),
+    avatar_url = COALESCE(avatar_url, sqlc.narg(avatar_url)::text),
+    updated_at = now()
+WHERE id = @id
+RETURNING id, username, display_name, bio, avatar_url, log_visibility, hide_notes, role, created_at, updated_at;
+
+-- name: ListUserIDsByUsernames :many
+SELECT id, username
+FROM users
+WHERE lower(username) = ANY(@usernames::text[]);
+
+-- name: GetUserPrivacy :one
+SELECT log_visibility, hide_notes
+FROM users
+WHERE id = @id;
+
+-- name: UpdateUserPrivacy :one
+UPDATE users
+SET log_visibility = COALESCE(sqlc.narg(log_visibility)::text, log_visibility),
+    hide_notes = COALESCE(sqlc.narg(hide_notes)::boolean, hide_notes),
+    updated_at = now()
+WHERE id = @id
+RETURNING log_visibility, hide_notes;
+
+-- name: SetUserRole :one
+UPDATE users
+SET role = @role,
+    updated_at = now()
+WHERE id = @id
+RETURNING id, username, display_name, bio, avatar_url, log_visibility, hide_notes, role, created_at, updated_at;
+
+-- name: GetUserIDByUsername :one
+SELECT id
+FROM users
+WHERE lower(username) = lower(@username::text);
@@ -12,13 +12,18 @@ CREATE INDEX idx_movie_ids_popularity ON movie_ids (popularity);
 CREATE INDEX idx_movie_ids_title_trgm ON movie_ids USING GIN (original_title gin_trgm_ops);
 
 CREATE TABLE users (
-    id           BIGSERIAL   NOT NULL PRIMARY KEY,
-    username     TEXT        NOT NULL,
-    display_name TEXT,
-    bio          TEXT,
-    avatar_url   TEXT,
-    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
-    updated_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+    id             BIGSERIAL   NOT NULL PRIMARY KEY,
+    username       TEXT        NOT NULL,
+    display_name   TEXT,
+    bio            TEXT,
+    avatar_url     TEXT,
+    log_visibility TEXT        NOT NULL DEFAULT 'public',
+    hide_notes     BOOLEAN     NOT NULL DEFAULT false,
+    role           TEXT        NOT NULL DEFAULT 'user',
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT users_log_visibility_valid CHECK (log_visibility IN ('public', 'friends', 'private')),
+    CONSTRAINT users_role_valid CHECK (role IN ('user', 'moderator', 'admin'))
 );
 
 CREATE UNIQUE INDEX users_username_lower_unique ON users (lower(username));
@@ -30,14 +35,264 @@ CREATE TABLE movie_log (
     watched_on    DATE        NOT NULL DEFAULT CURRENT_DATE,
     note          TEXT,
     rank_position INTEGER,
+    sentiment     TEXT,
+    rating        NUMERIC(2, 1),
+    version       BIGINT      NOT NULL DEFAULT 1,
+    visibility    TEXT,
     created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
     updated_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
     CONSTRAINT movie_log_user_movie_unique UNIQUE (user_id, movie_id),
-    CONSTRAINT movie_log_rank_positive CHECK (rank_position IS NULL OR rank_position > 0)
+    CONSTRAINT movie_log_rank_positive CHECK (rank_position IS NULL OR rank_position > 0),
+    CONSTRAINT movie_log_sentiment_valid CHECK (sentiment IS NULL OR sentiment IN ('liked', 'fine', 'disliked')),
+    CONSTRAINT movie_log_ranked_sentiment CHECK (rank_position IS NULL OR sentiment IS NOT NULL),
+    CONSTRAINT movie_log_rating_half_stars CHECK (rating IS NULL OR (rating BETWEEN 0.5 AND 5.0 AND rating * 2 = trunc(rating * 2))),
+    CONSTRAINT movie_log_visibility_valid CHECK (visibility IS NULL OR visibility IN ('public', 'friends', 'private'))
 );
 
 CREATE UNIQUE INDEX movie_log_user_rank_unique
     ON movie_log (user_id, rank_position)
     WHERE rank_position IS NOT NULL;
-CREATE INDEX idx_movie_log_user_watched_on ON movie_log (user_id, watched_on DESC);
-CREATE INDEX idx_movie_log_user_created_at ON movie_log (user_id, created_at DESC);
+CREATE INDEX idx_movie_log_user_watched_on ON movie_log (user_id, watched_on DESC, id DESC);
+CREATE INDEX idx_movie_log_user_created_at ON movie_log (user_id, created_at DESC, id DESC);
+CREATE INDEX idx_movie_log_user_rating ON movie_log (user_id, rating) WHERE rating IS NOT NULL;
+
+CREATE TABLE rank_sessions (
+    id                BIGSERIAL   NOT NULL PRIMARY KEY,
+    user_id           BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    log_id            BIGINT      NOT NULL REFERENCES movie_log (id) ON DELETE CASCADE,
+    low_position      INTEGER     NOT NULL,
+    high_position     INTEGER     NOT NULL,
+    comparison_log_id BIGINT      REFERENCES movie_log (id) ON DELETE SET NULL,
+    sentiment         TEXT        NOT NULL,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT rank_sessions_log_unique UNIQUE (log_id),
+    CONSTRAINT rank_sessions_low_positive CHECK (low_position > 0),
+    CONSTRAINT rank_sessions_sentiment_valid CHECK (sentiment IN ('liked', 'fine', 'disliked'))
+);
+
+CREATE INDEX idx_rank_sessions_user_id ON rank_sessions (user_id);
+
+CREATE TABLE movie_log_viewings (
+    id         BIGSERIAL   NOT NULL PRIMARY KEY,
+    log_id     BIGINT      NOT NULL REFERENCES movie_log (id) ON DELETE CASCADE,
+    watched_on DATE        NOT NULL DEFAULT CURRENT_DATE,
+    note       TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_movie_log_viewings_log_watched_on ON movie_log_viewings (log_id, watched_on DESC);
+
+CREATE TABLE tags (
+    id         BIGSERIAL   NOT NULL PRIMARY KEY,
+    user_id    BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name       TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT tags_name_not_blank CHECK (name <> '')
+);
+
+CREATE UNIQUE INDEX tags_user_name_unique ON tags (user_id, name);
+
+CREATE TABLE movie_log_tags (
+    log_id     BIGINT      NOT NULL REFERENCES movie_log (id) ON DELETE CASCADE,
+    tag_id     BIGINT      NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (log_id, tag_id)
+);
+
+CREATE INDEX idx_movie_log_tags_tag_id ON movie_log_tags (tag_id);
+
+CREATE TABLE log_imports (
+    id              BIGSERIAL   NOT NULL PRIMARY KEY,
+    user_id         BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    source          TEXT        NOT NULL,
+    film_count      INTEGER     NOT NULL DEFAULT 0,
+    imported_count  INTEGER     NOT NULL DEFAULT 0,
+    queued_count    INTEGER     NOT NULL DEFAULT 0,
+    existing_count  INTEGER     NOT NULL DEFAULT 0,
+    status          TEXT        NOT NULL DEFAULT 'running',
+    processed_count INTEGER     NOT NULL DEFAULT 0,
+    ranked_count    INTEGER     NOT NULL DEFAULT 0,
+    error           TEXT,
+    finished_at     TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT log_imports_status_valid CHECK (status IN ('running', 'succeeded', 'failed'))
+);
+
+CREATE INDEX idx_log_imports_user_id ON log_imports (user_id, created_at DESC);
+
+CREATE TABLE log_import_reviews (
+    id                  BIGSERIAL     NOT NULL PRIMARY KEY,
+    import_id           BIGINT        NOT NULL REFERENCES log_imports (id) ON DELETE CASCADE,
+    user_id             BIGINT        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    title               TEXT          NOT NULL,
+    release_year        INTEGER,
+    watched_dates       DATE[]        NOT NULL,
+    rating              NUMERIC(2, 1),
+    review              TEXT,
+    candidate_movie_ids INTEGER[]     NOT NULL DEFAULT '{}',
+    status              TEXT          NOT NULL DEFAULT 'pending',
+    movie_id            INTEGER       REFERENCES movie_ids (id) ON DELETE SET NULL,
+    log_id              BIGINT        REFERENCES movie_log (id) ON DELETE SET NULL,
+    created_at          TIMESTAMPTZ   NOT NULL DEFAULT now(),
+    updated_at          TIMESTAMPTZ   NOT NULL DEFAULT now(),
+    CONSTRAINT log_import_reviews_status_valid CHECK (status IN ('pending', 'confirmed', 'dismissed'))
+);
+
+CREATE INDEX idx_log_import_reviews_user_status ON log_import_reviews (user_id, status, id);
+
+CREATE TABLE imdb_movie_ids (
+    imdb_id  TEXT    NOT NULL PRIMARY KEY,
+    movie_id INTEGER NOT NULL REFERENCES movie_ids (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_imdb_movie_ids_movie_id ON imdb_movie_ids (movie_id);
+
+CREATE TABLE friendships (
+    follower_id BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    followee_id BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    status      TEXT        NOT NULL DEFAULT 'pending',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (follower_id, followee_id),
+    CONSTRAINT friendships_not_self CHECK (follower_id <> followee_id),
+    CONSTRAINT friendships_status_valid CHECK (status IN ('pending', 'accepted'))
+);
+
+CREATE INDEX idx_friendships_followee_status ON friendships (followee_id, status);
+
+CREATE TABLE activity_events (
+    id            BIGSERIAL     NOT NULL PRIMARY KEY,
+    user_id       BIGINT        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    log_id        BIGINT        NOT NULL REFERENCES movie_log (id) ON DELETE CASCADE,
+    kind          TEXT          NOT NULL,
+    note          TEXT,
+    rating        NUMERIC(2, 1),
+    sentiment     TEXT,
+    rank_position INTEGER,
+    watched_on    DATE,
+    created_at    TIMESTAMPTZ   NOT NULL DEFAULT now(),
+    CONSTRAINT activity_events_kind_valid CHECK (kind IN ('logged', 'noted', 'ranked', 'rewatched'))
+);
+
+CREATE INDEX idx_activity_events_user_created ON activity_events (user_id, created_at DESC, id DESC);
+CREATE INDEX idx_activity_events_log_id ON activity_events (log_id);
+
+CREATE TABLE log_comments (
+    id         BIGSERIAL   NOT NULL PRIMARY KEY,
+    log_id     BIGINT      NOT NULL REFERENCES movie_log (id) ON DELETE CASCADE,
+    user_id    BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    parent_id  BIGINT      REFERENCES log_comments (id) ON DELETE CASCADE,
+    body       TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT log_comments_body_not_empty CHECK (body <> '')
+);
+
+CREATE INDEX idx_log_comments_log_id ON log_comments (log_id, created_at, id);
+
+CREATE TABLE log_likes (
+    log_id     BIGINT      NOT NULL REFERENCES movie_log (id) ON DELETE CASCADE,
+    user_id    BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (log_id, user_id)
+);
+
+CREATE INDEX idx_log_likes_user_id ON log_likes (user_id);
+
+CREATE TABLE notifications (
+    id         BIGSERIAL   NOT NULL PRIMARY KEY,
+    user_id    BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    actor_id   BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    kind       TEXT        NOT NULL,
+    log_id     BIGINT      REFERENCES movie_log (id) ON DELETE CASCADE,
+    comment_id BIGINT      REFERENCES log_comments (id) ON DELETE CASCADE,
+    read_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT notifications_kind_valid CHECK (kind IN (
+        'follow_request', 'follow_accepted', 'comment', 'reply', 'like'
+    ))
+);
+
+CREATE INDEX idx_notifications_user_created ON notifications (user_id, created_at DESC, id DESC);
+CREATE INDEX idx_notifications_user_unread ON notifications (user_id) WHERE read_at IS NULL;
+
+CREATE TABLE notification_preferences (
+    user_id    BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    kind       TEXT        NOT NULL,
+    enabled    BOOLEAN     NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, kind)
+);
+
+CREATE TABLE user_credentials (
+    user_id       BIGINT      NOT NULL PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    password_hash TEXT        NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE user_sessions (
+    token_hash TEXT        NOT NULL PRIMARY KEY,
+    user_id    BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    user_agent TEXT,
+    ip         TEXT,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_user_sessions_user_id ON user_sessions (user_id);
+
+CREATE TABLE api_tokens (
+    id           BIGSERIAL   NOT NULL PRIMARY KEY,
+    user_id      BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name         TEXT        NOT NULL,
+    token_hash   TEXT        NOT NULL,
+    scopes       TEXT[]      NOT NULL,
+    expires_at   TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT api_tokens_token_hash_unique UNIQUE (token_hash)
+);
+
+CREATE INDEX idx_api_tokens_user_id ON api_tokens (user_id);
+
+CREATE TABLE user_identities (
+    issuer         TEXT        NOT NULL,
+    subject        TEXT        NOT NULL,
+    user_id        BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    email          TEXT,
+    email_verified BOOLEAN     NOT NULL DEFAULT false,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (issuer, subject),
+    CONSTRAINT user_identities_user_issuer_unique UNIQUE (user_id, issuer)
+);
+
+CREATE INDEX idx_user_identities_verified_email ON user_identities (lower(email)) WHERE email_verified;
+
+CREATE TABLE rate_limit_buckets (
+    key        TEXT             NOT NULL PRIMARY KEY,
+    tokens     DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMPTZ      NOT NULL
+);
+
+CREATE INDEX idx_rate_limit_buckets_updated_at ON rate_limit_buckets (updated_at);
+
+CREATE TABLE audit_events (
+    id             BIGSERIAL   NOT NULL PRIMARY KEY,
+    actor_id       BIGINT,
+    actor_username TEXT,
+    action         TEXT        NOT NULL,
+    target_type    TEXT        NOT NULL,
+    target_id      BIGINT,
+    request_id     TEXT,
+    ip             TEXT,
+    before         JSONB,
+    after          JSONB,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_audit_events_created ON audit_events (created_at DESC, id DESC);
+CREATE INDEX idx_audit_events_actor ON audit_events (actor_id, created_at DESC);
+CREATE INDEX idx_audit_events_target ON audit_events (target_type, target_id, created_at DESC);
@@ -8,6 +8,106 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type ActivityEvent struct {
+	ID           int64              `db:"id" json:"id"`
+	UserID       int64              `db:"user_id" json:"user_id"`
+	LogID        int64              `db:"log_id" json:"log_id"`
+	Kind         string             `db:"kind" json:"kind"`
+	Note         pgtype.Text        `db:"note" json:"note"`
+	Rating       pgtype.Numeric     `db:"rating" json:"rating"`
+	Sentiment    pgtype.Text        `db:"sentiment" json:"sentiment"`
+	RankPosition pgtype.Int4        `db:"rank_position" json:"rank_position"`
+	WatchedOn    pgtype.Date        `db:"watched_on" json:"watched_on"`
+	CreatedAt    pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type ApiToken struct {
+	ID         int64              `db:"id" json:"id"`
+	UserID     int64              `db:"user_id" json:"user_id"`
+	Name       string             `db:"name" json:"name"`
+	TokenHash  string             `db:"token_hash" json:"token_hash"`
+	Scopes     []string           `db:"scopes" json:"scopes"`
+	ExpiresAt  pgtype.Timestamptz `db:"expires_at" json:"expires_at"`
+	LastUsedAt pgtype.Timestamptz `db:"last_used_at" json:"last_used_at"`
+	CreatedAt  pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type AuditEvent struct {
+	ID            int64              `db:"id" json:"id"`
+	ActorID       pgtype.Int8        `db:"actor_id" json:"actor_id"`
+	ActorUsername pgtype.Text        `db:"actor_username" json:"actor_username"`
+	Action        string             `db:"action" json:"action"`
+	TargetType    string             `db:"target_type" json:"target_type"`
+	TargetID      pgtype.Int8        `db:"target_id" json:"target_id"`
+	RequestID     pgtype.Text        `db:"request_id" json:"request_id"`
+	Ip            pgtype.Text        `db:"ip" json:"ip"`
+	Before        []byte             `db:"before" json:"before"`
+	After         []byte             `db:"after" json:"after"`
+	CreatedAt     pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type Friendship struct {
+	FollowerID int64              `db:"follower_id" json:"follower_id"`
+	FolloweeID int64              `db:"followee_id" json:"followee_id"`
+	Status     string             `db:"status" json:"status"`
+	CreatedAt  pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt  pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type ImdbMovieID struct {
+	ImdbID  string `db:"imdb_id" json:"imdb_id"`
+	MovieID int32  `db:"movie_id" json:"movie_id"`
+}
+
+type LogComment struct {
+	ID        int64              `db:"id" json:"id"`
+	LogID     int64              `db:"log_id" json:"log_id"`
+	UserID    int64              `db:"user_id" json:"user_id"`
+	ParentID  pgtype.Int8        `db:"parent_id" json:"parent_id"`
+	Body      string             `db:"body" json:"body"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type LogImport struct {
+	ID             int64              `db:"id" json:"id"`
+	UserID         int64              `db:"user_id" json:"user_id"`
+	Source         string             `db:"source" json:"source"`
+	FilmCount      int32              `db:"film_count" json:"film_count"`
+	ImportedCount  int32              `db:"imported_count" json:"imported_count"`
+	QueuedCount    int32              `db:"queued_count" json:"queued_count"`
+	ExistingCount  int32              `db:"existing_count" json:"existing_count"`
+	Status         string             `db:"status" json:"status"`
+	ProcessedCount int32              `db:"processed_count" json:"processed_count"`
+	RankedCount    int32              `db:"ranked_count" json:"ranked_count"`
+	Error          pgtype.Text        `db:"error" json:"error"`
+	FinishedAt     pgtype.Timestamptz `db:"finished_at" json:"finished_at"`
+	CreatedAt      pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type LogImportReview struct {
+	ID                int64              `db:"id" json:"id"`
+	ImportID          int64              `db:"import_id" json:"import_id"`
+	UserID            int64              `db:"user_id" json:"user_id"`
+	Title             string             `db:"title" json:"title"`
+	ReleaseYear       pgtype.Int4        `db:"release_year" json:"release_year"`
+	WatchedDates      []pgtype.Date      `db:"watched_dates" json:"watched_dates"`
+	Rating            pgtype.Numeric     `db:"rating" json:"rating"`
+	Review            pgtype.Text        `db:"review" json:"review"`
+	CandidateMovieIds []int32            `db:"candidate_movie_ids" json:"candidate_movie_ids"`
+	Status            string             `db:"status" json:"status"`
+	MovieID           pgtype.Int4        `db:"movie_id" json:"movie_id"`
+	LogID             pgtype.Int8        `db:"log_id" json:"log_id"`
+	CreatedAt         pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt         pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type LogLike struct {
+	LogID     int64              `db:"log_id" json:"log_id"`
+	UserID    int64              `db:"user_id" json:"user_id"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
 type MovieID struct {
 	ID            int32          `db:"id" json:"id"`
 	OriginalTitle string         `db:"original_title" json:"original_title"`
@@ -23,16 +123,106 @@ type MovieLog struct {
 	WatchedOn    pgtype.Date        `db:"watched_on" json:"watched_on"`
 	Note         pgtype.Text        `db:"note" json:"note"`
 	RankPosition pgtype.Int4        `db:"rank_position" json:"rank_position"`
+	Sentiment    pgtype.Text        `db:"sentiment" json:"sentiment"`
+	Rating       pgtype.Numeric     `db:"rating" json:"rating"`
+	Version      int64              `db:"version" json:"version"`
+	Visibility   pgtype.Text        `db:"visibility" json:"visibility"`
 	CreatedAt    pgtype.Timestamptz `db:"created_at" json:"created_at"`
 	UpdatedAt    pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
 }
 
+type MovieLogTag struct {
+	LogID     int64              `db:"log_id" json:"log_id"`
+	TagID     int64              `db:"tag_id" json:"tag_id"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type MovieLogViewing struct {
+	ID        int64              `db:"id" json:"id"`
+	LogID     int64              `db:"log_id" json:"log_id"`
+	WatchedOn pgtype.Date        `db:"watched_on" json:"watched_on"`
+	Note      pgtype.Text        `db:"note" json:"note"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type Notification struct {
+	ID        int64              `db:"id" json:"id"`
+	UserID    int64              `db:"user_id" json:"user_id"`
+	ActorID   int64              `db:"actor_id" json:"actor_id"`
+	Kind      string             `db:"kind" json:"kind"`
+	LogID     pgtype.Int8        `db:"log_id" json:"log_id"`
+	CommentID pgtype.Int8        `db:"comment_id" json:"comment_id"`
+	ReadAt    pgtype.Timestamptz `db:"read_at" json:"read_at"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type NotificationPreference struct {
+	UserID    int64              `db:"user_id" json:"user_id"`
+	Kind      string             `db:"kind" json:"kind"`
+	Enabled   bool               `db:"enabled" json:"enabled"`
+	UpdatedAt pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type RankSession struct {
+	ID              int64              `db:"id" json:"id"`
+	UserID          int64              `db:"user_id" json:"user_id"`
+	LogID           int64              `db:"log_id" json:"log_id"`
+	LowPosition     int32              `db:"low_position" json:"low_position"`
+	HighPosition    int32              `db:"high_position" json:"high_position"`
+	ComparisonLogID pgtype.Int8        `db:"comparison_log_id" json:"comparison_log_id"`
+	Sentiment       string             `db:"sentiment" json:"sentiment"`
+	CreatedAt       pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type RateLimitBucket struct {
+	Key       string             `db:"key" json:"key"`
+	Tokens    float64            `db:"tokens" json:"tokens"`
+	UpdatedAt pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type Tag struct {
+	ID        int64              `db:"id" json:"id"`
+	UserID    int64              `db:"user_id" json:"user_id"`
+	Name      string             `db:"name" json:"name"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
 type User struct {
-	ID          int64              `db:"id" json:"id"`
-	Username    string             `db:"username" json:"username"`
-	DisplayName pgtype.Text        `db:"display_name" json:"display_name"`
-	Bio         pgtype.Text        `db:"bio" json:"bio"`
-	AvatarUrl   pgtype.Text        `db:"avatar_url" json:"avatar_url"`
-	CreatedAt   pgtype.Timestamptz `db:"created_at" json:"created_at"`
-	UpdatedAt   pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	ID            int64              `db:"id" json:"id"`
+	Username      string             `db:"username" json:"username"`
+	DisplayName   pgtype.Text        `db:"display_name" json:"display_name"`
+	Bio           pgtype.Text        `db:"bio" json:"bio"`
+	AvatarUrl     pgtype.Text        `db:"avatar_url" json:"avatar_url"`
+	LogVisibility string             `db:"log_visibility" json:"log_visibility"`
+	HideNotes     bool               `db:"hide_notes" json:"hide_notes"`
+	Role          string             `db:"role" json:"role"`
+	CreatedAt     pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type UserCredential struct {
+	UserID       int64              `db:"user_id" json:"user_id"`
+	PasswordHash string             `db:"password_hash" json:"password_hash"`
+	CreatedAt    pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type UserIdentity struct {
+	Issuer        string             `db:"issuer" json:"issuer"`
+	Subject       string             `db:"subject" json:"subject"`
+	UserID        int64              `db:"user_id" json:"user_id"`
+	Email         pgtype.Text        `db:"email" json:"email"`
+	EmailVerified bool               `db:"email_verified" json:"email_verified"`
+	CreatedAt     pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type UserSession struct {
+	TokenHash string             `db:"token_hash" json:"token_hash"`
+	UserID    int64              `db:"user_id" json:"user_id"`
+	UserAgent pgtype.Text        `db:"user_agent" json:"user_agent"`
+	Ip        pgtype.Text        `db:"ip" json:"ip"`
+	ExpiresAt pgtype.Timestamptz `db:"expires_at" json:"expires_at"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
 }
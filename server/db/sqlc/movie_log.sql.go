@@ -11,9 +11,137 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const deleteMovieLogEntry = `-- name: DeleteMovieLogEntry :execrows
+const applyRankOrder = `-- name: ApplyRankOrder :execrows
+UPDATE movie_log ml
+SET rank_position = o.position::int,
+    version = ml.version + 1,
+    updated_at = now()
+FROM unnest($1::bigint[]) WITH ORDINALITY AS o (log_id, position)
+WHERE ml.id = o.log_id AND ml.user_id = $2
+`
+
+type ApplyRankOrderParams struct {
+	LogIds []int64 `db:"log_ids" json:"log_ids"`
+	UserID int64   `db:"user_id" json:"user_id"`
+}
+
+func (q *Queries) ApplyRankOrder(ctx context.Context, arg ApplyRankOrderParams) (int64, error) {
+	result, err := q.db.Exec(ctx, applyRankOrder, arg.LogIds, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const clearRankPositions = `-- name: ClearRankPositions :exec
+UPDATE movie_log
+SET rank_position = NULL,
+    version = version + 1,
+    updated_at = now()
+WHERE user_id = $1 AND rank_position IS NOT NULL
+`
+
+func (q *Queries) ClearRankPositions(ctx context.Context, userID int64) error {
+	_, err := q.db.Exec(ctx, clearRankPositions, userID)
+	return err
+}
+
+const countRankedMovieLog = `-- name: CountRankedMovieLog :one
+SELECT COUNT(*)
+FROM movie_log
+WHERE user_id = $1 AND rank_position IS NOT NULL
+`
+
+func (q *Queries) CountRankedMovieLog(ctx context.Context, userID int64) (int64, error) {
+	row := q.db.QueryRow(ctx, countRankedMovieLog, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countRankedMovieLogBySentiment = `-- name: CountRankedMovieLogBySentiment :many
+SELECT sentiment, COUNT(*) AS ranked_count
+FROM movie_log
+WHERE user_id = $1 AND rank_position IS NOT NULL
+GROUP BY sentiment
+`
+
+type CountRankedMovieLogBySentimentRow struct {
+	Sentiment   pgtype.Text `db:"sentiment" json:"sentiment"`
+	RankedCount int64       `db:"ranked_count" json:"ranked_count"`
+}
+
+func (q *Queries) CountRankedMovieLogBySentiment(ctx context.Context, userID int64) ([]CountRankedMovieLogBySentimentRow, error) {
+	rows, err := q.db.Query(ctx, countRankedMovieLogBySentiment, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountRankedMovieLogBySentimentRow
+	for rows.Next() {
+		var i CountRankedMovieLogBySentimentRow
+		if err := rows.Scan(
+			&i.Sentiment,
+			&i.RankedCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createMovieLogEntry = `-- name: CreateMovieLogEntry :one
+INSERT INTO movie_log (user_id, movie_id, watched_on, note, rating, visibility)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (user_id, movie_id) DO NOTHING
+RETURNING id, user_id, movie_id, watched_on, note, rank_position, sentiment, rating, version, visibility, created_at, updated_at
+`
+
+type CreateMovieLogEntryParams struct {
+	UserID     int64          `db:"user_id" json:"user_id"`
+	MovieID    int32          `db:"movie_id" json:"movie_id"`
+	WatchedOn  pgtype.Date    `db:"watched_on" json:"watched_on"`
+	Note       pgtype.Text    `db:"note" json:"note"`
+	Rating     pgtype.Numeric `db:"rating" json:"rating"`
+	Visibility pgtype.Text    `db:"visibility" json:"visibility"`
+}
+
+func (q *Queries) CreateMovieLogEntry(ctx context.Context, arg CreateMovieLogEntryParams) (MovieLog, error) {
+	row := q.db.QueryRow(ctx, createMovieLogEntry,
+		arg.UserID,
+		arg.MovieID,
+		arg.WatchedOn,
+		arg.Note,
+		arg.Rating,
+		arg.Visibility,
+	)
+	var i MovieLog
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.MovieID,
+		&i.WatchedOn,
+		&i.Note,
+		&i.RankPosition,
+		&i.Sentiment,
+		&i.Rating,
+		&i.Version,
+		&i.Visibility,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteMovieLogEntry = `-- name: DeleteMovieLogEntry :one
 DELETE FROM movie_log
 WHERE id = $1 AND user_id = $2
+RETURNING id, user_id, movie_id, watched_on, note, rank_position, sentiment, rating,
+          version, visibility, created_at, updated_at
 `
 
 type DeleteMovieLogEntryParams struct {
@@ -21,24 +149,280 @@ type DeleteMovieLogEntryParams struct {
 	UserID int64 `db:"user_id" json:"user_id"`
 }
 
-func (q *Queries) DeleteMovieLogEntry(ctx context.Context, arg DeleteMovieLogEntryParams) (int64, error) {
-	result, err := q.db.Exec(ctx, deleteMovieLogEntry, arg.ID, arg.UserID)
+func (q *Queries) DeleteMovieLogEntry(ctx context.Context, arg DeleteMovieLogEntryParams) (MovieLog, error) {
+	row := q.db.QueryRow(ctx, deleteMovieLogEntry, arg.ID, arg.UserID)
+	var i MovieLog
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.MovieID,
+		&i.WatchedOn,
+		&i.Note,
+		&i.RankPosition,
+		&i.Sentiment,
+		&i.Rating,
+		&i.Version,
+		&i.Visibility,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const exportMovieLogPage = `-- name: ExportMovieLogPage :many
+SELECT ml.id AS log_id, ml.movie_id, mi.original_title, ml.watched_on,
+       CASE WHEN $1::boolean THEN ml.note END AS note,
+       ml.rank_position, ml.sentiment, ml.rating, ml.visibility, ml.created_at, ml.updated_at,
+       (SELECT COUNT(*) FROM movie_log_viewings mlv WHERE mlv.log_id = ml.id) AS viewing_count,
+       ARRAY(
+           SELECT t.name
+           FROM movie_log_tags mlt
+           JOIN tags t ON t.id = mlt.tag_id
+           WHERE mlt.log_id = ml.id
+           ORDER BY t.name
+       )::text[] AS tags
+FROM movie_log ml
+JOIN movie_ids mi ON mi.id = ml.movie_id
+JOIN users u ON u.id = ml.user_id
+WHERE ml.user_id = $2
+  AND ml.id > $3::bigint
+  AND COALESCE(ml.visibility, u.log_visibility) = ANY($4::text[])
+ORDER BY ml.id ASC
+LIMIT $5::integer
+`
+
+type ExportMovieLogPageParams struct {
+	ShowNotes    bool     `db:"show_notes" json:"show_notes"`
+	UserID       int64    `db:"user_id" json:"user_id"`
+	AfterID      int64    `db:"after_id" json:"after_id"`
+	Visibilities []string `db:"visibilities" json:"visibilities"`
+	PageSize     int32    `db:"page_size" json:"page_size"`
+}
+
+type ExportMovieLogPageRow struct {
+	LogID         int64              `db:"log_id" json:"log_id"`
+	MovieID       int32              `db:"movie_id" json:"movie_id"`
+	OriginalTitle string             `db:"original_title" json:"original_title"`
+	WatchedOn     pgtype.Date        `db:"watched_on" json:"watched_on"`
+	Note          pgtype.Text        `db:"note" json:"note"`
+	RankPosition  pgtype.Int4        `db:"rank_position" json:"rank_position"`
+	Sentiment     pgtype.Text        `db:"sentiment" json:"sentiment"`
+	Rating        pgtype.Numeric     `db:"rating" json:"rating"`
+	Visibility    pgtype.Text        `db:"visibility" json:"visibility"`
+	CreatedAt     pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	ViewingCount  int64              `db:"viewing_count" json:"viewing_count"`
+	Tags          []string           `db:"tags" json:"tags"`
+}
+
+func (q *Queries) ExportMovieLogPage(ctx context.Context, arg ExportMovieLogPageParams) ([]ExportMovieLogPageRow, error) {
+	rows, err := q.db.Query(ctx, exportMovieLogPage,
+		arg.ShowNotes,
+		arg.UserID,
+		arg.AfterID,
+		arg.Visibilities,
+		arg.PageSize,
+	)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	return result.RowsAffected(), nil
+	defer rows.Close()
+	var items []ExportMovieLogPageRow
+	for rows.Next() {
+		var i ExportMovieLogPageRow
+		if err := rows.Scan(
+			&i.LogID,
+			&i.MovieID,
+			&i.OriginalTitle,
+			&i.WatchedOn,
+			&i.Note,
+			&i.RankPosition,
+			&i.Sentiment,
+			&i.Rating,
+			&i.Visibility,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ViewingCount,
+			&i.Tags,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const listMovieLogByUser = `-- name: ListMovieLogByUser :many
-SELECT ml.id AS log_id, ml.user_id, ml.movie_id, mi.original_title, ml.watched_on,
-       ml.note, ml.rank_position, ml.created_at, ml.updated_at
+const getMovieLogEntryAtRank = `-- name: GetMovieLogEntryAtRank :one
+SELECT ml.id AS log_id, ml.movie_id, mi.original_title, ml.rank_position, ml.sentiment
+FROM movie_log ml
+JOIN movie_ids mi ON mi.id = ml.movie_id
+WHERE ml.user_id = $1 AND ml.rank_position = $2::int
+`
+
+type GetMovieLogEntryAtRankParams struct {
+	UserID       int64 `db:"user_id" json:"user_id"`
+	RankPosition int32 `db:"rank_position" json:"rank_position"`
+}
+
+type GetMovieLogEntryAtRankRow struct {
+	LogID         int64       `db:"log_id" json:"log_id"`
+	MovieID       int32       `db:"movie_id" json:"movie_id"`
+	OriginalTitle string      `db:"original_title" json:"original_title"`
+	RankPosition  pgtype.Int4 `db:"rank_position" json:"rank_position"`
+	Sentiment     pgtype.Text `db:"sentiment" json:"sentiment"`
+}
+
+func (q *Queries) GetMovieLogEntryAtRank(ctx context.Context, arg GetMovieLogEntryAtRankParams) (GetMovieLogEntryAtRankRow, error) {
+	row := q.db.QueryRow(ctx, getMovieLogEntryAtRank, arg.UserID, arg.RankPosition)
+	var i GetMovieLogEntryAtRankRow
+	err := row.Scan(
+		&i.LogID,
+		&i.MovieID,
+		&i.OriginalTitle,
+		&i.RankPosition,
+		&i.Sentiment,
+	)
+	return i, err
+}
+
+const getMovieLogRankEntry = `-- name: GetMovieLogRankEntry :one
+SELECT ml.id AS log_id, ml.movie_id, mi.original_title, ml.rank_position, ml.sentiment
 FROM movie_log ml
 JOIN movie_ids mi ON mi.id = ml.movie_id
+WHERE ml.id = $1 AND ml.user_id = $2
+`
+
+type GetMovieLogRankEntryParams struct {
+	ID     int64 `db:"id" json:"id"`
+	UserID int64 `db:"user_id" json:"user_id"`
+}
+
+type GetMovieLogRankEntryRow struct {
+	LogID         int64       `db:"log_id" json:"log_id"`
+	MovieID       int32       `db:"movie_id" json:"movie_id"`
+	OriginalTitle string      `db:"original_title" json:"original_title"`
+	RankPosition  pgtype.Int4 `db:"rank_position" json:"rank_position"`
+	Sentiment     pgtype.Text `db:"sentiment" json:"sentiment"`
+}
+
+func (q *Queries) GetMovieLogRankEntry(ctx context.Context, arg GetMovieLogRankEntryParams) (GetMovieLogRankEntryRow, error) {
+	row := q.db.QueryRow(ctx, getMovieLogRankEntry, arg.ID, arg.UserID)
+	var i GetMovieLogRankEntryRow
+	err := row.Scan(
+		&i.LogID,
+		&i.MovieID,
+		&i.OriginalTitle,
+		&i.RankPosition,
+		&i.Sentiment,
+	)
+	return i, err
+}
+
+const getMovieLogVersion = `-- name: GetMovieLogVersion :one
+SELECT version
+FROM movie_log
+WHERE id = $1 AND user_id = $2
+`
+
+type GetMovieLogVersionParams struct {
+	ID     int64 `db:"id" json:"id"`
+	UserID int64 `db:"user_id" json:"user_id"`
+}
+
+func (q *Queries) GetMovieLogVersion(ctx context.Context, arg GetMovieLogVersionParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getMovieLogVersion, arg.ID, arg.UserID)
+	var version int64
+	err := row.Scan(&version)
+	return version, err
+}
+
+const getMovieLogVisibility = `-- name: GetMovieLogVisibility :one
+SELECT visibility
+FROM movie_log
+WHERE id = $1 AND user_id = $2
+`
+
+type GetMovieLogVisibilityParams struct {
+	ID     int64 `db:"id" json:"id"`
+	UserID int64 `db:"user_id" json:"user_id"`
+}
+
+func (q *Queries) GetMovieLogVisibility(ctx context.Context, arg GetMovieLogVisibilityParams) (pgtype.Text, error) {
+	row := q.db.QueryRow(ctx, getMovieLogVisibility, arg.ID, arg.UserID)
+	var visibility pgtype.Text
+	err := row.Scan(&visibility)
+	return visibility, err
+}
+
+const listLoggedMovieIDsByUser = `-- name: ListLoggedMovieIDsByUser :many
+SELECT ml.movie_id
+FROM movie_log ml
+JOIN users u ON u.id = ml.user_id
 WHERE ml.user_id = $1
-ORDER BY (ml.rank_position IS NULL), ml.rank_position ASC NULLS LAST, ml.created_at DESC
+  AND COALESCE(ml.visibility, u.log_visibility) = ANY($2::text[])
+`
+
+type ListLoggedMovieIDsByUserParams struct {
+	UserID       int64    `db:"user_id" json:"user_id"`
+	Visibilities []string `db:"visibilities" json:"visibilities"`
+}
+
+func (q *Queries) ListLoggedMovieIDsByUser(ctx context.Context, arg ListLoggedMovieIDsByUserParams) ([]int32, error) {
+	rows, err := q.db.Query(ctx, listLoggedMovieIDsByUser, arg.UserID, arg.Visibilities)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var movieID int32
+		if err := rows.Scan(&movieID); err != nil {
+			return nil, err
+		}
+		items = append(items, movieID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMovieLogByIDs = `-- name: ListMovieLogByIDs :many
+SELECT ml.id AS log_id, ml.user_id, ml.movie_id, mi.original_title, ml.watched_on,
+       CASE WHEN $1::boolean THEN ml.note END AS note,
+       ml.rank_position, ml.sentiment, ml.rating, ml.version, ml.visibility, ml.created_at, ml.updated_at,
+       (SELECT COUNT(*) FROM movie_log_viewings mlv WHERE mlv.log_id = ml.id) AS viewing_count,
+       ARRAY(
+           SELECT t.name
+           FROM movie_log_tags mlt
+           JOIN tags t ON t.id = mlt.tag_id
+           WHERE mlt.log_id = ml.id
+           ORDER BY t.name
+       )::text[] AS tags,
+       (SELECT COUNT(*) FROM log_likes ll WHERE ll.log_id = ml.id) AS like_count,
+       (SELECT COUNT(*) FROM log_comments lc WHERE lc.log_id = ml.id) AS comment_count,
+       EXISTS (
+           SELECT 1
+           FROM log_likes ll
+           WHERE ll.log_id = ml.id AND ll.user_id = $2::bigint
+       ) AS liked_by_me
+FROM movie_log ml
+JOIN movie_ids mi ON mi.id = ml.movie_id
+WHERE ml.user_id = $3
+  AND ml.id = ANY($4::bigint[])
 `
 
-type ListMovieLogByUserRow struct {
+type ListMovieLogByIDsParams struct {
+	ShowNotes bool        `db:"show_notes" json:"show_notes"`
+	ViewerID  pgtype.Int8 `db:"viewer_id" json:"viewer_id"`
+	UserID    int64       `db:"user_id" json:"user_id"`
+	LogIds    []int64     `db:"log_ids" json:"log_ids"`
+}
+
+type ListMovieLogByIDsRow struct {
 	LogID         int64              `db:"log_id" json:"log_id"`
 	UserID        int64              `db:"user_id" json:"user_id"`
 	MovieID       int32              `db:"movie_id" json:"movie_id"`
@@ -46,19 +430,33 @@ type ListMovieLogByUserRow struct {
 	WatchedOn     pgtype.Date        `db:"watched_on" json:"watched_on"`
 	Note          pgtype.Text        `db:"note" json:"note"`
 	RankPosition  pgtype.Int4        `db:"rank_position" json:"rank_position"`
+	Sentiment     pgtype.Text        `db:"sentiment" json:"sentiment"`
+	Rating        pgtype.Numeric     `db:"rating" json:"rating"`
+	Version       int64              `db:"version" json:"version"`
+	Visibility    pgtype.Text        `db:"visibility" json:"visibility"`
 	CreatedAt     pgtype.Timestamptz `db:"created_at" json:"created_at"`
 	UpdatedAt     pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	ViewingCount  int64              `db:"viewing_count" json:"viewing_count"`
+	Tags          []string           `db:"tags" json:"tags"`
+	LikeCount     int64              `db:"like_count" json:"like_count"`
+	CommentCount  int64              `db:"comment_count" json:"comment_count"`
+	LikedByMe     bool               `db:"liked_by_me" json:"liked_by_me"`
 }
 
-func (q *Queries) ListMovieLogByUser(ctx context.Context, userID int64) ([]ListMovieLogByUserRow, error) {
-	rows, err := q.db.Query(ctx, listMovieLogByUser, userID)
+func (q *Queries) ListMovieLogByIDs(ctx context.Context, arg ListMovieLogByIDsParams) ([]ListMovieLogByIDsRow, error) {
+	rows, err := q.db.Query(ctx, listMovieLogByIDs,
+		arg.ShowNotes,
+		arg.ViewerID,
+		arg.UserID,
+		arg.LogIds,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListMovieLogByUserRow
+	var items []ListMovieLogByIDsRow
 	for rows.Next() {
-		var i ListMovieLogByUserRow
+		var i ListMovieLogByIDsRow
 		if err := rows.Scan(
 			&i.LogID,
 			&i.UserID,
@@ -67,8 +465,17 @@ func (q *Queries) ListMovieLogByUser(ctx context.Context, userID int64) ([]ListM
 			&i.WatchedOn,
 			&i.Note,
 			&i.RankPosition,
+			&i.Sentiment,
+			&i.Rating,
+			&i.Version,
+			&i.Visibility,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ViewingCount,
+			&i.Tags,
+			&i.LikeCount,
+			&i.CommentCount,
+			&i.LikedByMe,
 		); err != nil {
 			return nil, err
 		}
@@ -80,29 +487,291 @@ func (q *Queries) ListMovieLogByUser(ctx context.Context, userID int64) ([]ListM
 	return items, nil
 }
 
-const upsertMovieLogEntry = `-- name: UpsertMovieLogEntry :one
-INSERT INTO movie_log (user_id, movie_id, watched_on, note, rank_position)
-VALUES ($1, $2, $3, $4, NULL)
-ON CONFLICT (user_id, movie_id) DO UPDATE
-SET watched_on = EXCLUDED.watched_on,
-    note = EXCLUDED.note,
-    updated_at = now()
-RETURNING id, user_id, movie_id, watched_on, note, rank_position, created_at, updated_at
+const listMovieLogIDs = `-- name: ListMovieLogIDs :many
+SELECT ml.id
+FROM movie_log ml
+JOIN movie_ids mi ON mi.id = ml.movie_id
+JOIN users u ON u.id = ml.user_id
+WHERE ml.user_id = $1
+  AND COALESCE(ml.visibility, u.log_visibility) = ANY($2::text[])
+  AND (
+      COALESCE(cardinality($3::text[]), 0) = 0
+      OR (
+          SELECT COUNT(*)
+          FROM movie_log_tags mlt
+          JOIN tags t ON t.id = mlt.tag_id
+          WHERE mlt.log_id = ml.id AND t.name = ANY($3::text[])
+      ) >= CASE WHEN $4::boolean THEN cardinality($3::text[]) ELSE 1 END
+  )
+  AND ($5::date IS NULL OR ml.watched_on >= $5::date)
+  AND ($6::date IS NULL OR ml.watched_on <= $6::date)
+  AND ($7::boolean IS NULL OR (ml.rank_position IS NOT NULL) = $7::boolean)
+  AND (
+      $8::text IS NULL
+      OR mi.original_title ILIKE $8::text
+      OR ($9::boolean AND ml.note ILIKE $8::text)
+  )
+  AND (
+      $10::bigint IS NULL
+      OR CASE
+          WHEN $11::text = 'watched_on' AND $12::boolean
+              THEN (ml.watched_on, ml.id) < ($13::date, $10::bigint)
+          WHEN $11::text = 'watched_on'
+              THEN (ml.watched_on, ml.id) > ($13::date, $10::bigint)
+          WHEN $11::text = 'created_at' AND $12::boolean
+              THEN (ml.created_at, ml.id) < ($14::timestamptz, $10::bigint)
+          WHEN $11::text = 'created_at'
+              THEN (ml.created_at, ml.id) > ($14::timestamptz, $10::bigint)
+          WHEN $11::text = 'title' AND $12::boolean
+              THEN (mi.original_title, ml.id) < ($15::text, $10::bigint)
+          WHEN $11::text = 'title'
+              THEN (mi.original_title, ml.id) > ($15::text, $10::bigint)
+          WHEN $12::boolean
+              THEN (COALESCE(ml.rank_position, 2147483647), ml.id) < ($16::integer, $10::bigint)
+          ELSE (COALESCE(ml.rank_position, 2147483647), ml.id) > ($16::integer, $10::bigint)
+      END
+  )
+ORDER BY
+    CASE WHEN $11::text = 'watched_on' AND NOT $12::boolean THEN ml.watched_on END ASC,
+    CASE WHEN $11::text = 'watched_on' AND $12::boolean THEN ml.watched_on END DESC,
+    CASE WHEN $11::text = 'created_at' AND NOT $12::boolean THEN ml.created_at END ASC,
+    CASE WHEN $11::text = 'created_at' AND $12::boolean THEN ml.created_at END DESC,
+    CASE WHEN $11::text = 'title' AND NOT $12::boolean THEN mi.original_title END ASC,
+    CASE WHEN $11::text = 'title' AND $12::boolean THEN mi.original_title END DESC,
+    CASE WHEN $11::text = 'rank' AND NOT $12::boolean THEN COALESCE(ml.rank_position, 2147483647) END ASC,
+    CASE WHEN $11::text = 'rank' AND $12::boolean THEN COALESCE(ml.rank_position, 2147483647) END DESC,
+    CASE WHEN $12::boolean THEN ml.id END DESC,
+    ml.id ASC
+LIMIT $17::integer
 `
 
-type UpsertMovieLogEntryParams struct {
-	UserID    int64       `db:"user_id" json:"user_id"`
-	MovieID   int32       `db:"movie_id" json:"movie_id"`
-	WatchedOn pgtype.Date `db:"watched_on" json:"watched_on"`
-	Note      pgtype.Text `db:"note" json:"note"`
+type ListMovieLogIDsParams struct {
+	UserID          int64              `db:"user_id" json:"user_id"`
+	Visibilities    []string           `db:"visibilities" json:"visibilities"`
+	TagNames        []string           `db:"tag_names" json:"tag_names"`
+	MatchAll        bool               `db:"match_all" json:"match_all"`
+	WatchedFrom     pgtype.Date        `db:"watched_from" json:"watched_from"`
+	WatchedTo       pgtype.Date        `db:"watched_to" json:"watched_to"`
+	Ranked          pgtype.Bool        `db:"ranked" json:"ranked"`
+	Search          pgtype.Text        `db:"search" json:"search"`
+	ShowNotes       bool               `db:"show_notes" json:"show_notes"`
+	CursorID        pgtype.Int8        `db:"cursor_id" json:"cursor_id"`
+	SortBy          string             `db:"sort_by" json:"sort_by"`
+	Descending      bool               `db:"descending" json:"descending"`
+	CursorWatchedOn pgtype.Date        `db:"cursor_watched_on" json:"cursor_watched_on"`
+	CursorCreatedAt pgtype.Timestamptz `db:"cursor_created_at" json:"cursor_created_at"`
+	CursorTitle     pgtype.Text        `db:"cursor_title" json:"cursor_title"`
+	CursorRank      pgtype.Int4        `db:"cursor_rank" json:"cursor_rank"`
+	PageSize        int32              `db:"page_size" json:"page_size"`
 }
 
-func (q *Queries) UpsertMovieLogEntry(ctx context.Context, arg UpsertMovieLogEntryParams) (MovieLog, error) {
-	row := q.db.QueryRow(ctx, upsertMovieLogEntry,
+// Pages through a user's log in the order sort_by and descending ask for,
+// with id as the tiebreaker. The cursor is the last entry of the previous
+// page: its id, and its sort key in the cursor column for sort_by; the other
+// cursor columns are NULL. ListMovieLogByIDs then loads the entries on the
+// page.
+func (q *Queries) ListMovieLogIDs(ctx context.Context, arg ListMovieLogIDsParams) ([]int64, error) {
+	rows, err := q.db.Query(ctx, listMovieLogIDs,
 		arg.UserID,
-		arg.MovieID,
-		arg.WatchedOn,
+		arg.Visibilities,
+		arg.TagNames,
+		arg.MatchAll,
+		arg.WatchedFrom,
+		arg.WatchedTo,
+		arg.Ranked,
+		arg.Search,
+		arg.ShowNotes,
+		arg.CursorID,
+		arg.SortBy,
+		arg.Descending,
+		arg.CursorWatchedOn,
+		arg.CursorCreatedAt,
+		arg.CursorTitle,
+		arg.CursorRank,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMovieLogSentiments = `-- name: ListMovieLogSentiments :many
+SELECT id, sentiment
+FROM movie_log
+WHERE user_id = $1 AND id = ANY($2::bigint[])
+`
+
+type ListMovieLogSentimentsParams struct {
+	UserID int64   `db:"user_id" json:"user_id"`
+	LogIds []int64 `db:"log_ids" json:"log_ids"`
+}
+
+type ListMovieLogSentimentsRow struct {
+	ID        int64       `db:"id" json:"id"`
+	Sentiment pgtype.Text `db:"sentiment" json:"sentiment"`
+}
+
+func (q *Queries) ListMovieLogSentiments(ctx context.Context, arg ListMovieLogSentimentsParams) ([]ListMovieLogSentimentsRow, error) {
+	rows, err := q.db.Query(ctx, listMovieLogSentiments, arg.UserID, arg.LogIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListMovieLogSentimentsRow
+	for rows.Next() {
+		var i ListMovieLogSentimentsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Sentiment,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRankedMovieLogByUser = `-- name: ListRankedMovieLogByUser :many
+SELECT ml.id AS log_id, ml.movie_id, mi.original_title, ml.rank_position, ml.sentiment, ml.rating, ml.version,
+       ml.visibility,
+       ROW_NUMBER() OVER (PARTITION BY ml.sentiment ORDER BY ml.rank_position) AS band_position,
+       COUNT(*) OVER (PARTITION BY ml.sentiment) AS band_size
+FROM movie_log ml
+JOIN movie_ids mi ON mi.id = ml.movie_id
+WHERE ml.user_id = $1 AND ml.rank_position IS NOT NULL
+ORDER BY ml.rank_position ASC
+`
+
+type ListRankedMovieLogByUserRow struct {
+	LogID         int64          `db:"log_id" json:"log_id"`
+	MovieID       int32          `db:"movie_id" json:"movie_id"`
+	OriginalTitle string         `db:"original_title" json:"original_title"`
+	RankPosition  pgtype.Int4    `db:"rank_position" json:"rank_position"`
+	Sentiment     pgtype.Text    `db:"sentiment" json:"sentiment"`
+	Rating        pgtype.Numeric `db:"rating" json:"rating"`
+	Version       int64          `db:"version" json:"version"`
+	Visibility    pgtype.Text    `db:"visibility" json:"visibility"`
+	BandPosition  int64          `db:"band_position" json:"band_position"`
+	BandSize      int64          `db:"band_size" json:"band_size"`
+}
+
+func (q *Queries) ListRankedMovieLogByUser(ctx context.Context, userID int64) ([]ListRankedMovieLogByUserRow, error) {
+	rows, err := q.db.Query(ctx, listRankedMovieLogByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRankedMovieLogByUserRow
+	for rows.Next() {
+		var i ListRankedMovieLogByUserRow
+		if err := rows.Scan(
+			&i.LogID,
+			&i.MovieID,
+			&i.OriginalTitle,
+			&i.RankPosition,
+			&i.Sentiment,
+			&i.Rating,
+			&i.Version,
+			&i.Visibility,
+			&i.BandPosition,
+			&i.BandSize,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const movieLogEntryExists = `-- name: MovieLogEntryExists :one
+SELECT EXISTS (
+    SELECT 1
+    FROM movie_log
+    WHERE id = $1 AND user_id = $2
+)
+`
+
+type MovieLogEntryExistsParams struct {
+	ID     int64 `db:"id" json:"id"`
+	UserID int64 `db:"user_id" json:"user_id"`
+}
+
+func (q *Queries) MovieLogEntryExists(ctx context.Context, arg MovieLogEntryExistsParams) (bool, error) {
+	row := q.db.QueryRow(ctx, movieLogEntryExists, arg.ID, arg.UserID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const parkRankPositions = `-- name: ParkRankPositions :exec
+UPDATE movie_log
+SET rank_position = rank_position + 1000000
+WHERE user_id = $1
+  AND rank_position BETWEEN $2::int AND $3::int
+`
+
+type ParkRankPositionsParams struct {
+	UserID       int64 `db:"user_id" json:"user_id"`
+	FromPosition int32 `db:"from_position" json:"from_position"`
+	ToPosition   int32 `db:"to_position" json:"to_position"`
+}
+
+func (q *Queries) ParkRankPositions(ctx context.Context, arg ParkRankPositionsParams) error {
+	_, err := q.db.Exec(ctx, parkRankPositions, arg.UserID, arg.FromPosition, arg.ToPosition)
+	return err
+}
+
+const patchMovieLogEntry = `-- name: PatchMovieLogEntry :one
+UPDATE movie_log
+SET note = CASE WHEN $1::boolean THEN $2::text ELSE note END,
+    rating = CASE WHEN $3::boolean THEN $4::numeric ELSE rating END,
+    visibility = CASE WHEN $5::boolean THEN $6::text ELSE visibility END,
+    version = version + 1,
+    updated_at = now()
+WHERE id = $7 AND user_id = $8
+RETURNING id, user_id, movie_id, watched_on, note, rank_position, sentiment, rating, version, visibility, created_at, updated_at
+`
+
+type PatchMovieLogEntryParams struct {
+	SetNote       bool           `db:"set_note" json:"set_note"`
+	Note          pgtype.Text    `db:"note" json:"note"`
+	SetRating     bool           `db:"set_rating" json:"set_rating"`
+	Rating        pgtype.Numeric `db:"rating" json:"rating"`
+	SetVisibility bool           `db:"set_visibility" json:"set_visibility"`
+	Visibility    pgtype.Text    `db:"visibility" json:"visibility"`
+	ID            int64          `db:"id" json:"id"`
+	UserID        int64          `db:"user_id" json:"user_id"`
+}
+
+func (q *Queries) PatchMovieLogEntry(ctx context.Context, arg PatchMovieLogEntryParams) (MovieLog, error) {
+	row := q.db.QueryRow(ctx, patchMovieLogEntry,
+		arg.SetNote,
 		arg.Note,
+		arg.SetRating,
+		arg.Rating,
+		arg.SetVisibility,
+		arg.Visibility,
+		arg.ID,
+		arg.UserID,
 	)
 	var i MovieLog
 	err := row.Scan(
@@ -112,8 +781,158 @@ func (q *Queries) UpsertMovieLogEntry(ctx context.Context, arg UpsertMovieLogEnt
 		&i.WatchedOn,
 		&i.Note,
 		&i.RankPosition,
+		&i.Sentiment,
+		&i.Rating,
+		&i.Version,
+		&i.Visibility,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
 	return i, err
 }
+
+const ratingHistogramByUser = `-- name: RatingHistogramByUser :many
+SELECT ml.rating, COUNT(*) AS rating_count
+FROM movie_log ml
+JOIN users u ON u.id = ml.user_id
+WHERE ml.user_id = $1
+  AND ml.rating IS NOT NULL
+  AND COALESCE(ml.visibility, u.log_visibility) = ANY($2::text[])
+GROUP BY ml.rating
+ORDER BY ml.rating ASC
+`
+
+type RatingHistogramByUserParams struct {
+	UserID       int64    `db:"user_id" json:"user_id"`
+	Visibilities []string `db:"visibilities" json:"visibilities"`
+}
+
+type RatingHistogramByUserRow struct {
+	Rating      pgtype.Numeric `db:"rating" json:"rating"`
+	RatingCount int64          `db:"rating_count" json:"rating_count"`
+}
+
+func (q *Queries) RatingHistogramByUser(ctx context.Context, arg RatingHistogramByUserParams) ([]RatingHistogramByUserRow, error) {
+	rows, err := q.db.Query(ctx, ratingHistogramByUser, arg.UserID, arg.Visibilities)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RatingHistogramByUserRow
+	for rows.Next() {
+		var i RatingHistogramByUserRow
+		if err := rows.Scan(
+			&i.Rating,
+			&i.RatingCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setMovieLogRankPosition = `-- name: SetMovieLogRankPosition :exec
+UPDATE movie_log
+SET rank_position = $1,
+    version = version + 1,
+    updated_at = now()
+WHERE id = $2 AND user_id = $3
+`
+
+type SetMovieLogRankPositionParams struct {
+	RankPosition pgtype.Int4 `db:"rank_position" json:"rank_position"`
+	ID           int64       `db:"id" json:"id"`
+	UserID       int64       `db:"user_id" json:"user_id"`
+}
+
+func (q *Queries) SetMovieLogRankPosition(ctx context.Context, arg SetMovieLogRankPositionParams) error {
+	_, err := q.db.Exec(ctx, setMovieLogRankPosition, arg.RankPosition, arg.ID, arg.UserID)
+	return err
+}
+
+const setMovieLogSentiment = `-- name: SetMovieLogSentiment :exec
+UPDATE movie_log
+SET sentiment = $1,
+    version = version + 1,
+    updated_at = now()
+WHERE id = $2 AND user_id = $3
+`
+
+type SetMovieLogSentimentParams struct {
+	Sentiment pgtype.Text `db:"sentiment" json:"sentiment"`
+	ID        int64       `db:"id" json:"id"`
+	UserID    int64       `db:"user_id" json:"user_id"`
+}
+
+func (q *Queries) SetMovieLogSentiment(ctx context.Context, arg SetMovieLogSentimentParams) error {
+	_, err := q.db.Exec(ctx, setMovieLogSentiment, arg.Sentiment, arg.ID, arg.UserID)
+	return err
+}
+
+const syncMovieLogWatchedOn = `-- name: SyncMovieLogWatchedOn :one
+UPDATE movie_log ml
+SET watched_on = latest.watched_on,
+    version = ml.version + 1,
+    updated_at = now()
+FROM (
+    SELECT MAX(watched_on) AS watched_on
+    FROM movie_log_viewings
+    WHERE log_id = $1
+) latest
+WHERE ml.id = $1 AND latest.watched_on IS NOT NULL
+RETURNING ml.watched_on, ml.version
+`
+
+type SyncMovieLogWatchedOnRow struct {
+	WatchedOn pgtype.Date `db:"watched_on" json:"watched_on"`
+	Version   int64       `db:"version" json:"version"`
+}
+
+func (q *Queries) SyncMovieLogWatchedOn(ctx context.Context, id int64) (SyncMovieLogWatchedOnRow, error) {
+	row := q.db.QueryRow(ctx, syncMovieLogWatchedOn, id)
+	var i SyncMovieLogWatchedOnRow
+	err := row.Scan(
+		&i.WatchedOn,
+		&i.Version,
+	)
+	return i, err
+}
+
+const touchMovieLogEntry = `-- name: TouchMovieLogEntry :exec
+UPDATE movie_log
+SET version = version + 1,
+    updated_at = now()
+WHERE id = $1 AND user_id = $2
+`
+
+type TouchMovieLogEntryParams struct {
+	ID     int64 `db:"id" json:"id"`
+	UserID int64 `db:"user_id" json:"user_id"`
+}
+
+func (q *Queries) TouchMovieLogEntry(ctx context.Context, arg TouchMovieLogEntryParams) error {
+	_, err := q.db.Exec(ctx, touchMovieLogEntry, arg.ID, arg.UserID)
+	return err
+}
+
+const unparkRankPositions = `-- name: UnparkRankPositions :exec
+UPDATE movie_log
+SET rank_position = rank_position - 1000000 + $1::int,
+    version = version + 1
+WHERE user_id = $2
+  AND rank_position > 1000000
+`
+
+type UnparkRankPositionsParams struct {
+	Delta  int32 `db:"delta" json:"delta"`
+	UserID int64 `db:"user_id" json:"user_id"`
+}
+
+func (q *Queries) UnparkRankPositions(ctx context.Context, arg UnparkRankPositionsParams) error {
+	_, err := q.db.Exec(ctx, unparkRankPositions, arg.Delta, arg.UserID)
+	return err
+}
@@ -11,6 +11,71 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const listMovieIDsByIMDbIDs = `-- name: ListMovieIDsByIMDbIDs :many
+SELECT imdb_id, movie_id
+FROM imdb_movie_ids
+WHERE imdb_id = ANY($1::text[])
+`
+
+func (q *Queries) ListMovieIDsByIMDbIDs(ctx context.Context, imdbIds []string) ([]ImdbMovieID, error) {
+	rows, err := q.db.Query(ctx, listMovieIDsByIMDbIDs, imdbIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ImdbMovieID
+	for rows.Next() {
+		var i ImdbMovieID
+		if err := rows.Scan(
+			&i.ImdbID,
+			&i.MovieID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMoviesByIDs = `-- name: ListMoviesByIDs :many
+SELECT id, original_title, popularity
+FROM movie_ids
+WHERE id = ANY($1::int[])
+`
+
+type ListMoviesByIDsRow struct {
+	ID            int32          `db:"id" json:"id"`
+	OriginalTitle string         `db:"original_title" json:"original_title"`
+	Popularity    pgtype.Numeric `db:"popularity" json:"popularity"`
+}
+
+func (q *Queries) ListMoviesByIDs(ctx context.Context, ids []int32) ([]ListMoviesByIDsRow, error) {
+	rows, err := q.db.Query(ctx, listMoviesByIDs, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListMoviesByIDsRow
+	for rows.Next() {
+		var i ListMoviesByIDsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OriginalTitle,
+			&i.Popularity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const movieExists = `-- name: MovieExists :one
 SELECT EXISTS (
     SELECT 1
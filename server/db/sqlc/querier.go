@@ -6,17 +6,137 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
+	AcceptFollowRequest(ctx context.Context, arg AcceptFollowRequestParams) (Friendship, error)
+	AddFirstMovieLogViewing(ctx context.Context, arg AddFirstMovieLogViewingParams) error
+	AddMovieLogTag(ctx context.Context, arg AddMovieLogTagParams) error
+	ApplyRankOrder(ctx context.Context, arg ApplyRankOrderParams) (int64, error)
+	AreFriends(ctx context.Context, arg AreFriendsParams) (bool, error)
+	ClearMovieLogTags(ctx context.Context, logID int64) error
+	ClearRankPositions(ctx context.Context, userID int64) error
+	CountMovieLogViewings(ctx context.Context, logID int64) (int64, error)
+	CountRankedMovieLog(ctx context.Context, userID int64) (int64, error)
+	CountRankedMovieLogBySentiment(ctx context.Context, userID int64) ([]CountRankedMovieLogBySentimentRow, error)
+	CountUnreadNotifications(ctx context.Context, userID int64) (int64, error)
+	CreateAPIToken(ctx context.Context, arg CreateAPITokenParams) (ApiToken, error)
+	CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) error
+	CreateFollowRequest(ctx context.Context, arg CreateFollowRequestParams) (Friendship, error)
+	CreateLogComment(ctx context.Context, arg CreateLogCommentParams) (LogComment, error)
+	CreateLogImport(ctx context.Context, arg CreateLogImportParams) (LogImport, error)
+	CreateLogImportReview(ctx context.Context, arg CreateLogImportReviewParams) error
+	CreateMovieLogEntry(ctx context.Context, arg CreateMovieLogEntryParams) (MovieLog, error)
+	CreateMovieLogViewing(ctx context.Context, arg CreateMovieLogViewingParams) (MovieLogViewing, error)
+	CreateNotification(ctx context.Context, arg CreateNotificationParams) (int64, error)
 	CreateUser(ctx context.Context, username string) (User, error)
-	DeleteMovieLogEntry(ctx context.Context, arg DeleteMovieLogEntryParams) (int64, error)
+	CreateUserCredentials(ctx context.Context, arg CreateUserCredentialsParams) error
+	CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) error
+	CreateUserSession(ctx context.Context, arg CreateUserSessionParams) error
+	DeleteAPIToken(ctx context.Context, arg DeleteAPITokenParams) (int64, error)
+	DeleteExpiredUserSessions(ctx context.Context, userID int64) error
+	DeleteFollow(ctx context.Context, arg DeleteFollowParams) (int64, error)
+	DeleteFollowRequest(ctx context.Context, arg DeleteFollowRequestParams) (int64, error)
+	DeleteIdleRateLimitBuckets(ctx context.Context, idleSeconds float64) (int64, error)
+	DeleteLogComment(ctx context.Context, arg DeleteLogCommentParams) (int64, error)
+	DeleteMovieLogEntry(ctx context.Context, arg DeleteMovieLogEntryParams) (MovieLog, error)
+	DeleteMovieLogViewing(ctx context.Context, arg DeleteMovieLogViewingParams) (int64, error)
+	DeleteRankSession(ctx context.Context, arg DeleteRankSessionParams) (int64, error)
+	DeleteRankSessionByLogID(ctx context.Context, logID int64) error
+	DeleteRankSessionsByLogIDs(ctx context.Context, logIds []int64) error
+	DeleteUnusedTags(ctx context.Context, userID int64) error
 	DeleteUser(ctx context.Context, id int64) (int64, error)
-	ListMovieLogByUser(ctx context.Context, userID int64) ([]ListMovieLogByUserRow, error)
+	DeleteUserSession(ctx context.Context, tokenHash string) error
+	DeleteUserSessionsByUser(ctx context.Context, userID int64) error
+	EnsureRateLimitBucket(ctx context.Context, arg EnsureRateLimitBucketParams) error
+	ExportMovieLogPage(ctx context.Context, arg ExportMovieLogPageParams) ([]ExportMovieLogPageRow, error)
+	FailInterruptedLogImports(ctx context.Context) (int64, error)
+	FailLogImport(ctx context.Context, arg FailLogImportParams) error
+	FillUserProfile(ctx context.Context, arg FillUserProfileParams) (User, error)
+	FinishLogImport(ctx context.Context, arg FinishLogImportParams) (LogImport, error)
+	GetAPITokenUser(ctx context.Context, tokenHash string) (GetAPITokenUserRow, error)
+	GetLogComment(ctx context.Context, arg GetLogCommentParams) (LogComment, error)
+	GetLogImport(ctx context.Context, arg GetLogImportParams) (LogImport, error)
+	GetLogImportReview(ctx context.Context, arg GetLogImportReviewParams) (LogImportReview, error)
+	GetMovieLogEngagement(ctx context.Context, logID int64) (GetMovieLogEngagementRow, error)
+	GetMovieLogEntryAtRank(ctx context.Context, arg GetMovieLogEntryAtRankParams) (GetMovieLogEntryAtRankRow, error)
+	GetMovieLogRankEntry(ctx context.Context, arg GetMovieLogRankEntryParams) (GetMovieLogRankEntryRow, error)
+	GetMovieLogVersion(ctx context.Context, arg GetMovieLogVersionParams) (int64, error)
+	GetMovieLogVisibility(ctx context.Context, arg GetMovieLogVisibilityParams) (pgtype.Text, error)
+	GetOIDCLinkStatus(ctx context.Context, arg GetOIDCLinkStatusParams) (GetOIDCLinkStatusRow, error)
+	GetRankSession(ctx context.Context, arg GetRankSessionParams) (RankSession, error)
+	GetRelationship(ctx context.Context, arg GetRelationshipParams) (GetRelationshipRow, error)
+	GetSessionUser(ctx context.Context, tokenHash string) (GetSessionUserRow, error)
+	GetUser(ctx context.Context, id int64) (User, error)
+	GetUserCredentialsByUsername(ctx context.Context, username string) (GetUserCredentialsByUsernameRow, error)
+	GetUserIDByUsername(ctx context.Context, username string) (int64, error)
+	GetUserIdentity(ctx context.Context, arg GetUserIdentityParams) (int64, error)
+	GetUserPrivacy(ctx context.Context, id int64) (GetUserPrivacyRow, error)
+	LikeMovieLogEntry(ctx context.Context, arg LikeMovieLogEntryParams) (int64, error)
+	ListAPITokens(ctx context.Context, userID int64) ([]ApiToken, error)
+	ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error)
+	ListFeedEvents(ctx context.Context, arg ListFeedEventsParams) ([]ListFeedEventsRow, error)
+	ListFollowers(ctx context.Context, userID int64) ([]ListFollowersRow, error)
+	ListFollowing(ctx context.Context, userID int64) ([]ListFollowingRow, error)
+	ListFriends(ctx context.Context, userID int64) ([]ListFriendsRow, error)
+	ListIncomingFollowRequests(ctx context.Context, userID int64) ([]ListIncomingFollowRequestsRow, error)
+	ListLogComments(ctx context.Context, logID int64) ([]ListLogCommentsRow, error)
+	ListLogCommentsByAuthor(ctx context.Context, userID int64) ([]ListLogCommentsByAuthorRow, error)
+	ListLogImportReviews(ctx context.Context, arg ListLogImportReviewsParams) ([]LogImportReview, error)
+	ListLogLikesByUser(ctx context.Context, userID int64) ([]ListLogLikesByUserRow, error)
+	ListLoggedMovieIDsByUser(ctx context.Context, arg ListLoggedMovieIDsByUserParams) ([]int32, error)
+	ListMovieIDsByIMDbIDs(ctx context.Context, imdbIds []string) ([]ImdbMovieID, error)
+	ListMovieLogByIDs(ctx context.Context, arg ListMovieLogByIDsParams) ([]ListMovieLogByIDsRow, error)
+	ListMovieLogIDs(ctx context.Context, arg ListMovieLogIDsParams) ([]int64, error)
+	ListMovieLogLikes(ctx context.Context, logID int64) ([]ListMovieLogLikesRow, error)
+	ListMovieLogSentiments(ctx context.Context, arg ListMovieLogSentimentsParams) ([]ListMovieLogSentimentsRow, error)
+	ListMovieLogTagNames(ctx context.Context, logID int64) ([]string, error)
+	ListMovieLogViewingDates(ctx context.Context, arg ListMovieLogViewingDatesParams) ([]ListMovieLogViewingDatesRow, error)
+	ListMovieLogViewings(ctx context.Context, logID int64) ([]MovieLogViewing, error)
+	ListMovieLogViewingsByUser(ctx context.Context, userID int64) ([]MovieLogViewing, error)
+	ListMoviesByIDs(ctx context.Context, ids []int32) ([]ListMoviesByIDsRow, error)
+	ListNotificationPreferences(ctx context.Context, userID int64) ([]ListNotificationPreferencesRow, error)
+	ListNotifications(ctx context.Context, arg ListNotificationsParams) ([]ListNotificationsRow, error)
+	ListOutgoingFollowRequests(ctx context.Context, userID int64) ([]ListOutgoingFollowRequestsRow, error)
+	ListRankSessionsByUser(ctx context.Context, userID int64) ([]RankSession, error)
+	ListRankedMovieLogByUser(ctx context.Context, userID int64) ([]ListRankedMovieLogByUserRow, error)
+	ListTagsByUser(ctx context.Context, arg ListTagsByUserParams) ([]ListTagsByUserRow, error)
+	ListUserIDsByUsernames(ctx context.Context, usernames []string) ([]ListUserIDsByUsernamesRow, error)
+	ListUserIDsByVerifiedEmail(ctx context.Context, email string) ([]int64, error)
 	ListUsers(ctx context.Context) ([]User, error)
+	LockRateLimitBucket(ctx context.Context, key string) (LockRateLimitBucketRow, error)
+	LockUser(ctx context.Context, id int64) (int64, error)
+	MarkAllNotificationsRead(ctx context.Context, userID int64) (int64, error)
+	MarkNotificationRead(ctx context.Context, arg MarkNotificationReadParams) (int64, error)
 	MovieExists(ctx context.Context, id int32) (bool, error)
+	MovieLogEntryExists(ctx context.Context, arg MovieLogEntryExistsParams) (bool, error)
+	ParkRankPositions(ctx context.Context, arg ParkRankPositionsParams) error
+	PatchMovieLogEntry(ctx context.Context, arg PatchMovieLogEntryParams) (MovieLog, error)
+	RatingHistogramByUser(ctx context.Context, arg RatingHistogramByUserParams) ([]RatingHistogramByUserRow, error)
+	RecordActivityEvent(ctx context.Context, arg RecordActivityEventParams) error
+	RemoveMovieLogTag(ctx context.Context, arg RemoveMovieLogTagParams) (int64, error)
+	ResolveLogImportReview(ctx context.Context, arg ResolveLogImportReviewParams) (LogImportReview, error)
+	RunningLogImportExists(ctx context.Context, userID int64) (bool, error)
 	SearchMovies(ctx context.Context, query string) ([]SearchMoviesRow, error)
-	UpsertMovieLogEntry(ctx context.Context, arg UpsertMovieLogEntryParams) (MovieLog, error)
+	SetLatestMovieLogViewingDate(ctx context.Context, arg SetLatestMovieLogViewingDateParams) error
+	SetMovieLogRankPosition(ctx context.Context, arg SetMovieLogRankPositionParams) error
+	SetMovieLogSentiment(ctx context.Context, arg SetMovieLogSentimentParams) error
+	SetNotificationPreference(ctx context.Context, arg SetNotificationPreferenceParams) error
+	SetUserCredentials(ctx context.Context, arg SetUserCredentialsParams) error
+	SetUserRole(ctx context.Context, arg SetUserRoleParams) (User, error)
+	SyncMovieLogWatchedOn(ctx context.Context, id int64) (SyncMovieLogWatchedOnRow, error)
+	TouchAPIToken(ctx context.Context, id int64) error
+	TouchMovieLogEntry(ctx context.Context, arg TouchMovieLogEntryParams) error
+	UnlikeMovieLogEntry(ctx context.Context, arg UnlikeMovieLogEntryParams) (int64, error)
+	UnparkRankPositions(ctx context.Context, arg UnparkRankPositionsParams) error
+	UpdateLogComment(ctx context.Context, arg UpdateLogCommentParams) (LogComment, error)
+	UpdateLogImportProgress(ctx context.Context, arg UpdateLogImportProgressParams) error
+	UpdateRateLimitBucket(ctx context.Context, arg UpdateRateLimitBucketParams) error
+	UpdateUserPrivacy(ctx context.Context, arg UpdateUserPrivacyParams) (UpdateUserPrivacyRow, error)
+	UpsertRankSession(ctx context.Context, arg UpsertRankSessionParams) (RankSession, error)
+	UpsertTag(ctx context.Context, arg UpsertTagParams) (Tag, error)
 	UserExists(ctx context.Context, id int64) (bool, error)
 }
 
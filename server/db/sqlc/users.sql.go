@@ -7,12 +7,14 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (username)
 VALUES ($1)
-RETURNING id, username, display_name, bio, avatar_url, created_at, updated_at
+RETURNING id, username, display_name, bio, avatar_url, log_visibility, hide_notes, role, created_at, updated_at
 `
 
 func (q *Queries) CreateUser(ctx context.Context, username string) (User, error) {
@@ -24,6 +26,9 @@ func (q *Queries) CreateUser(ctx context.Context, username string) (User, error)
 		&i.DisplayName,
 		&i.Bio,
 		&i.AvatarUrl,
+		&i.LogVisibility,
+		&i.HideNotes,
+		&i.Role,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -43,8 +48,140 @@ func (q *Queries) DeleteUser(ctx context.Context, id int64) (int64, error) {
 	return result.RowsAffected(), nil
 }
 
+const fillUserProfile = `-- name: FillUserProfile :one
+UPDATE users
+SET display_name = COALESCE(display_name, $1::text),
+    bio = COALESCE(bio, $2::text),
+    avatar_url = COALESCE(avatar_url, $3::text),
+    updated_at = now()
+WHERE id = $4
+RETURNING id, username, display_name, bio, avatar_url, log_visibility, hide_notes, role, created_at, updated_at
+`
+
+type FillUserProfileParams struct {
+	DisplayName pgtype.Text `db:"display_name" json:"display_name"`
+	Bio         pgtype.Text `db:"bio" json:"bio"`
+	AvatarUrl   pgtype.Text `db:"avatar_url" json:"avatar_url"`
+	ID          int64       `db:"id" json:"id"`
+}
+
+func (q *Queries) FillUserProfile(ctx context.Context, arg FillUserProfileParams) (User, error) {
+	row := q.db.QueryRow(ctx, fillUserProfile,
+		arg.DisplayName,
+		arg.Bio,
+		arg.AvatarUrl,
+		arg.ID,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.LogVisibility,
+		&i.HideNotes,
+		&i.Role,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getUser = `-- name: GetUser :one
+SELECT id, username, display_name, bio, avatar_url, log_visibility, hide_notes, role, created_at, updated_at
+FROM users
+WHERE id = $1
+`
+
+func (q *Queries) GetUser(ctx context.Context, id int64) (User, error) {
+	row := q.db.QueryRow(ctx, getUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.LogVisibility,
+		&i.HideNotes,
+		&i.Role,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getUserIDByUsername = `-- name: GetUserIDByUsername :one
+SELECT id
+FROM users
+WHERE lower(username) = lower($1::text)
+`
+
+func (q *Queries) GetUserIDByUsername(ctx context.Context, username string) (int64, error) {
+	row := q.db.QueryRow(ctx, getUserIDByUsername, username)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const getUserPrivacy = `-- name: GetUserPrivacy :one
+SELECT log_visibility, hide_notes
+FROM users
+WHERE id = $1
+`
+
+type GetUserPrivacyRow struct {
+	LogVisibility string `db:"log_visibility" json:"log_visibility"`
+	HideNotes     bool   `db:"hide_notes" json:"hide_notes"`
+}
+
+func (q *Queries) GetUserPrivacy(ctx context.Context, id int64) (GetUserPrivacyRow, error) {
+	row := q.db.QueryRow(ctx, getUserPrivacy, id)
+	var i GetUserPrivacyRow
+	err := row.Scan(
+		&i.LogVisibility,
+		&i.HideNotes,
+	)
+	return i, err
+}
+
+const listUserIDsByUsernames = `-- name: ListUserIDsByUsernames :many
+SELECT id, username
+FROM users
+WHERE lower(username) = ANY($1::text[])
+`
+
+type ListUserIDsByUsernamesRow struct {
+	ID       int64  `db:"id" json:"id"`
+	Username string `db:"username" json:"username"`
+}
+
+func (q *Queries) ListUserIDsByUsernames(ctx context.Context, usernames []string) ([]ListUserIDsByUsernamesRow, error) {
+	rows, err := q.db.Query(ctx, listUserIDsByUsernames, usernames)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUserIDsByUsernamesRow
+	for rows.Next() {
+		var i ListUserIDsByUsernamesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listUsers = `-- name: ListUsers :many
-SELECT id, username, display_name, bio, avatar_url, created_at, updated_at
+SELECT id, username, display_name, bio, avatar_url, log_visibility, hide_notes, role, created_at, updated_at
 FROM users
 ORDER BY id DESC
 `
@@ -64,6 +201,9 @@ func (q *Queries) ListUsers(ctx context.Context) ([]User, error) {
 			&i.DisplayName,
 			&i.Bio,
 			&i.AvatarUrl,
+			&i.LogVisibility,
+			&i.HideNotes,
+			&i.Role,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -77,6 +217,80 @@ func (q *Queries) ListUsers(ctx context.Context) ([]User, error) {
 	return items, nil
 }
 
+const lockUser = `-- name: LockUser :one
+SELECT id
+FROM users
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) LockUser(ctx context.Context, id int64) (int64, error) {
+	row := q.db.QueryRow(ctx, lockUser, id)
+	err := row.Scan(&id)
+	return id, err
+}
+
+const setUserRole = `-- name: SetUserRole :one
+UPDATE users
+SET role = $1,
+    updated_at = now()
+WHERE id = $2
+RETURNING id, username, display_name, bio, avatar_url, log_visibility, hide_notes, role, created_at, updated_at
+`
+
+type SetUserRoleParams struct {
+	Role string `db:"role" json:"role"`
+	ID   int64  `db:"id" json:"id"`
+}
+
+func (q *Queries) SetUserRole(ctx context.Context, arg SetUserRoleParams) (User, error) {
+	row := q.db.QueryRow(ctx, setUserRole, arg.Role, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.LogVisibility,
+		&i.HideNotes,
+		&i.Role,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateUserPrivacy = `-- name: UpdateUserPrivacy :one
+UPDATE users
+SET log_visibility = COALESCE($1::text, log_visibility),
+    hide_notes = COALESCE($2::boolean, hide_notes),
+    updated_at = now()
+WHERE id = $3
+RETURNING log_visibility, hide_notes
+`
+
+type UpdateUserPrivacyParams struct {
+	LogVisibility pgtype.Text `db:"log_visibility" json:"log_visibility"`
+	HideNotes     pgtype.Bool `db:"hide_notes" json:"hide_notes"`
+	ID            int64       `db:"id" json:"id"`
+}
+
+type UpdateUserPrivacyRow struct {
+	LogVisibility string `db:"log_visibility" json:"log_visibility"`
+	HideNotes     bool   `db:"hide_notes" json:"hide_notes"`
+}
+
+func (q *Queries) UpdateUserPrivacy(ctx context.Context, arg UpdateUserPrivacyParams) (UpdateUserPrivacyRow, error) {
+	row := q.db.QueryRow(ctx, updateUserPrivacy, arg.LogVisibility, arg.HideNotes, arg.ID)
+	var i UpdateUserPrivacyRow
+	err := row.Scan(
+		&i.LogVisibility,
+		&i.HideNotes,
+	)
+	return i, err
+}
+
 const userExists = `-- name: UserExists :one
 SELECT EXISTS (
     SELECT 1
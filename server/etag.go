@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var errPreconditionFailed = errors.New("resource has changed since it was read; reload and try again")
+
+// movieLogETag tags one log entry. Every write to a movie_log row bumps its
+// version, so the tag changes whenever the entry does.
+func movieLogETag(version int64) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// rankingETag tags a user's whole ranking. It fingerprints the ranked entries
+// in order together with their versions, so any reorder, insertion or removal
+// produces a new tag.
+func rankingETag(entries []db.ListRankedMovieLogByUserRow) string {
+	hash := sha256.New()
+	for _, entry := range entries {
+		fmt.Fprintf(hash, "%d:%d:%d;", entry.LogID, entry.RankPosition.Int32, entry.Version)
+	}
+	return fmt.Sprintf(`"%x"`, hash.Sum(nil)[:16])
+}
+
+// ifMatchSatisfied reports whether an If-Match header value allows a write to
+// a resource currently tagged etag. An absent header always does.
+func ifMatchSatisfied(ifMatch, etag string) bool {
+	if ifMatch == "" {
+		return true
+	}
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// requireMovieLogMatch checks If-Match against the log entry's current
+// version. It must run inside withUserTx so the version cannot move between
+// the check and the write.
+func requireMovieLogMatch(ctx context.Context, qtx *db.Queries, userID, logID int64, ifMatch string) error {
+	version, err := qtx.GetMovieLogVersion(ctx, db.GetMovieLogVersionParams{
+		ID:     logID,
+		UserID: userID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errLogEntryNotFound
+		}
+		return fmt.Errorf("get log entry version: %w", err)
+	}
+
+	if !ifMatchSatisfied(ifMatch, movieLogETag(version)) {
+		return errPreconditionFailed
+	}
+	return nil
+}
+
+// requireRankingMatch checks If-Match against the user's current ranking. Like
+// requireMovieLogMatch it must run inside withUserTx.
+func requireRankingMatch(ctx context.Context, qtx *db.Queries, userID int64, ifMatch string) error {
+	if ifMatch == "" {
+		return nil
+	}
+
+	entries, err := qtx.ListRankedMovieLogByUser(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("list ranked entries: %w", err)
+	}
+
+	if !ifMatchSatisfied(ifMatch, rankingETag(entries)) {
+		return errPreconditionFailed
+	}
+	return nil
+}
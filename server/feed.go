@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultFeedPageSize = 50
+	maxFeedPageSize     = 200
+)
+
+var errInvalidTimelineCursor = errors.New("cursor is invalid")
+
+// feedGroupWindow is how far apart a friend's events of the same kind can be
+// and still be summarized as one feed item.
+const feedGroupWindow = 6 * time.Hour
+
+// feedSummaries phrases each kind of event for one movie and for several.
+var feedSummaries = map[string][2]string{
+	"logged":    {"%s logged %s", "%s logged %d movies"},
+	"noted":     {"%s wrote about %s", "%s wrote about %d movies"},
+	"ranked":    {"%s ranked %s", "%s ranked %d movies"},
+	"rewatched": {"%s rewatched %s", "%s rewatched %d movies"},
+}
+
+// timelineCursor marks the last row of a page listed newest first by
+// created_at and id, as the feed and notifications are. It is handed to
+// clients as opaque base64.
+type timelineCursor struct {
+	CreatedAt string `json:"t"`
+	ID        int64  `json:"id"`
+}
+
+// recordActivity adds an event to the feeds of the user's followers. Only
+// changes made one at a time through the log and ranking routes are recorded;
+// imports and archive restores would flood the feed.
+func recordActivity(ctx context.Context, qtx *db.Queries, event db.RecordActivityEventParams) error {
+	if err := qtx.RecordActivityEvent(ctx, event); err != nil {
+		return fmt.Errorf("record %s activity: %w", event.Kind, err)
+	}
+	return nil
+}
+
+// recordRankActivity records that a log entry was placed at position in the
+// ranking, along with the band it was placed in.
+func recordRankActivity(ctx context.Context, qtx *db.Queries, userID, logID int64, position int32) error {
+	entry, err := qtx.GetMovieLogRankEntry(ctx, db.GetMovieLogRankEntryParams{
+		ID:     logID,
+		UserID: userID,
+	})
+	if err != nil {
+		return fmt.Errorf("get ranked entry: %w", err)
+	}
+
+	return recordActivity(ctx, qtx, db.RecordActivityEventParams{
+		UserID:       userID,
+		LogID:        logID,
+		Kind:         "ranked",
+		Sentiment:    entry.Sentiment,
+		RankPosition: pgtype.Int4{Int32: position, Valid: true},
+	})
+}
+
+// parseFeedParams reads limit and cursor for a feed page. PageSize is set one
+// past limit so the caller can tell whether another page follows.
+func parseFeedParams(c echo.Context, userID int64) (db.ListFeedEventsParams, int, error) {
+	params := db.ListFeedEventsParams{
+		UserID: userID,
+	}
+
+	limit := defaultFeedPageSize
+	if raw := c.QueryParam("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxFeedPageSize {
+			return params, 0, fmt.Errorf("limit must be between 1 and %d", maxFeedPageSize)
+		}
+		limit = parsed
+	}
+	params.PageSize = int32(limit + 1)
+
+	if raw := c.QueryParam("cursor"); raw != "" {
+		var err error
+		params.CursorCreatedAt, params.CursorID, err = decodeTimelineCursor(raw)
+		if err != nil {
+			return params, 0, err
+		}
+	}
+
+	return params, limit, nil
+}
+
+// encodeFeedCursor builds the cursor for the page that starts after row.
+func encodeFeedCursor(row db.ListFeedEventsRow) string {
+	return encodeTimelineCursor(row.CreatedAt, row.ID)
+}
+
+func encodeTimelineCursor(createdAt pgtype.Timestamptz, id int64) string {
+	encoded, _ := json.Marshal(timelineCursor{
+		CreatedAt: createdAt.Time.UTC().Format(time.RFC3339Nano),
+		ID:        id,
+	})
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+func decodeTimelineCursor(raw string) (pgtype.Timestamptz, int64, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return pgtype.Timestamptz{}, 0, errInvalidTimelineCursor
+	}
+	var cursor timelineCursor
+	if err := json.Unmarshal(decoded, &cursor); err != nil {
+		return pgtype.Timestamptz{}, 0, errInvalidTimelineCursor
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, cursor.CreatedAt)
+	if err != nil {
+		return pgtype.Timestamptz{}, 0, errInvalidTimelineCursor
+	}
+	return pgtype.Timestamptz{Time: createdAt, Valid: true}, cursor.ID, nil
+}
+
+// groupFeedEvents folds consecutive events by the same friend of the same
+// kind into one item, as long as they fall within feedGroupWindow of the
+// item's newest event. Grouping only sees one page, so a run of events that
+// straddles a page boundary shows up as two items.
+func groupFeedEvents(rows []db.ListFeedEventsRow) []FeedItemResponse {
+	items := []FeedItemResponse{}
+	var newest time.Time
+	for _, row := range rows {
+		last := len(items) - 1
+		if last >= 0 &&
+			items[last].Actor.UserID == row.UserID &&
+			items[last].Kind == row.Kind &&
+			newest.Sub(row.CreatedAt.Time) <= feedGroupWindow {
+			items[last].Events = append(items[last].Events, toFeedEventResponse(row))
+			items[last].EarliestAt = timestamptzRFC3339(row.CreatedAt)
+			continue
+		}
+
+		newest = row.CreatedAt.Time
+		items = append(items, FeedItemResponse{
+			Actor: FeedActorResponse{
+				UserID:      row.UserID,
+				Username:    row.Username,
+				DisplayName: textPtr(row.DisplayName),
+				AvatarURL:   textPtr(row.AvatarUrl),
+			},
+			Kind:       row.Kind,
+			LatestAt:   timestamptzRFC3339(row.CreatedAt),
+			EarliestAt: timestamptzRFC3339(row.CreatedAt),
+			Events:     []FeedEventResponse{toFeedEventResponse(row)},
+		})
+	}
+
+	for i := range items {
+		items[i].Count = len(items[i].Events)
+		items[i].Summary = feedSummary(items[i])
+	}
+	return items
+}
+
+func feedSummary(item FeedItemResponse) string {
+	name := item.Actor.Username
+	if item.Actor.DisplayName != nil && *item.Actor.DisplayName != "" {
+		name = *item.Actor.DisplayName
+	}
+
+	phrases := feedSummaries[item.Kind]
+	if item.Count == 1 {
+		return fmt.Sprintf(phrases[0], name, item.Events[0].OriginalTitle)
+	}
+	return fmt.Sprintf(phrases[1], name, item.Count)
+}
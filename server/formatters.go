@@ -31,6 +31,26 @@ func int4Ptr(value pgtype.Int4) *int32 {
 	return &v
 }
 
+func int8Ptr(value pgtype.Int8) *int64 {
+	if !value.Valid {
+		return nil
+	}
+	v := value.Int64
+	return &v
+}
+
+func numericFloatPtr(value pgtype.Numeric) *float64 {
+	if !value.Valid {
+		return nil
+	}
+	f, err := value.Float64Value()
+	if err != nil || !f.Valid {
+		return nil
+	}
+	v := f.Float64
+	return &v
+}
+
 func dateISO(value pgtype.Date) string {
 	if !value.Valid {
 		return ""
@@ -53,12 +73,39 @@ func toAdminUserResponse(user db.User) AdminUserResponse {
 		DisplayName: textPtr(user.DisplayName),
 		Bio:         textPtr(user.Bio),
 		AvatarURL:   textPtr(user.AvatarUrl),
+		Role:        user.Role,
 		CreatedAt:   timestamptzRFC3339(user.CreatedAt),
 		UpdatedAt:   timestamptzRFC3339(user.UpdatedAt),
 	}
 }
 
-func toMovieLogResponse(logEntry db.ListMovieLogByUserRow) MovieLogResponse {
+func toAuditEventResponse(event db.AuditEvent) AuditEventResponse {
+	return AuditEventResponse{
+		AuditEventID:  event.ID,
+		ActorID:       int8Ptr(event.ActorID),
+		ActorUsername: textPtr(event.ActorUsername),
+		Action:        event.Action,
+		TargetType:    event.TargetType,
+		TargetID:      int8Ptr(event.TargetID),
+		RequestID:     textPtr(event.RequestID),
+		IP:            textPtr(event.Ip),
+		Before:        event.Before,
+		After:         event.After,
+		CreatedAt:     timestamptzRFC3339(event.CreatedAt),
+	}
+}
+
+func toAuthUserResponse(id int64, username string, displayName, avatarURL pgtype.Text, role string) AuthUserResponse {
+	return AuthUserResponse{
+		ID:          id,
+		Username:    username,
+		DisplayName: textPtr(displayName),
+		AvatarURL:   textPtr(avatarURL),
+		Role:        role,
+	}
+}
+
+func toMovieLogResponse(logEntry db.ListMovieLogByIDsRow, bandSizes rankBandSizes) MovieLogResponse {
 	return MovieLogResponse{
 		LogID:         logEntry.LogID,
 		UserID:        logEntry.UserID,
@@ -67,7 +114,227 @@ func toMovieLogResponse(logEntry db.ListMovieLogByUserRow) MovieLogResponse {
 		WatchedOn:     dateISO(logEntry.WatchedOn),
 		Note:          textPtr(logEntry.Note),
 		RankPosition:  int4Ptr(logEntry.RankPosition),
+		Sentiment:     textPtr(logEntry.Sentiment),
+		Score:         bandSizes.score(logEntry.Sentiment, logEntry.RankPosition),
+		Rating:        numericFloatPtr(logEntry.Rating),
+		ViewingCount:  logEntry.ViewingCount,
+		Tags:          tagNames(logEntry.Tags),
+		LikeCount:     logEntry.LikeCount,
+		CommentCount:  logEntry.CommentCount,
+		LikedByMe:     logEntry.LikedByMe,
+		Visibility:    textPtr(logEntry.Visibility),
+		Version:       logEntry.Version,
 		CreatedAt:     timestamptzRFC3339(logEntry.CreatedAt),
 		UpdatedAt:     timestamptzRFC3339(logEntry.UpdatedAt),
 	}
 }
+
+func toMovieLogViewingResponse(viewing db.MovieLogViewing) MovieLogViewingResponse {
+	return MovieLogViewingResponse{
+		ViewingID: viewing.ID,
+		LogID:     viewing.LogID,
+		WatchedOn: dateISO(viewing.WatchedOn),
+		Note:      textPtr(viewing.Note),
+		CreatedAt: timestamptzRFC3339(viewing.CreatedAt),
+		UpdatedAt: timestamptzRFC3339(viewing.UpdatedAt),
+	}
+}
+
+func toRankEntryResponse(entry db.ListRankedMovieLogByUserRow) RankEntryResponse {
+	return RankEntryResponse{
+		LogID:         entry.LogID,
+		MovieID:       entry.MovieID,
+		OriginalTitle: entry.OriginalTitle,
+		RankPosition:  int4Ptr(entry.RankPosition),
+		Sentiment:     textPtr(entry.Sentiment),
+		Score:         rankScore(entry.Sentiment, entry.RankPosition, entry.BandPosition, entry.BandSize),
+	}
+}
+
+func toLogImportResponse(logImport db.LogImport) LogImportResponse {
+	return LogImportResponse{
+		ImportID:       logImport.ID,
+		Source:         logImport.Source,
+		Status:         logImport.Status,
+		FilmCount:      logImport.FilmCount,
+		ProcessedCount: logImport.ProcessedCount,
+		ImportedCount:  logImport.ImportedCount,
+		QueuedCount:    logImport.QueuedCount,
+		ExistingCount:  logImport.ExistingCount,
+		RankedCount:    logImport.RankedCount,
+		Error:          textPtr(logImport.Error),
+		CreatedAt:      timestamptzRFC3339(logImport.CreatedAt),
+		FinishedAt:     timePtrRFC3339(logImport.FinishedAt.Time),
+	}
+}
+
+// toLogImportReviewResponse expands the review's candidate ids with titles
+// from movieTitles. Candidates that have since left movie_ids are dropped.
+func toLogImportReviewResponse(review db.LogImportReview, movieTitles map[int32]string) LogImportReviewResponse {
+	watchedDates := make([]string, len(review.WatchedDates))
+	for i, watchedOn := range review.WatchedDates {
+		watchedDates[i] = dateISO(watchedOn)
+	}
+
+	candidates := make([]ImportCandidateResponse, 0, len(review.CandidateMovieIds))
+	for _, movieID := range review.CandidateMovieIds {
+		title, ok := movieTitles[movieID]
+		if !ok {
+			continue
+		}
+		candidates = append(candidates, ImportCandidateResponse{
+			MovieID:       movieID,
+			OriginalTitle: title,
+		})
+	}
+
+	return LogImportReviewResponse{
+		ReviewID:     review.ID,
+		ImportID:     review.ImportID,
+		Title:        review.Title,
+		ReleaseYear:  int4Ptr(review.ReleaseYear),
+		WatchedDates: watchedDates,
+		Rating:       numericFloatPtr(review.Rating),
+		Review:       textPtr(review.Review),
+		Candidates:   candidates,
+		Status:       review.Status,
+		MovieID:      int4Ptr(review.MovieID),
+		LogID:        int8Ptr(review.LogID),
+		CreatedAt:    timestamptzRFC3339(review.CreatedAt),
+		UpdatedAt:    timestamptzRFC3339(review.UpdatedAt),
+	}
+}
+
+func toMovieLogExportEntry(row db.ExportMovieLogPageRow, bandSizes rankBandSizes) MovieLogExportEntry {
+	return MovieLogExportEntry{
+		LogID:         row.LogID,
+		TMDBID:        row.MovieID,
+		OriginalTitle: row.OriginalTitle,
+		WatchedOn:     dateISO(row.WatchedOn),
+		Note:          textPtr(row.Note),
+		RankPosition:  int4Ptr(row.RankPosition),
+		Sentiment:     textPtr(row.Sentiment),
+		Score:         bandSizes.score(row.Sentiment, row.RankPosition),
+		Rating:        numericFloatPtr(row.Rating),
+		ViewingCount:  row.ViewingCount,
+		Tags:          tagNames(row.Tags),
+		CreatedAt:     timestamptzRFC3339(row.CreatedAt),
+		UpdatedAt:     timestamptzRFC3339(row.UpdatedAt),
+	}
+}
+
+func toFriendUserResponse(userID int64, username string, displayName, avatarURL pgtype.Text, since pgtype.Timestamptz) FriendUserResponse {
+	return FriendUserResponse{
+		UserID:      userID,
+		Username:    username,
+		DisplayName: textPtr(displayName),
+		AvatarURL:   textPtr(avatarURL),
+		Since:       timestamptzRFC3339(since),
+	}
+}
+
+func toFriendshipResponse(friendship db.Friendship) FriendshipResponse {
+	return FriendshipResponse{
+		FollowerID: friendship.FollowerID,
+		FolloweeID: friendship.FolloweeID,
+		Status:     friendship.Status,
+		CreatedAt:  timestamptzRFC3339(friendship.CreatedAt),
+		UpdatedAt:  timestamptzRFC3339(friendship.UpdatedAt),
+	}
+}
+
+func toFeedEventResponse(row db.ListFeedEventsRow) FeedEventResponse {
+	var watchedOn *string
+	if row.WatchedOn.Valid {
+		value := dateISO(row.WatchedOn)
+		watchedOn = &value
+	}
+
+	return FeedEventResponse{
+		EventID:       row.ID,
+		LogID:         row.LogID,
+		MovieID:       row.MovieID,
+		OriginalTitle: row.OriginalTitle,
+		Note:          textPtr(row.Note),
+		Rating:        numericFloatPtr(row.Rating),
+		Sentiment:     textPtr(row.Sentiment),
+		RankPosition:  int4Ptr(row.RankPosition),
+		WatchedOn:     watchedOn,
+		CreatedAt:     timestamptzRFC3339(row.CreatedAt),
+	}
+}
+
+func toLogCommentThreadResponse(row db.ListLogCommentsRow) LogCommentResponse {
+	response := toLogCommentResponse(db.LogComment{
+		ID:        row.ID,
+		LogID:     row.LogID,
+		UserID:    row.UserID,
+		ParentID:  row.ParentID,
+		Body:      row.Body,
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+	})
+	response.Author = &LogCommentAuthorResponse{
+		UserID:      row.UserID,
+		Username:    row.Username,
+		DisplayName: textPtr(row.DisplayName),
+		AvatarURL:   textPtr(row.AvatarUrl),
+	}
+	return response
+}
+
+func toLogCommentResponse(comment db.LogComment) LogCommentResponse {
+	return LogCommentResponse{
+		CommentID: comment.ID,
+		LogID:     comment.LogID,
+		ParentID:  int8Ptr(comment.ParentID),
+		UserID:    comment.UserID,
+		Body:      comment.Body,
+		Edited:    comment.UpdatedAt.Time.After(comment.CreatedAt.Time),
+		Replies:   []LogCommentResponse{},
+		CreatedAt: timestamptzRFC3339(comment.CreatedAt),
+		UpdatedAt: timestamptzRFC3339(comment.UpdatedAt),
+	}
+}
+
+func toLogLikeResponse(row db.ListMovieLogLikesRow) LogLikeResponse {
+	return LogLikeResponse{
+		UserID:      row.ID,
+		Username:    row.Username,
+		DisplayName: textPtr(row.DisplayName),
+		AvatarURL:   textPtr(row.AvatarUrl),
+		LikedAt:     timestamptzRFC3339(row.CreatedAt),
+	}
+}
+
+func toNotificationResponse(row db.ListNotificationsRow) NotificationResponse {
+	return NotificationResponse{
+		NotificationID: row.ID,
+		Kind:           row.Kind,
+		Actor: FeedActorResponse{
+			UserID:      row.ActorID,
+			Username:    row.Username,
+			DisplayName: textPtr(row.DisplayName),
+			AvatarURL:   textPtr(row.AvatarUrl),
+		},
+		LogID:         int8Ptr(row.LogID),
+		MovieID:       int4Ptr(row.MovieID),
+		OriginalTitle: textPtr(row.OriginalTitle),
+		CommentID:     int8Ptr(row.CommentID),
+		CommentBody:   textPtr(row.CommentBody),
+		Read:          row.ReadAt.Valid,
+		ReadAt:        timePtrRFC3339(row.ReadAt.Time),
+		CreatedAt:     timestamptzRFC3339(row.CreatedAt),
+	}
+}
+
+func toAPITokenResponse(token db.ApiToken) APITokenResponse {
+	return APITokenResponse{
+		TokenID:    token.ID,
+		Name:       token.Name,
+		Scopes:     token.Scopes,
+		ExpiresAt:  timePtrRFC3339(token.ExpiresAt.Time),
+		LastUsedAt: timePtrRFC3339(token.LastUsedAt.Time),
+		CreatedAt:  timestamptzRFC3339(token.CreatedAt),
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	errFollowSelf            = errors.New("users cannot follow themselves")
+	errFollowRequestExists   = errors.New("already following or requested to follow this user")
+	errFollowRequestNotFound = errors.New("follow request not found")
+	errFollowNotFound        = errors.New("not following this user")
+)
+
+// sendFollowRequest asks followeeID to accept followerID as a follower. The
+// follow stays pending until the followee accepts it. Two users are friends
+// once each has accepted the other.
+func sendFollowRequest(ctx context.Context, queries *db.Queries, followerID, followeeID int64) (db.Friendship, error) {
+	if followerID == followeeID {
+		return db.Friendship{}, errFollowSelf
+	}
+
+	for _, userID := range []int64{followerID, followeeID} {
+		userExists, err := queries.UserExists(ctx, userID)
+		if err != nil {
+			return db.Friendship{}, fmt.Errorf("user exists: %w", err)
+		}
+		if !userExists {
+			return db.Friendship{}, errUserNotFound
+		}
+	}
+
+	friendship, err := queries.CreateFollowRequest(ctx, db.CreateFollowRequestParams{
+		FollowerID: followerID,
+		FolloweeID: followeeID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.Friendship{}, errFollowRequestExists
+		}
+		return db.Friendship{}, fmt.Errorf("create follow request: %w", err)
+	}
+
+	notify(ctx, queries, db.CreateNotificationParams{
+		UserID:  followeeID,
+		ActorID: followerID,
+		Kind:    "follow_request",
+	})
+	return friendship, nil
+}
+
+// relationshipBetween reports how userID and otherUserID follow each other.
+// Follows are public, but pending requests are only reported when the viewer
+// is one of the two users.
+func relationshipBetween(ctx context.Context, queries *db.Queries, userID, otherUserID int64, viewer pgtype.Int8) (RelationshipResponse, error) {
+	relationship, err := queries.GetRelationship(ctx, db.GetRelationshipParams{
+		UserID:      userID,
+		OtherUserID: otherUserID,
+	})
+	if err != nil {
+		return RelationshipResponse{}, fmt.Errorf("get relationship: %w", err)
+	}
+
+	following := relationship.OutgoingStatus == "accepted"
+	followedBy := relationship.IncomingStatus == "accepted"
+	response := RelationshipResponse{
+		UserID:      userID,
+		OtherUserID: otherUserID,
+		Following:   following,
+		FollowedBy:  followedBy,
+		Friends:     following && followedBy,
+	}
+	if viewer.Valid && (viewer.Int64 == userID || viewer.Int64 == otherUserID) {
+		response.RequestSent = relationship.OutgoingStatus == "pending"
+		response.RequestReceived = relationship.IncomingStatus == "pending"
+	}
+	return response, nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestRelationshipBetweenPendingRequests(t *testing.T) {
+	ctx := context.Background()
+	_, queries := openTestDB(t)
+
+	var ids []int64
+	for _, username := range []string{"requester", "requested", "onlooker"} {
+		user, err := queries.CreateUser(ctx, username)
+		if err != nil {
+			t.Fatalf("create user %s: %v", username, err)
+		}
+		ids = append(ids, user.ID)
+	}
+	requester, requested, onlooker := ids[0], ids[1], ids[2]
+	if _, err := queries.CreateFollowRequest(ctx, db.CreateFollowRequestParams{
+		FollowerID: requester,
+		FolloweeID: requested,
+	}); err != nil {
+		t.Fatalf("create follow request: %v", err)
+	}
+
+	signedIn := func(id int64) pgtype.Int8 { return pgtype.Int8{Int64: id, Valid: true} }
+	tests := []struct {
+		name        string
+		viewer      pgtype.Int8
+		wantPending bool
+	}{
+		{"requester", signedIn(requester), true},
+		{"requested", signedIn(requested), true},
+		{"onlooker", signedIn(onlooker), false},
+		{"anonymous", pgtype.Int8{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relationship, err := relationshipBetween(ctx, queries, requester, requested, tt.viewer)
+			if err != nil {
+				t.Fatalf("relationship: %v", err)
+			}
+			if relationship.RequestSent != tt.wantPending || relationship.RequestReceived {
+				t.Errorf("request_sent = %v, request_received = %v, want %v and false",
+					relationship.RequestSent, relationship.RequestReceived, tt.wantPending)
+			}
+			if relationship.Following || relationship.FollowedBy {
+				t.Errorf("pending request reported as a follow: %+v", relationship)
+			}
+		})
+	}
+}
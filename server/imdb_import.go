@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// maxIMDbExportSize bounds the uploaded ratings CSV, which is read into
+// memory.
+const maxIMDbExportSize = 16 << 20
+
+// imdbImportBatchSize is how many films each transaction of a background
+// IMDb import handles. Progress is saved after every batch.
+const imdbImportBatchSize = 100
+
+var (
+	errIMDbExportInvalid = errors.New("file is not an IMDb ratings export")
+	errLogImportRunning  = errors.New("an import is already running")
+	errLogImportNotFound = errors.New("import not found")
+)
+
+// imdbTitleTypes are the IMDb title types that can be logged as movies.
+// Series, episodes and games in the export are skipped.
+var imdbTitleTypes = map[string]bool{
+	"movie":     true,
+	"tvmovie":   true,
+	"video":     true,
+	"short":     true,
+	"tvshort":   true,
+	"tvspecial": true,
+}
+
+// imdbRatedFilm is one row of an IMDb ratings export. Rating is the user's
+// own 1-10 rating; IMDbRating is the site-wide average, used to break ties
+// when seeding a ranking.
+type imdbRatedFilm struct {
+	film       importedFilm
+	imdbID     string
+	rating     int
+	imdbRating float64
+}
+
+// parseIMDbRatings reads IMDb's ratings export CSV. Ratings are halved onto
+// the 0.5-5.0 log scale and the rating date becomes the viewing date.
+func parseIMDbRatings(data []byte) ([]imdbRatedFilm, error) {
+	rows, err := readCSVRows(bytes.NewReader(data))
+	if err != nil || len(rows) == 0 {
+		return nil, errIMDbExportInvalid
+	}
+
+	var films []imdbRatedFilm
+	for _, row := range rows {
+		if _, ok := row["Const"]; !ok {
+			return nil, errIMDbExportInvalid
+		}
+		if _, ok := row["Your Rating"]; !ok {
+			return nil, errIMDbExportInvalid
+		}
+
+		imdbID := strings.TrimSpace(row["Const"])
+		titleType := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(row["Title Type"]), " ", ""))
+		if imdbID == "" || (titleType != "" && !imdbTitleTypes[titleType]) {
+			continue
+		}
+
+		rating, err := strconv.Atoi(strings.TrimSpace(row["Your Rating"]))
+		if err != nil || rating < 1 || rating > 10 {
+			continue
+		}
+		halfStars := float64(rating) / 2
+
+		// movie_ids stores original titles, so prefer that column when the
+		// export has it.
+		title := strings.TrimSpace(row["Original Title"])
+		if title == "" {
+			title = strings.TrimSpace(row["Title"])
+		}
+		year, _ := strconv.Atoi(strings.TrimSpace(row["Year"]))
+		imdbRating, _ := strconv.ParseFloat(strings.TrimSpace(row["IMDb Rating"]), 64)
+
+		film := importedFilm{
+			Title:  title,
+			Year:   int32(year),
+			Rating: &halfStars,
+		}
+		if ratedOn, err := time.Parse("2006-01-02", strings.TrimSpace(row["Date Rated"])); err == nil {
+			film.WatchedDates = []time.Time{ratedOn}
+		}
+
+		films = append(films, imdbRatedFilm{
+			film:       film,
+			imdbID:     imdbID,
+			rating:     rating,
+			imdbRating: imdbRating,
+		})
+	}
+	return films, nil
+}
+
+// imdbSentiment maps an IMDb rating onto a ranking band.
+func imdbSentiment(rating int) string {
+	switch {
+	case rating >= 7:
+		return "liked"
+	case rating >= 5:
+		return "fine"
+	default:
+		return "disliked"
+	}
+}
+
+// startIMDbImport records a running import for the user, refusing to start a
+// second one while another is still running.
+func startIMDbImport(ctx context.Context, pool *pgxpool.Pool, queries *db.Queries, source auditSource, userID int64, films []imdbRatedFilm) (db.LogImport, error) {
+	var logImport db.LogImport
+	err := withUserTx(ctx, pool, queries, userID, func(qtx *db.Queries) error {
+		running, err := qtx.RunningLogImportExists(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if running {
+			return errLogImportRunning
+		}
+
+		logImport, err = qtx.CreateLogImport(ctx, db.CreateLogImportParams{
+			UserID:    userID,
+			Source:    "imdb",
+			FilmCount: int32(len(films)),
+		})
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, qtx, source, auditEvent{
+			Action:     auditImportStart,
+			TargetType: "log_import",
+			TargetID:   auditTarget(logImport.ID),
+			After:      logImport,
+		})
+	})
+	return logImport, err
+}
+
+// runIMDbImport logs the films of a started import in batches, saving
+// progress after each one. Films are matched by IMDb id through
+// imdb_movie_ids first and by title otherwise. With seedRanking, films are
+// handled best-rated first and each new entry is appended to the end of its
+// sentiment band, so the new entries rank in IMDb rating order below
+// anything the user had already ranked.
+func runIMDbImport(ctx context.Context, pool *pgxpool.Pool, queries *db.Queries, importID, userID int64, films []imdbRatedFilm, seedRanking bool) (db.LogImport, error) {
+	if seedRanking {
+		sort.SliceStable(films, func(i, j int) bool {
+			if films[i].rating != films[j].rating {
+				return films[i].rating > films[j].rating
+			}
+			return films[i].imdbRating > films[j].imdbRating
+		})
+	}
+
+	var counts logImportCounts
+	for start := 0; start < len(films); start += imdbImportBatchSize {
+		batch := films[start:min(start+imdbImportBatchSize, len(films))]
+
+		err := withUserTx(ctx, pool, queries, userID, func(qtx *db.Queries) error {
+			batchCounts := counts
+			if err := importIMDbBatch(ctx, qtx, importID, userID, batch, seedRanking, &batchCounts); err != nil {
+				return err
+			}
+			if err := qtx.UpdateLogImportProgress(ctx, db.UpdateLogImportProgressParams{
+				ProcessedCount: batchCounts.processed,
+				ImportedCount:  batchCounts.imported,
+				QueuedCount:    batchCounts.queued,
+				ExistingCount:  batchCounts.existing,
+				RankedCount:    batchCounts.ranked,
+				ID:             importID,
+			}); err != nil {
+				return fmt.Errorf("save import progress: %w", err)
+			}
+			counts = batchCounts
+			return nil
+		})
+		if err != nil {
+			return db.LogImport{}, err
+		}
+	}
+
+	return finishLogImport(ctx, queries, importID, counts)
+}
+
+func importIMDbBatch(ctx context.Context, qtx *db.Queries, importID, userID int64, batch []imdbRatedFilm, seedRanking bool, counts *logImportCounts) error {
+	imdbIDs := make([]string, len(batch))
+	for i, film := range batch {
+		imdbIDs[i] = film.imdbID
+	}
+	mappings, err := qtx.ListMovieIDsByIMDbIDs(ctx, imdbIDs)
+	if err != nil {
+		return fmt.Errorf("map imdb ids: %w", err)
+	}
+	movieIDs := make(map[string]int32, len(mappings))
+	for _, mapping := range mappings {
+		movieIDs[mapping.ImdbID] = mapping.MovieID
+	}
+
+	for _, film := range batch {
+		logID, err := logImportFilm(ctx, qtx, importID, userID, movieIDs[film.imdbID], film.film, counts)
+		if err != nil {
+			return err
+		}
+		if logID == 0 || !seedRanking {
+			continue
+		}
+
+		if _, err := insertAtRank(ctx, qtx, userID, logID, imdbSentiment(film.rating), math.MaxInt32); err != nil {
+			return err
+		}
+		counts.ranked++
+	}
+	return nil
+}
+
+// runIMDbImportInBackground runs a started import to completion, recording a
+// failure on the import so its status does not stay running. Either way the
+// outcome is added to the audit log under the request that started it.
+func runIMDbImportInBackground(pool *pgxpool.Pool, queries *db.Queries, source auditSource, logImport db.LogImport, films []imdbRatedFilm, seedRanking bool) {
+	ctx := context.Background()
+	log.Printf("imdb import started: import_id=%d user_id=%d films=%d", logImport.ID, logImport.UserID, len(films))
+
+	finished, err := runIMDbImport(ctx, pool, queries, logImport.ID, logImport.UserID, films, seedRanking)
+	if err != nil {
+		log.Printf("imdb import failed: import_id=%d err=%v", logImport.ID, err)
+		message := err.Error()
+		if err := queries.FailLogImport(ctx, db.FailLogImportParams{
+			Error: trimmedText(&message),
+			ID:    logImport.ID,
+		}); err != nil {
+			log.Printf("record imdb import failure error: %v", err)
+		}
+		finished, err = queries.GetLogImport(ctx, db.GetLogImportParams{
+			ID:     logImport.ID,
+			UserID: logImport.UserID,
+		})
+		if err != nil {
+			log.Printf("get failed imdb import error: %v", err)
+			return
+		}
+	} else {
+		log.Printf("imdb import succeeded: import_id=%d", logImport.ID)
+	}
+
+	recordAuditOrLog(queries, source, auditEvent{
+		Action:     auditImportFinish,
+		TargetType: "log_import",
+		TargetID:   auditTarget(logImport.ID),
+		After:      finished,
+	})
+}
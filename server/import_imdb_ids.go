@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var errNoIMDbIDFiles = errors.New("no imdb_ids*.csv files found")
+
+const createIMDbIDImportStagingSQL = `
+CREATE TEMP TABLE imdb_movie_ids_import_staging (
+	imdb_id  TEXT    NOT NULL,
+	movie_id INTEGER NOT NULL
+) ON COMMIT DROP
+`
+
+// Mappings to movies missing from movie_ids are dropped rather than failing
+// the whole load, since the two datasets are refreshed independently.
+const mergeIMDbIDImportStagingSQL = `
+INSERT INTO imdb_movie_ids (imdb_id, movie_id)
+SELECT DISTINCT ON (s.imdb_id) s.imdb_id, s.movie_id
+FROM imdb_movie_ids_import_staging s
+JOIN movie_ids mi ON mi.id = s.movie_id
+ORDER BY s.imdb_id
+ON CONFLICT (imdb_id) DO UPDATE
+SET movie_id = EXCLUDED.movie_id
+`
+
+func findLatestIMDbIDsCSV(dataDir string) (string, error) {
+	latestPath, err := findLatestDataFile(dataDir, "imdb_ids", ".csv")
+	if err != nil {
+		return "", err
+	}
+	if latestPath == "" {
+		return "", errNoIMDbIDFiles
+	}
+	return latestPath, nil
+}
+
+func copyIMDbIDChunk(ctx context.Context, tx pgx.Tx, rows [][]any) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	_, err := tx.CopyFrom(
+		ctx,
+		pgx.Identifier{"imdb_movie_ids_import_staging"},
+		[]string{"imdb_id", "movie_id"},
+		pgx.CopyFromRows(rows),
+	)
+	if err != nil {
+		return fmt.Errorf("copy to staging table failed: %w", err)
+	}
+	return nil
+}
+
+// runIMDbIDsImport loads a CSV with imdb_id and tmdb_id columns into
+// imdb_movie_ids, which the IMDb ratings importer uses to resolve tt ids.
+func runIMDbIDsImport(ctx context.Context, pool *pgxpool.Pool, sourcePath string, state *movieImportJobState) error {
+	file, err := os.Open(sourcePath)
+	if err != nil {
+		return fmt.Errorf("open import file: %w", err)
+	}
+	defer file.Close()
+
+	csvReader := csv.NewReader(file)
+	csvReader.FieldsPerRecord = -1
+	csvReader.ReuseRecord = true
+
+	header, err := csvReader.Read()
+	if err != nil {
+		return fmt.Errorf("read header: %w", err)
+	}
+	imdbColumn, tmdbColumn := -1, -1
+	for i, column := range header {
+		switch strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")) {
+		case "imdb_id":
+			imdbColumn = i
+		case "tmdb_id":
+			tmdbColumn = i
+		}
+	}
+	if imdbColumn < 0 || tmdbColumn < 0 {
+		return errors.New("header must include imdb_id and tmdb_id columns")
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, createIMDbIDImportStagingSQL); err != nil {
+		return fmt.Errorf("create staging table: %w", err)
+	}
+
+	var processedRows int64
+	lineNumber := 1
+	copyRows := make([][]any, 0, importCopyBatchSize)
+
+	for {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		lineNumber++
+		if err != nil {
+			return fmt.Errorf("line %d: invalid CSV: %w", lineNumber, err)
+		}
+		if len(record) <= imdbColumn || len(record) <= tmdbColumn {
+			continue
+		}
+
+		imdbID := strings.TrimSpace(record[imdbColumn])
+		rawMovieID := strings.TrimSpace(record[tmdbColumn])
+		if imdbID == "" || rawMovieID == "" {
+			continue
+		}
+		processedRows++
+
+		if !strings.HasPrefix(imdbID, "tt") {
+			return fmt.Errorf("line %d: imdb_id must start with tt", lineNumber)
+		}
+		movieID, err := strconv.ParseInt(rawMovieID, 10, 32)
+		if err != nil || movieID <= 0 {
+			return fmt.Errorf("line %d: tmdb_id must be a positive integer", lineNumber)
+		}
+
+		copyRows = append(copyRows, []any{imdbID, int32(movieID)})
+
+		if len(copyRows) >= importCopyBatchSize {
+			if err := copyIMDbIDChunk(ctx, tx, copyRows); err != nil {
+				return fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+			copyRows = copyRows[:0]
+			state.updateProgress(processedRows, 0)
+		}
+	}
+
+	if err := copyIMDbIDChunk(ctx, tx, copyRows); err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(ctx, mergeIMDbIDImportStagingSQL)
+	if err != nil {
+		return fmt.Errorf("merge staging table into imdb_movie_ids: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	state.updateProgress(processedRows, tag.RowsAffected())
+	return nil
+}
@@ -122,6 +122,19 @@ func (s *movieImportJobState) isRunning() bool {
 }
 
 func findLatestMovieIDsGZ(dataDir string) (string, error) {
+	latestPath, err := findLatestDataFile(dataDir, "", ".json.gz")
+	if err != nil {
+		return "", err
+	}
+	if latestPath == "" {
+		return "", errNoMovieIDFiles
+	}
+	return latestPath, nil
+}
+
+// findLatestDataFile returns the most recently modified file in dataDir whose
+// name has the given prefix and suffix, or "" when there is none.
+func findLatestDataFile(dataDir, prefix, suffix string) (string, error) {
 	entries, err := os.ReadDir(dataDir)
 	if err != nil {
 		return "", fmt.Errorf("read data directory: %w", err)
@@ -133,7 +146,7 @@ func findLatestMovieIDsGZ(dataDir string) (string, error) {
 	found := false
 
 	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json.gz") {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), prefix) || !strings.HasSuffix(entry.Name(), suffix) {
 			continue
 		}
 
@@ -150,10 +163,6 @@ func findLatestMovieIDsGZ(dataDir string) (string, error) {
 		}
 	}
 
-	if !found {
-		return "", errNoMovieIDFiles
-	}
-
 	return latestPath, nil
 }
 
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxLetterboxdExportSize bounds the uploaded ZIP, which is read into memory.
+const maxLetterboxdExportSize = 32 << 20
+
+// maxLetterboxdCSVSize bounds each CSV once decompressed, so a small ZIP
+// cannot expand into far more than it was allowed to upload.
+const maxLetterboxdCSVSize = 16 << 20
+
+var (
+	errLetterboxdExportEmpty = errors.New("zip does not contain a Letterboxd diary, watched, ratings or reviews file")
+	errLetterboxdCSVTooLarge = errors.New("a file in the export is too large")
+)
+
+// letterboxdFilm accumulates everything an export says about one film. The
+// same film shows up in several files, keyed by name and year.
+type letterboxdFilm struct {
+	film         importedFilm
+	dates        map[string]bool
+	fallbackDate string
+	diaryRating  *float64
+	rated        bool
+	reviews      []string
+}
+
+// parseLetterboxdExport reads diary.csv, watched.csv, ratings.csv and
+// reviews.csv from a Letterboxd export ZIP and folds them into one
+// importedFilm per film. Files under deleted/ or orphaned/ are ignored.
+func parseLetterboxdExport(data []byte) ([]importedFilm, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("open zip: %w", err)
+	}
+
+	files := make(map[string]*zip.File)
+	for _, file := range archive.File {
+		dir, name := path.Split(file.Name)
+		if strings.Contains("/"+dir, "/deleted/") || strings.Contains("/"+dir, "/orphaned/") {
+			continue
+		}
+		if _, seen := files[name]; !seen {
+			files[name] = file
+		}
+	}
+
+	films := make(map[string]*letterboxdFilm)
+	var order []string
+	filmFor := func(row map[string]string) *letterboxdFilm {
+		title := strings.TrimSpace(row["Name"])
+		year, _ := strconv.Atoi(strings.TrimSpace(row["Year"]))
+		key := fmt.Sprintf("%s|%d", strings.ToLower(title), year)
+		entry, ok := films[key]
+		if !ok {
+			entry = &letterboxdFilm{
+				film:  importedFilm{Title: title, Year: int32(year)},
+				dates: make(map[string]bool),
+			}
+			films[key] = entry
+			order = append(order, key)
+		}
+		return entry
+	}
+
+	found := false
+	for _, name := range []string{"watched.csv", "diary.csv", "ratings.csv", "reviews.csv"} {
+		file, ok := files[name]
+		if !ok {
+			continue
+		}
+		found = true
+
+		rows, err := readLetterboxdCSV(file)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", name, err)
+		}
+
+		for _, row := range rows {
+			if strings.TrimSpace(row["Name"]) == "" {
+				continue
+			}
+			entry := filmFor(row)
+
+			switch name {
+			case "watched.csv":
+				entry.fallbackDate = row["Date"]
+			case "diary.csv":
+				entry.addDate(row["Watched Date"], row["Date"])
+				if rating := parseLetterboxdRating(row["Rating"]); rating != nil {
+					entry.diaryRating = rating
+				}
+			case "ratings.csv":
+				if rating := parseLetterboxdRating(row["Rating"]); rating != nil {
+					entry.film.Rating = rating
+					entry.rated = true
+				}
+			case "reviews.csv":
+				entry.addDate(row["Watched Date"], row["Date"])
+				if review := strings.TrimSpace(row["Review"]); review != "" {
+					entry.reviews = append(entry.reviews, review)
+				}
+			}
+		}
+	}
+	if !found {
+		return nil, errLetterboxdExportEmpty
+	}
+
+	result := make([]importedFilm, 0, len(order))
+	for _, key := range order {
+		result = append(result, films[key].finish())
+	}
+	return result, nil
+}
+
+func (f *letterboxdFilm) addDate(watchedDate, loggedDate string) {
+	date := strings.TrimSpace(watchedDate)
+	if date == "" {
+		date = strings.TrimSpace(loggedDate)
+	}
+	if date != "" {
+		f.dates[date] = true
+	}
+}
+
+func (f *letterboxdFilm) finish() importedFilm {
+	if len(f.dates) == 0 && strings.TrimSpace(f.fallbackDate) != "" {
+		f.dates[strings.TrimSpace(f.fallbackDate)] = true
+	}
+	for raw := range f.dates {
+		watchedOn, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			continue
+		}
+		f.film.WatchedDates = append(f.film.WatchedDates, watchedOn)
+	}
+	sort.Slice(f.film.WatchedDates, func(i, j int) bool {
+		return f.film.WatchedDates[i].Before(f.film.WatchedDates[j])
+	})
+
+	if !f.rated {
+		f.film.Rating = f.diaryRating
+	}
+	f.film.Review = strings.Join(f.reviews, "\n\n")
+	return f.film
+}
+
+// readLetterboxdCSV returns each data row keyed by its header column. The
+// declared size is checked first, and the read is capped as well because the
+// header can lie.
+func readLetterboxdCSV(file *zip.File) ([]map[string]string, error) {
+	if file.UncompressedSize64 > maxLetterboxdCSVSize {
+		return nil, errLetterboxdCSVTooLarge
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(io.LimitReader(reader, maxLetterboxdCSVSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxLetterboxdCSVSize {
+		return nil, errLetterboxdCSVTooLarge
+	}
+
+	return readCSVRows(bytes.NewReader(data))
+}
+
+func parseLetterboxdRating(raw string) *float64 {
+	rating, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	if err != nil || rating <= 0 {
+		return nil
+	}
+	return &rating
+}
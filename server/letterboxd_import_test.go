@@ -0,0 +1,62 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// letterboxdZip builds an export ZIP holding the given files.
+func letterboxdZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for name, content := range files {
+		file, err := writer.Create(name)
+		if err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+		if _, err := file.Write([]byte(content)); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestParseLetterboxdExport(t *testing.T) {
+	const diaryHeader = "Date,Name,Year,Letterboxd URI,Rating,Rewatch,Tags,Watched Date\n"
+	const diaryRow = "2024-01-02,Heat,1995,https://boxd.it/x,4.5,,,2024-01-01\n"
+
+	t.Run("diary", func(t *testing.T) {
+		films, err := parseLetterboxdExport(letterboxdZip(t, map[string]string{
+			"diary.csv": diaryHeader + diaryRow,
+		}))
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		if len(films) != 1 || films[0].Title != "Heat" || films[0].Year != 1995 || len(films[0].WatchedDates) != 1 {
+			t.Errorf("films = %+v", films)
+		}
+	})
+
+	t.Run("oversized entry", func(t *testing.T) {
+		// The rows compress to a small fraction of the cap, so only the
+		// decompressed size can catch them.
+		diary := diaryHeader + strings.Repeat(diaryRow, maxLetterboxdCSVSize/len(diaryRow)+1)
+		data := letterboxdZip(t, map[string]string{"diary.csv": diary})
+		if len(data) > maxLetterboxdExportSize {
+			t.Fatalf("zip is %d bytes, over the upload limit", len(data))
+		}
+
+		_, err := parseLetterboxdExport(data)
+		if !errors.Is(err, errLetterboxdCSVTooLarge) {
+			t.Errorf("err = %v, want %v", err, errLetterboxdCSVTooLarge)
+		}
+	})
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+)
+
+// exportPageSize is how many log entries an export reads and flushes at a
+// time, which bounds its memory use however long the log is.
+const exportPageSize = 500
+
+// movieLogExportFormats maps each export format to its content type and file
+// extension.
+var movieLogExportFormats = map[string][2]string{
+	"letterboxd": {"text/csv; charset=utf-8", "csv"},
+	"csv":        {"text/csv; charset=utf-8", "csv"},
+	"json":       {"application/json; charset=utf-8", "json"},
+}
+
+// letterboxdExportHeader uses the column names Letterboxd's CSV importer
+// recognizes. tmdbID lets it match films without relying on titles.
+var letterboxdExportHeader = []string{"tmdbID", "Title", "WatchedDate", "Rewatch", "Rating", "Tags", "Review"}
+
+var csvExportHeader = []string{
+	"log_id", "tmdb_id", "original_title", "watched_on", "rating", "sentiment",
+	"rank_position", "score", "viewing_count", "tags", "note", "created_at", "updated_at",
+}
+
+// movieLogExporter writes one export format. flush pushes buffered rows out
+// after every page so the response streams instead of holding the whole log.
+type movieLogExporter interface {
+	begin() error
+	entry(entry MovieLogExportEntry) error
+	flush() error
+	end() error
+}
+
+func newMovieLogExporter(format string, w io.Writer) movieLogExporter {
+	switch format {
+	case "letterboxd":
+		return &csvMovieLogExporter{w: csv.NewWriter(w), header: letterboxdExportHeader, records: letterboxdExportRecords}
+	case "csv":
+		return &csvMovieLogExporter{w: csv.NewWriter(w), header: csvExportHeader, records: csvExportRecords}
+	default:
+		return &jsonMovieLogExporter{w: w}
+	}
+}
+
+// streamMovieLogExport pages through the entries of the user's log that access
+// allows in id order and hands each to the exporter, calling flush once each
+// page is written. qtx should read from a single snapshot so pages stay
+// consistent with each other and with bandSizes.
+func streamMovieLogExport(ctx context.Context, qtx *db.Queries, userID int64, access logAccess, bandSizes rankBandSizes, exporter movieLogExporter, flush func()) error {
+	if err := exporter.begin(); err != nil {
+		return err
+	}
+
+	var afterID int64
+	for {
+		rows, err := qtx.ExportMovieLogPage(ctx, db.ExportMovieLogPageParams{
+			ShowNotes:    access.showNotes(),
+			UserID:       userID,
+			AfterID:      afterID,
+			Visibilities: access.visibilities(),
+			PageSize:     exportPageSize,
+		})
+		if err != nil {
+			return fmt.Errorf("export log page: %w", err)
+		}
+
+		viewings, err := exportViewingDates(ctx, qtx, userID, rows)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			entry := toMovieLogExportEntry(row, bandSizes)
+			entry.Viewings = viewings[row.LogID]
+			if err := exporter.entry(entry); err != nil {
+				return err
+			}
+			afterID = row.LogID
+		}
+		if err := exporter.flush(); err != nil {
+			return err
+		}
+		flush()
+
+		if len(rows) < exportPageSize {
+			break
+		}
+	}
+
+	return exporter.end()
+}
+
+// exportViewingDates reads the viewing dates of a page of entries, oldest
+// first, keyed by log id.
+func exportViewingDates(ctx context.Context, qtx *db.Queries, userID int64, rows []db.ExportMovieLogPageRow) (map[int64][]string, error) {
+	logIDs := make([]int64, len(rows))
+	for i, row := range rows {
+		logIDs[i] = row.LogID
+	}
+
+	viewingRows, err := qtx.ListMovieLogViewingDates(ctx, db.ListMovieLogViewingDatesParams{
+		UserID: userID,
+		LogIds: logIDs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("export log viewings: %w", err)
+	}
+
+	viewings := make(map[int64][]string, len(rows))
+	for _, row := range viewingRows {
+		viewings[row.LogID] = append(viewings[row.LogID], dateISO(row.WatchedOn))
+	}
+	return viewings, nil
+}
+
+// csvMovieLogExporter writes the rows records returns for each entry.
+type csvMovieLogExporter struct {
+	w       *csv.Writer
+	header  []string
+	records func(MovieLogExportEntry) [][]string
+}
+
+func (e *csvMovieLogExporter) begin() error {
+	return e.w.Write(e.header)
+}
+
+func (e *csvMovieLogExporter) entry(entry MovieLogExportEntry) error {
+	for _, record := range e.records(entry) {
+		if err := e.w.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *csvMovieLogExporter) flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvMovieLogExporter) end() error {
+	return e.flush()
+}
+
+// letterboxdExportRecords writes one diary row per viewing, oldest first, so
+// every viewing after the first is a rewatch. The note goes on the latest
+// viewing only, since Letterboxd turns each reviewed row into its own review.
+func letterboxdExportRecords(entry MovieLogExportEntry) [][]string {
+	dates := entry.Viewings
+	if len(dates) == 0 {
+		dates = []string{entry.WatchedOn}
+	}
+
+	records := make([][]string, len(dates))
+	for i, watchedOn := range dates {
+		rewatch, review := "No", ""
+		if i > 0 {
+			rewatch = "Yes"
+		}
+		if i == len(dates)-1 {
+			review = stringOrEmpty(entry.Note)
+		}
+		records[i] = []string{
+			strconv.FormatInt(int64(entry.TMDBID), 10),
+			entry.OriginalTitle,
+			watchedOn,
+			rewatch,
+			formatOptionalFloat(entry.Rating),
+			strings.Join(entry.Tags, ", "),
+			review,
+		}
+	}
+	return records
+}
+
+func csvExportRecords(entry MovieLogExportEntry) [][]string {
+	rankPosition := ""
+	if entry.RankPosition != nil {
+		rankPosition = strconv.FormatInt(int64(*entry.RankPosition), 10)
+	}
+
+	return [][]string{{
+		strconv.FormatInt(entry.LogID, 10),
+		strconv.FormatInt(int64(entry.TMDBID), 10),
+		entry.OriginalTitle,
+		entry.WatchedOn,
+		formatOptionalFloat(entry.Rating),
+		stringOrEmpty(entry.Sentiment),
+		rankPosition,
+		formatOptionalFloat(entry.Score),
+		strconv.FormatInt(entry.ViewingCount, 10),
+		strings.Join(entry.Tags, ", "),
+		stringOrEmpty(entry.Note),
+		entry.CreatedAt,
+		entry.UpdatedAt,
+	}}
+}
+
+// jsonMovieLogExporter writes a single JSON array, one element at a time.
+type jsonMovieLogExporter struct {
+	w       io.Writer
+	written bool
+}
+
+func (e *jsonMovieLogExporter) begin() error {
+	_, err := io.WriteString(e.w, "[")
+	return err
+}
+
+func (e *jsonMovieLogExporter) entry(entry MovieLogExportEntry) error {
+	encoded, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if e.written {
+		if _, err := io.WriteString(e.w, ",\n"); err != nil {
+			return err
+		}
+	}
+	e.written = true
+	_, err = e.w.Write(encoded)
+	return err
+}
+
+func (e *jsonMovieLogExporter) flush() error {
+	return nil
+}
+
+func (e *jsonMovieLogExporter) end() error {
+	_, err := io.WriteString(e.w, "]\n")
+	return err
+}
+
+func formatOptionalFloat(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
+
+func stringOrEmpty(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
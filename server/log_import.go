@@ -0,0 +1,323 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// maxImportCandidates caps how many possible matches a review queue item
+// keeps for the user to choose from.
+const maxImportCandidates = 5
+
+var (
+	errImportReviewNotFound = errors.New("import review not found")
+	errImportReviewResolved = errors.New("import review has already been resolved")
+)
+
+// importedFilm is one film read from another service's export, with all of
+// its viewings folded together.
+type importedFilm struct {
+	Title        string
+	Year         int32
+	WatchedDates []time.Time
+	Rating       *float64
+	Review       string
+}
+
+// matchImportedFilm looks a film up in movie_ids by title through the trigram
+// index. movie_ids carries no release dates, so the year cannot narrow the
+// search; a film only matches when exactly one candidate has its exact title.
+// Otherwise the best candidates are returned for the review queue.
+func matchImportedFilm(ctx context.Context, qtx *db.Queries, film importedFilm) (int32, []int32, error) {
+	results, err := qtx.SearchMovies(ctx, film.Title)
+	if err != nil {
+		return 0, nil, fmt.Errorf("search movies: %w", err)
+	}
+
+	var exact []int32
+	candidates := make([]int32, 0, maxImportCandidates)
+	for _, result := range results {
+		if strings.EqualFold(result.OriginalTitle, film.Title) {
+			exact = append(exact, result.ID)
+		}
+		if len(candidates) < maxImportCandidates {
+			candidates = append(candidates, result.ID)
+		}
+	}
+
+	if len(exact) == 1 {
+		return exact[0], nil, nil
+	}
+	if len(exact) > 1 {
+		if len(exact) > maxImportCandidates {
+			exact = exact[:maxImportCandidates]
+		}
+		return 0, exact, nil
+	}
+	return 0, candidates, nil
+}
+
+// importFilmToLog creates a log entry for a matched film with one viewing per
+// watch date. It reports false, without writing anything, when the movie is
+// already in the user's log.
+func importFilmToLog(ctx context.Context, qtx *db.Queries, userID int64, movieID int32, film importedFilm) (int64, bool, error) {
+	watchedDates := film.WatchedDates
+	if len(watchedDates) == 0 {
+		watchedDates = []time.Time{time.Now().UTC()}
+	}
+
+	rating, err := parseRating(film.Rating)
+	if err != nil {
+		rating = pgtype.Numeric{}
+	}
+
+	entry, err := qtx.CreateMovieLogEntry(ctx, db.CreateMovieLogEntryParams{
+		UserID:    userID,
+		MovieID:   movieID,
+		WatchedOn: pgtype.Date{Time: watchedDates[len(watchedDates)-1], Valid: true},
+		Note:      trimmedText(&film.Review),
+		Rating:    rating,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("create log entry: %w", err)
+	}
+
+	for _, watchedOn := range watchedDates {
+		if _, err := qtx.CreateMovieLogViewing(ctx, db.CreateMovieLogViewingParams{
+			LogID:     entry.ID,
+			WatchedOn: pgtype.Date{Time: watchedOn, Valid: true},
+		}); err != nil {
+			return 0, false, fmt.Errorf("create viewing: %w", err)
+		}
+	}
+
+	if _, err := qtx.SyncMovieLogWatchedOn(ctx, entry.ID); err != nil {
+		return 0, false, fmt.Errorf("sync watched_on: %w", err)
+	}
+	return entry.ID, true, nil
+}
+
+// logImportCounts tallies what happened to each film of an import.
+type logImportCounts struct {
+	processed int32
+	imported  int32
+	queued    int32
+	existing  int32
+	ranked    int32
+}
+
+// logImportFilm logs one film, matching it against movie_ids by title unless
+// the caller already resolved movieID. Films without a confident match are
+// queued for review. It returns the id of the entry it created, or 0.
+func logImportFilm(ctx context.Context, qtx *db.Queries, importID, userID int64, movieID int32, film importedFilm, counts *logImportCounts) (int64, error) {
+	counts.processed++
+
+	if movieID == 0 {
+		var candidates []int32
+		var err error
+		movieID, candidates, err = matchImportedFilm(ctx, qtx, film)
+		if err != nil {
+			return 0, err
+		}
+		if movieID == 0 {
+			if err := queueImportedFilm(ctx, qtx, importID, userID, film, candidates); err != nil {
+				return 0, err
+			}
+			counts.queued++
+			return 0, nil
+		}
+	}
+
+	logID, created, err := importFilmToLog(ctx, qtx, userID, movieID, film)
+	if err != nil {
+		return 0, err
+	}
+	if !created {
+		counts.existing++
+		return 0, nil
+	}
+	counts.imported++
+	return logID, nil
+}
+
+// runLogImport matches and logs every film from one export in a single
+// transaction. Confident matches become log entries; everything else is
+// queued for the user to confirm or correct.
+func runLogImport(ctx context.Context, qtx *db.Queries, audit auditSource, userID int64, source string, films []importedFilm) (db.LogImport, error) {
+	logImport, err := qtx.CreateLogImport(ctx, db.CreateLogImportParams{
+		UserID:    userID,
+		Source:    source,
+		FilmCount: int32(len(films)),
+	})
+	if err != nil {
+		return db.LogImport{}, fmt.Errorf("create import: %w", err)
+	}
+	if err := recordAudit(ctx, qtx, audit, auditEvent{
+		Action:     auditImportStart,
+		TargetType: "log_import",
+		TargetID:   auditTarget(logImport.ID),
+		After:      logImport,
+	}); err != nil {
+		return db.LogImport{}, err
+	}
+
+	var counts logImportCounts
+	for _, film := range films {
+		if _, err := logImportFilm(ctx, qtx, logImport.ID, userID, 0, film, &counts); err != nil {
+			return db.LogImport{}, err
+		}
+	}
+
+	logImport, err = finishLogImport(ctx, qtx, logImport.ID, counts)
+	if err != nil {
+		return db.LogImport{}, err
+	}
+	if err := recordAudit(ctx, qtx, audit, auditEvent{
+		Action:     auditImportFinish,
+		TargetType: "log_import",
+		TargetID:   auditTarget(logImport.ID),
+		After:      logImport,
+	}); err != nil {
+		return db.LogImport{}, err
+	}
+	return logImport, nil
+}
+
+func finishLogImport(ctx context.Context, qtx *db.Queries, importID int64, counts logImportCounts) (db.LogImport, error) {
+	logImport, err := qtx.FinishLogImport(ctx, db.FinishLogImportParams{
+		ProcessedCount: counts.processed,
+		ImportedCount:  counts.imported,
+		QueuedCount:    counts.queued,
+		ExistingCount:  counts.existing,
+		RankedCount:    counts.ranked,
+		ID:             importID,
+	})
+	if err != nil {
+		return db.LogImport{}, fmt.Errorf("finish import: %w", err)
+	}
+	return logImport, nil
+}
+
+func queueImportedFilm(ctx context.Context, qtx *db.Queries, importID, userID int64, film importedFilm, candidates []int32) error {
+	watchedDates := make([]pgtype.Date, len(film.WatchedDates))
+	for i, watchedOn := range film.WatchedDates {
+		watchedDates[i] = pgtype.Date{Time: watchedOn, Valid: true}
+	}
+
+	rating, err := parseRating(film.Rating)
+	if err != nil {
+		rating = pgtype.Numeric{}
+	}
+
+	releaseYear := pgtype.Int4{}
+	if film.Year > 0 {
+		releaseYear = pgtype.Int4{Int32: film.Year, Valid: true}
+	}
+
+	if err := qtx.CreateLogImportReview(ctx, db.CreateLogImportReviewParams{
+		ImportID:          importID,
+		UserID:            userID,
+		Title:             film.Title,
+		ReleaseYear:       releaseYear,
+		WatchedDates:      watchedDates,
+		Rating:            rating,
+		Review:            trimmedText(&film.Review),
+		CandidateMovieIds: candidates,
+	}); err != nil {
+		return fmt.Errorf("queue %q for review: %w", film.Title, err)
+	}
+	return nil
+}
+
+// importedFilmFromReview rebuilds the film a review queue item was created
+// from, so confirming it logs the same dates, rating and review.
+func importedFilmFromReview(review db.LogImportReview) importedFilm {
+	film := importedFilm{
+		Title:  review.Title,
+		Year:   review.ReleaseYear.Int32,
+		Rating: numericFloatPtr(review.Rating),
+		Review: review.Review.String,
+	}
+	for _, watchedOn := range review.WatchedDates {
+		film.WatchedDates = append(film.WatchedDates, watchedOn.Time)
+	}
+	sort.Slice(film.WatchedDates, func(i, j int) bool {
+		return film.WatchedDates[i].Before(film.WatchedDates[j])
+	})
+	return film
+}
+
+// importCandidateTitles looks up the titles of every candidate movie across
+// the given review queue items.
+func importCandidateTitles(ctx context.Context, queries *db.Queries, reviews []db.LogImportReview) (map[int32]string, error) {
+	var movieIDs []int32
+	for _, review := range reviews {
+		movieIDs = append(movieIDs, review.CandidateMovieIds...)
+	}
+	if len(movieIDs) == 0 {
+		return map[int32]string{}, nil
+	}
+
+	movies, err := queries.ListMoviesByIDs(ctx, movieIDs)
+	if err != nil {
+		return nil, fmt.Errorf("list candidate movies: %w", err)
+	}
+
+	titles := make(map[int32]string, len(movies))
+	for _, movie := range movies {
+		titles[movie.ID] = movie.OriginalTitle
+	}
+	return titles, nil
+}
+
+// readCSVRows returns each data row of a CSV export keyed by its header
+// column. A leading byte order mark on the header is dropped.
+func readCSVRows(reader io.Reader) ([]map[string]string, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+
+	header, err := csvReader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	var rows []map[string]string
+	for {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]string, len(header))
+		for i, column := range header {
+			if i < len(record) {
+				row[column] = record[i]
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
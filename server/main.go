@@ -31,20 +31,78 @@ func main() {
 	fmt.Println("Connected to database")
 
 	queries := db.New(pool)
+
+	if len(os.Args) > 1 {
+		if err := runCommand(ctx, pool, queries, os.Args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// Background log imports run inside this process, so any still marked
+	// running were cut off by the last shutdown.
+	if interrupted, err := queries.FailInterruptedLogImports(ctx); err != nil {
+		log.Fatalf("unable to clean up interrupted imports: %v", err)
+	} else if interrupted > 0 {
+		log.Printf("marked %d interrupted log imports as failed", interrupted)
+	}
+
 	importState := &movieImportJobState{status: "idle"}
+	imdbIDImportState := &movieImportJobState{status: "idle"}
 	dataDir := resolveDataDir()
+	secureCookies := resolveSecureCookies()
+
+	var oidcLogin *oidcAuth
+	if config, ok := loadOIDCConfig(); ok {
+		if oidcLogin, err = newOIDCAuth(ctx, config); err != nil {
+			log.Fatalf("unable to set up OIDC login: %v", err)
+		}
+	}
+
+	rateLimits, err := loadRateLimitConfig()
+	if err != nil {
+		log.Fatalf("invalid rate limit config: %v", err)
+	}
 
 	e := echo.New()
+	// Only trust X-Forwarded-For from proxies on loopback or private networks,
+	// so clients cannot pick their own rate limit key.
+	e.IPExtractor = echo.ExtractIPFromXFFHeader()
+	e.Use(middleware.RequestID())
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: []string{"http://localhost:3000"},
-		AllowMethods: []string{http.MethodGet, http.MethodPost, http.MethodDelete, http.MethodOptions},
+		AllowOrigins:     []string{"http://localhost:3000"},
+		AllowMethods:     []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions},
+		AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, "If-Match", echo.HeaderAuthorization},
+		ExposeHeaders:    []string{"ETag", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", echo.HeaderRetryAfter},
+		AllowCredentials: true,
 	}))
+	e.Use(authenticate(queries))
+	e.Use(rateLimiter(newRateLimitStore(rateLimits, pool, queries), rateLimits))
+	e.Use(requireAccountOwner)
+	e.Use(requireAdmin)
+
+	registerAuthRoutes(e, queries, pool, secureCookies)
+	registerOIDCRoutes(e, queries, pool, oidcLogin, secureCookies)
+	registerAPITokenRoutes(e, queries)
 
-	registerMovieRoutes(e, queries, pool, importState, dataDir)
-	registerAdminUserRoutes(e, queries)
-	registerMovieLogRoutes(e, queries)
+	registerMovieRoutes(e, queries, pool, importState, imdbIDImportState, dataDir)
+	registerAdminUserRoutes(e, queries, pool)
+	registerMovieLogRoutes(e, queries, pool)
+	registerMovieLogViewingRoutes(e, queries, pool)
+	registerRankingRoutes(e, queries, pool)
+	registerRatingRoutes(e, queries)
+	registerTagRoutes(e, queries, pool)
+	registerLogImportRoutes(e, queries, pool)
+	registerLogExportRoutes(e, queries, pool)
+	registerArchiveRoutes(e, queries, pool)
+	registerFriendRoutes(e, queries)
+	registerFeedRoutes(e, queries)
+	registerCommentRoutes(e, queries)
+	registerNotificationRoutes(e, queries, pool)
+	registerPrivacyRoutes(e, queries)
+	registerCompareRoutes(e, queries)
 
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultMovieLogPageSize = 50
+	maxMovieLogPageSize     = 200
+)
+
+var errInvalidCursor = errors.New("cursor is invalid or does not match sort and order")
+
+// movieLogSortDescending is each sort's default direction. Unranked entries
+// sort after every ranked one when sorting by rank ascending.
+var movieLogSortDescending = map[string]bool{
+	"rank":       false,
+	"title":      false,
+	"watched_on": true,
+	"created_at": true,
+}
+
+// movieLogCursor marks the last entry of a page. It is handed to clients as
+// opaque base64 and only valid for the sort and order it was issued under.
+type movieLogCursor struct {
+	Sort       string `json:"s"`
+	Descending bool   `json:"d"`
+	Value      string `json:"v"`
+	LogID      int64  `json:"id"`
+}
+
+// movieLogListParams describes one page of a log listing.
+type movieLogListParams struct {
+	db.ListMovieLogIDsParams
+	ViewerID pgtype.Int8
+}
+
+// parseMovieLogListParams reads the filter, sort, viewer and cursor query
+// parameters for a log listing. PageSize is set one past limit so the caller
+// can tell whether another page follows.
+func parseMovieLogListParams(c echo.Context, userID int64) (movieLogListParams, int, error) {
+	params := movieLogListParams{}
+	params.UserID = userID
+
+	limit := defaultMovieLogPageSize
+	if raw := c.QueryParam("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxMovieLogPageSize {
+			return params, 0, fmt.Errorf("limit must be between 1 and %d", maxMovieLogPageSize)
+		}
+		limit = parsed
+	}
+	params.PageSize = int32(limit + 1)
+
+	params.SortBy = c.QueryParam("sort")
+	if params.SortBy == "" {
+		params.SortBy = "rank"
+	}
+	descending, ok := movieLogSortDescending[params.SortBy]
+	if !ok {
+		return params, 0, errors.New("sort must be watched_on, created_at, rank or title")
+	}
+	switch c.QueryParam("order") {
+	case "":
+		params.Descending = descending
+	case "asc":
+		params.Descending = false
+	case "desc":
+		params.Descending = true
+	default:
+		return params, 0, errors.New("order must be asc or desc")
+	}
+
+	var err error
+	params.TagNames, err = normalizeTagNames(tagQueryParams(c))
+	if err != nil {
+		return params, 0, err
+	}
+	switch c.QueryParam("tag_match") {
+	case "", "any":
+	case "all":
+		params.MatchAll = true
+	default:
+		return params, 0, errors.New("tag_match must be any or all")
+	}
+
+	if params.WatchedFrom, err = parseOptionalDate(c.QueryParam("watched_from")); err != nil {
+		return params, 0, errors.New("watched_from must be in YYYY-MM-DD format")
+	}
+	if params.WatchedTo, err = parseOptionalDate(c.QueryParam("watched_to")); err != nil {
+		return params, 0, errors.New("watched_to must be in YYYY-MM-DD format")
+	}
+
+	if raw := c.QueryParam("ranked"); raw != "" {
+		ranked, err := strconv.ParseBool(raw)
+		if err != nil {
+			return params, 0, errors.New("ranked must be true or false")
+		}
+		params.Ranked = pgtype.Bool{Bool: ranked, Valid: true}
+	}
+
+	if q := strings.TrimSpace(c.QueryParam("q")); q != "" {
+		params.Search = pgtype.Text{String: "%" + escapeLikePattern(q) + "%", Valid: true}
+	}
+
+	params.ViewerID = viewerID(c)
+
+	if raw := c.QueryParam("cursor"); raw != "" {
+		if err := applyMovieLogCursor(&params, raw); err != nil {
+			return params, 0, err
+		}
+	}
+
+	return params, limit, nil
+}
+
+// applyMovieLogCursor checks a cursor against the listing's sort and order and
+// sets the cursor column for its sort key.
+func applyMovieLogCursor(params *movieLogListParams, raw string) error {
+	decoded, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return errInvalidCursor
+	}
+	var cursor movieLogCursor
+	if err := json.Unmarshal(decoded, &cursor); err != nil {
+		return errInvalidCursor
+	}
+	if cursor.Sort != params.SortBy || cursor.Descending != params.Descending {
+		return errInvalidCursor
+	}
+
+	switch cursor.Sort {
+	case "watched_on":
+		watchedOn, err := parseOptionalDate(cursor.Value)
+		if err != nil || !watchedOn.Valid {
+			return errInvalidCursor
+		}
+		params.CursorWatchedOn = watchedOn
+	case "created_at":
+		createdAt, err := time.Parse(time.RFC3339Nano, cursor.Value)
+		if err != nil {
+			return errInvalidCursor
+		}
+		params.CursorCreatedAt = pgtype.Timestamptz{Time: createdAt, Valid: true}
+	case "rank":
+		rank, err := strconv.ParseInt(cursor.Value, 10, 32)
+		if err != nil {
+			return errInvalidCursor
+		}
+		params.CursorRank = pgtype.Int4{Int32: int32(rank), Valid: true}
+	case "title":
+		params.CursorTitle = pgtype.Text{String: cursor.Value, Valid: true}
+	}
+	params.CursorID = pgtype.Int8{Int64: cursor.LogID, Valid: true}
+	return nil
+}
+
+// listMovieLogPage finds the ids on the page in the listing's sort and order,
+// then loads those entries in that order.
+func listMovieLogPage(ctx context.Context, queries *db.Queries, params movieLogListParams) ([]db.ListMovieLogByIDsRow, error) {
+	ids, err := queries.ListMovieLogIDs(ctx, params.ListMovieLogIDsParams)
+	if err != nil {
+		return nil, fmt.Errorf("list movie log ids: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	rows, err := queries.ListMovieLogByIDs(ctx, db.ListMovieLogByIDsParams{
+		ShowNotes: params.ShowNotes,
+		ViewerID:  params.ViewerID,
+		UserID:    params.UserID,
+		LogIds:    ids,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("load movie log page: %w", err)
+	}
+
+	order := make(map[int64]int, len(ids))
+	for i, id := range ids {
+		order[id] = i
+	}
+	slices.SortFunc(rows, func(a, b db.ListMovieLogByIDsRow) int {
+		return order[a.LogID] - order[b.LogID]
+	})
+	return rows, nil
+}
+
+// encodeMovieLogCursor builds the cursor for the page that starts after row.
+func encodeMovieLogCursor(params movieLogListParams, row db.ListMovieLogByIDsRow) string {
+	cursor := movieLogCursor{
+		Sort:       params.SortBy,
+		Descending: params.Descending,
+		LogID:      row.LogID,
+	}
+	switch params.SortBy {
+	case "watched_on":
+		cursor.Value = dateISO(row.WatchedOn)
+	case "created_at":
+		cursor.Value = row.CreatedAt.Time.UTC().Format(time.RFC3339Nano)
+	case "rank":
+		rank := int64(math.MaxInt32)
+		if row.RankPosition.Valid {
+			rank = int64(row.RankPosition.Int32)
+		}
+		cursor.Value = strconv.FormatInt(rank, 10)
+	case "title":
+		cursor.Value = row.OriginalTitle
+	}
+
+	encoded, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+func parseOptionalDate(raw string) (pgtype.Date, error) {
+	if raw == "" {
+		return pgtype.Date{}, nil
+	}
+	parsed, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return pgtype.Date{}, err
+	}
+	return pgtype.Date{Time: parsed, Valid: true}, nil
+}
+
+// escapeLikePattern makes user input match literally inside an ILIKE pattern.
+func escapeLikePattern(raw string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(raw)
+}
+
+// tagQueryParams collects tag filters from repeated ?tag= parameters, each of
+// which may also hold a comma-separated list.
+func tagQueryParams(c echo.Context) []string {
+	var tags []string
+	for _, value := range c.QueryParams()["tag"] {
+		for _, tag := range strings.Split(value, ",") {
+			if strings.TrimSpace(tag) != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+)
+
+// TestListMovieLogPageCursor pages through a log one entry at a time in
+// every sort and order, and checks the pages add up to the single-page
+// listing. The fixture's entries share a watched_on date and are unranked,
+// so those sorts also exercise the id tiebreaker.
+func TestListMovieLogPageCursor(t *testing.T) {
+	ctx := context.Background()
+	pool, queries := openTestDB(t)
+	fixture := seedPrivacyFixture(t, ctx, pool, queries)
+
+	for sortBy := range movieLogSortDescending {
+		for _, descending := range []bool{false, true} {
+			name := sortBy + "/asc"
+			if descending {
+				name = sortBy + "/desc"
+			}
+			t.Run(name, func(t *testing.T) {
+				newParams := func(pageSize int32) movieLogListParams {
+					return movieLogListParams{ListMovieLogIDsParams: db.ListMovieLogIDsParams{
+						UserID:       fixture.owner,
+						Visibilities: logVisibilities,
+						ShowNotes:    true,
+						SortBy:       sortBy,
+						Descending:   descending,
+						PageSize:     pageSize,
+					}}
+				}
+
+				all, err := listMovieLogPage(ctx, queries, newParams(10))
+				if err != nil {
+					t.Fatalf("list all: %v", err)
+				}
+				var want []int64
+				for _, row := range all {
+					want = append(want, row.LogID)
+				}
+
+				var got []int64
+				params := newParams(1)
+				for range len(want) + 1 {
+					rows, err := listMovieLogPage(ctx, queries, params)
+					if err != nil {
+						t.Fatalf("list page: %v", err)
+					}
+					if len(rows) == 0 {
+						break
+					}
+					got = append(got, rows[0].LogID)
+
+					params = newParams(1)
+					if err := applyMovieLogCursor(&params, encodeMovieLogCursor(params, rows[0])); err != nil {
+						t.Fatalf("apply cursor: %v", err)
+					}
+				}
+
+				if len(want) != len(fixture.movies) || !slices.Equal(got, want) {
+					t.Errorf("paged %v, want %v in one page of %d entries", got, want, len(fixture.movies))
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultNotificationPageSize = 50
+	maxNotificationPageSize     = 200
+)
+
+// notificationKinds lists every kind of notification, in the order
+// preferences are reported. Each kind is on unless the user turns it off.
+var notificationKinds = []string{
+	"follow_request",
+	"follow_accepted",
+	"comment",
+	"reply",
+	"like",
+}
+
+var errNotificationNotFound = errors.New("notification not found")
+
+// notify tells a user that actorID did something that concerns them. Nothing
+// is sent when users act on their own entries or have turned the kind off. A
+// notification that cannot be stored is logged rather than failing the change
+// that caused it, so queries must not belong to that change's transaction: a
+// failed insert would abort it. Notify once the transaction has committed.
+func notify(ctx context.Context, queries *db.Queries, notification db.CreateNotificationParams) {
+	if _, err := queries.CreateNotification(ctx, notification); err != nil {
+		log.Printf("create %s notification error: %v", notification.Kind, err)
+	}
+}
+
+// notifyLogComment tells the log entry's owner about a new comment and, for a
+// reply, the author of the parent comment as well.
+func notifyLogComment(ctx context.Context, queries *db.Queries, ownerID int64, comment db.LogComment, parentAuthorID int64) {
+	notify(ctx, queries, db.CreateNotificationParams{
+		UserID:    ownerID,
+		ActorID:   comment.UserID,
+		Kind:      "comment",
+		LogID:     pgtype.Int8{Int64: comment.LogID, Valid: true},
+		CommentID: pgtype.Int8{Int64: comment.ID, Valid: true},
+	})
+	if comment.ParentID.Valid && parentAuthorID != ownerID {
+		notify(ctx, queries, db.CreateNotificationParams{
+			UserID:    parentAuthorID,
+			ActorID:   comment.UserID,
+			Kind:      "reply",
+			LogID:     pgtype.Int8{Int64: comment.LogID, Valid: true},
+			CommentID: pgtype.Int8{Int64: comment.ID, Valid: true},
+		})
+	}
+}
+
+// parseNotificationParams reads limit, cursor and unread for a notification
+// page. PageSize is set one past limit so the caller can tell whether another
+// page follows.
+func parseNotificationParams(c echo.Context, userID int64) (db.ListNotificationsParams, int, error) {
+	params := db.ListNotificationsParams{
+		UserID: userID,
+	}
+
+	limit := defaultNotificationPageSize
+	if raw := c.QueryParam("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxNotificationPageSize {
+			return params, 0, fmt.Errorf("limit must be between 1 and %d", maxNotificationPageSize)
+		}
+		limit = parsed
+	}
+	params.PageSize = int32(limit + 1)
+
+	if raw := c.QueryParam("unread"); raw != "" {
+		unread, err := strconv.ParseBool(raw)
+		if err != nil {
+			return params, 0, errors.New("unread must be true or false")
+		}
+		params.UnreadOnly = unread
+	}
+
+	if raw := c.QueryParam("cursor"); raw != "" {
+		var err error
+		params.CursorCreatedAt, params.CursorID, err = decodeTimelineCursor(raw)
+		if err != nil {
+			return params, 0, err
+		}
+	}
+
+	return params, limit, nil
+}
+
+// notificationPreferences reports whether each kind of notification is on for
+// the user.
+func notificationPreferences(ctx context.Context, queries *db.Queries, userID int64) (map[string]bool, error) {
+	rows, err := queries.ListNotificationPreferences(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list notification preferences: %w", err)
+	}
+
+	preferences := make(map[string]bool, len(notificationKinds))
+	for _, kind := range notificationKinds {
+		preferences[kind] = true
+	}
+	for _, row := range rows {
+		if _, ok := preferences[row.Kind]; ok {
+			preferences[row.Kind] = row.Enabled
+		}
+	}
+	return preferences, nil
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/labstack/echo/v4"
+	"golang.org/x/oauth2"
+)
+
+const (
+	oidcCookieName     = "moviestack_oidc"
+	oidcCookiePath     = "/api/auth/oidc"
+	oidcLoginLifetime  = 10 * time.Minute
+	oidcFallbackPrefix = "oidc-"
+)
+
+var (
+	errOIDCStateMismatch = errors.New("login request expired or did not match; try again")
+	errOIDCIdentityTaken = errors.New("this account is already linked to another identity from this identity provider")
+	errOIDCUsernameTaken = errors.New("an account with this username already exists; sign in to it first, then use single sign-on to link it")
+	errOIDCMissingToken  = errors.New("identity provider did not return an id_token")
+)
+
+// oidcAuth is the relying-party side of an authorization code flow with
+// PKCE against one OpenID Connect provider.
+type oidcAuth struct {
+	issuer        string
+	oauth2        oauth2.Config
+	verifier      *oidc.IDTokenVerifier
+	usernameClaim string
+	postLoginURL  string
+}
+
+// oidcLoginState is what a login carries from the redirect to the provider
+// to the callback. It lives in a short-lived cookie so no server state is
+// needed.
+type oidcLoginState struct {
+	State    string
+	Nonce    string
+	Verifier string
+}
+
+// oidcIdentity is who the provider says signed in. EmailVerified is the
+// provider's email_verified claim; an unverified email is kept for the record
+// but never used to link accounts.
+type oidcIdentity struct {
+	Subject       string
+	Username      string
+	Email         string
+	EmailVerified bool
+}
+
+// newOIDCAuth discovers the provider's endpoints and keys from its issuer
+// URL.
+func newOIDCAuth(ctx context.Context, config oidcConfig) (*oidcAuth, error) {
+	provider, err := oidc.NewProvider(ctx, config.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("discover provider: %w", err)
+	}
+
+	scopes := config.Scopes
+	if !slices.Contains(scopes, oidc.ScopeOpenID) {
+		scopes = append([]string{oidc.ScopeOpenID}, scopes...)
+	}
+
+	return &oidcAuth{
+		issuer: config.IssuerURL,
+		oauth2: oauth2.Config{
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+			RedirectURL:  config.RedirectURL,
+			Endpoint:     provider.Endpoint(),
+			Scopes:       scopes,
+		},
+		verifier:      provider.Verifier(&oidc.Config{ClientID: config.ClientID}),
+		usernameClaim: config.UsernameClaim,
+		postLoginURL:  config.PostLoginURL,
+	}, nil
+}
+
+// startLogin returns the provider URL to send the browser to, along with the
+// state the callback must see again.
+func (a *oidcAuth) startLogin() (string, oidcLoginState, error) {
+	state, err := randomToken()
+	if err != nil {
+		return "", oidcLoginState{}, err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return "", oidcLoginState{}, err
+	}
+
+	login := oidcLoginState{
+		State:    state,
+		Nonce:    nonce,
+		Verifier: oauth2.GenerateVerifier(),
+	}
+	url := a.oauth2.AuthCodeURL(login.State,
+		oidc.Nonce(login.Nonce),
+		oauth2.S256ChallengeOption(login.Verifier),
+	)
+	return url, login, nil
+}
+
+// finishLogin exchanges the authorization code and verifies the id_token it
+// comes back with.
+func (a *oidcAuth) finishLogin(ctx context.Context, login oidcLoginState, state, code string) (oidcIdentity, error) {
+	if login.State == "" || subtle.ConstantTimeCompare([]byte(login.State), []byte(state)) != 1 {
+		return oidcIdentity{}, errOIDCStateMismatch
+	}
+
+	token, err := a.oauth2.Exchange(ctx, code, oauth2.VerifierOption(login.Verifier))
+	if err != nil {
+		return oidcIdentity{}, fmt.Errorf("exchange code: %w", err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return oidcIdentity{}, errOIDCMissingToken
+	}
+
+	idToken, err := a.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return oidcIdentity{}, fmt.Errorf("verify id_token: %w", err)
+	}
+	if subtle.ConstantTimeCompare([]byte(idToken.Nonce), []byte(login.Nonce)) != 1 {
+		return oidcIdentity{}, errOIDCStateMismatch
+	}
+
+	var claims map[string]any
+	if err := idToken.Claims(&claims); err != nil {
+		return oidcIdentity{}, fmt.Errorf("read id_token claims: %w", err)
+	}
+	identity := oidcIdentity{Subject: idToken.Subject}
+	identity.Email, _ = claims["email"].(string)
+	// Some providers send email_verified as a string.
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		identity.EmailVerified = verified
+	case string:
+		identity.EmailVerified = verified == "true"
+	}
+	identity.Username, _ = claims[a.usernameClaim].(string)
+	identity.Username = strings.TrimSpace(identity.Username)
+	if identity.Username == "" {
+		identity.Username = oidcFallbackPrefix + identity.Subject
+	}
+	return identity, nil
+}
+
+// provisionOIDCUser finds the user an identity belongs to. On the first
+// login from an identity it is linked to an existing user if oidcLinkTarget
+// finds one, and otherwise a new user is created under the identity's
+// username. A username that is already taken is never linked just because it
+// matches.
+func provisionOIDCUser(ctx context.Context, pool *pgxpool.Pool, queries *db.Queries, source auditSource, issuer string, identity oidcIdentity, signedIn pgtype.Int8) (int64, error) {
+	userID, err := queries.GetUserIdentity(ctx, db.GetUserIdentityParams{
+		Issuer:  issuer,
+		Subject: identity.Subject,
+	})
+	if err == nil {
+		return userID, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("get user identity: %w", err)
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := queries.WithTx(tx)
+	userID, err = oidcLinkTarget(ctx, qtx, issuer, identity, signedIn)
+	if err != nil {
+		return 0, err
+	}
+	if userID == 0 {
+		user, err := qtx.CreateUser(ctx, identity.Username)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				return 0, errOIDCUsernameTaken
+			}
+			return 0, fmt.Errorf("create user: %w", err)
+		}
+		if err := recordAudit(ctx, qtx, source, auditEvent{
+			Action:     auditUserCreate,
+			TargetType: "user",
+			TargetID:   auditTarget(user.ID),
+			After:      user,
+		}); err != nil {
+			return 0, err
+		}
+		userID = user.ID
+	}
+
+	if err := qtx.CreateUserIdentity(ctx, db.CreateUserIdentityParams{
+		Issuer:        issuer,
+		Subject:       identity.Subject,
+		UserID:        userID,
+		Email:         pgtype.Text{String: identity.Email, Valid: identity.Email != ""},
+		EmailVerified: identity.EmailVerified,
+	}); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return 0, errOIDCIdentityTaken
+		}
+		return 0, fmt.Errorf("create user identity: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit transaction: %w", err)
+	}
+	return userID, nil
+}
+
+// oidcLinkTarget picks the existing user a new identity belongs to, or
+// returns 0 when there is none. A signed-in user has proved they own their
+// account, so the identity is linked to it. Otherwise it is linked only to
+// the one account whose verified email from an earlier login matches the
+// identity's verified email, and never to an account with a password or a
+// role above user: whoever controls that email at the provider would
+// otherwise take the account over.
+func oidcLinkTarget(ctx context.Context, qtx *db.Queries, issuer string, identity oidcIdentity, signedIn pgtype.Int8) (int64, error) {
+	if signedIn.Valid {
+		status, err := qtx.GetOIDCLinkStatus(ctx, db.GetOIDCLinkStatusParams{
+			Issuer: issuer,
+			UserID: signedIn.Int64,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("get oidc link status: %w", err)
+		}
+		if status.HasIssuerIdentity {
+			return 0, errOIDCIdentityTaken
+		}
+		return signedIn.Int64, nil
+	}
+
+	if !identity.EmailVerified || identity.Email == "" {
+		return 0, nil
+	}
+	userIDs, err := qtx.ListUserIDsByVerifiedEmail(ctx, identity.Email)
+	if err != nil {
+		return 0, fmt.Errorf("list users by verified email: %w", err)
+	}
+	if len(userIDs) != 1 {
+		return 0, nil
+	}
+
+	status, err := qtx.GetOIDCLinkStatus(ctx, db.GetOIDCLinkStatusParams{
+		Issuer: issuer,
+		UserID: userIDs[0],
+	})
+	if err != nil {
+		return 0, fmt.Errorf("get oidc link status: %w", err)
+	}
+	if status.HasPassword || status.HasIssuerIdentity || hasRole(status.Role, "moderator") {
+		return 0, nil
+	}
+	return userIDs[0], nil
+}
+
+// oidcLoginCookie carries login state to the callback. SameSite=Lax still
+// sends it on the provider's top-level redirect back to us.
+func oidcLoginCookie(login oidcLoginState, secure bool) *http.Cookie {
+	return &http.Cookie{
+		Name:     oidcCookieName,
+		Value:    strings.Join([]string{login.State, login.Nonce, login.Verifier}, "."),
+		Path:     oidcCookiePath,
+		MaxAge:   int(oidcLoginLifetime / time.Second),
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+func readOIDCLoginCookie(c echo.Context) oidcLoginState {
+	cookie, err := c.Cookie(oidcCookieName)
+	if err != nil {
+		return oidcLoginState{}
+	}
+	parts := strings.Split(cookie.Value, ".")
+	if len(parts) != 3 {
+		return oidcLoginState{}
+	}
+	return oidcLoginState{State: parts[0], Nonce: parts[1], Verifier: parts[2]}
+}
+
+func clearOIDCLoginCookie(secure bool) *http.Cookie {
+	return &http.Cookie{
+		Name:     oidcCookieName,
+		Path:     oidcCookiePath,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
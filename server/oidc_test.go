@@ -0,0 +1,370 @@
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const testOIDCClientID = "moviestack"
+
+// testOIDCProvider is a minimal OpenID Connect provider: discovery, a JWKS
+// with one RSA key, and a token endpoint that answers any code with an
+// id_token carrying the claims set for the next login.
+type testOIDCProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu     sync.Mutex
+	claims map[string]any
+	nonce  string
+}
+
+func newTestOIDCProvider(t *testing.T) *testOIDCProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	provider := &testOIDCProvider{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		issuer := provider.server.URL
+		writeTestJSON(w, map[string]any{
+			"issuer":                                issuer,
+			"authorization_endpoint":                issuer + "/authorize",
+			"token_endpoint":                        issuer + "/token",
+			"jwks_uri":                              issuer + "/jwks",
+			"response_types_supported":              []string{"code"},
+			"subject_types_supported":               []string{"public"},
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeTestJSON(w, map[string]any{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test",
+				"alg": "RS256",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		idToken, err := provider.idToken()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeTestJSON(w, map[string]any{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     idToken,
+		})
+	})
+
+	provider.server = httptest.NewServer(mux)
+	t.Cleanup(provider.server.Close)
+	return provider
+}
+
+// idToken signs the claims for the current login with the standard ones
+// added.
+func (p *testOIDCProvider) idToken() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	claims := map[string]any{
+		"iss":   p.server.URL,
+		"aud":   testOIDCClientID,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+		"nonce": p.nonce,
+	}
+	for name, value := range p.claims {
+		claims[name] = value
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "kid": "test", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, p.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// login runs the whole flow for an identity with the given claims, the way
+// the login and callback routes do, and returns what finishLogin verified.
+func (p *testOIDCProvider) login(t *testing.T, auth *oidcAuth, claims map[string]any) (oidcIdentity, error) {
+	t.Helper()
+
+	authURL, login, err := auth.startLogin()
+	if err != nil {
+		t.Fatalf("start login: %v", err)
+	}
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("parse auth url: %v", err)
+	}
+
+	p.mu.Lock()
+	p.claims = claims
+	p.nonce = parsed.Query().Get("nonce")
+	p.mu.Unlock()
+
+	return auth.finishLogin(context.Background(), login, parsed.Query().Get("state"), "code")
+}
+
+func newTestOIDCAuth(t *testing.T, provider *testOIDCProvider) *oidcAuth {
+	t.Helper()
+
+	auth, err := newOIDCAuth(context.Background(), oidcConfig{
+		IssuerURL:     provider.server.URL,
+		ClientID:      testOIDCClientID,
+		RedirectURL:   "http://localhost/api/auth/oidc/callback",
+		UsernameClaim: "preferred_username",
+	})
+	if err != nil {
+		t.Fatalf("new oidc auth: %v", err)
+	}
+	return auth
+}
+
+func writeTestJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(value)
+}
+
+func TestFinishLogin(t *testing.T) {
+	provider := newTestOIDCProvider(t)
+	auth := newTestOIDCAuth(t, provider)
+
+	tests := []struct {
+		name   string
+		claims map[string]any
+		want   oidcIdentity
+	}{
+		{
+			name:   "username claim",
+			claims: map[string]any{"sub": "1", "preferred_username": " alice ", "email": "alice@example.com", "email_verified": true},
+			want:   oidcIdentity{Subject: "1", Username: "alice", Email: "alice@example.com", EmailVerified: true},
+		},
+		{
+			name:   "email verified as a string",
+			claims: map[string]any{"sub": "2", "preferred_username": "bob", "email": "bob@example.com", "email_verified": "true"},
+			want:   oidcIdentity{Subject: "2", Username: "bob", Email: "bob@example.com", EmailVerified: true},
+		},
+		{
+			// The email's local part is not used as a username.
+			name:   "no username claim",
+			claims: map[string]any{"sub": "3", "email": "carol@example.com"},
+			want:   oidcIdentity{Subject: "3", Username: "oidc-3", Email: "carol@example.com"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := provider.login(t, auth, tt.claims)
+			if err != nil {
+				t.Fatalf("finish login: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("identity = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("state mismatch", func(t *testing.T) {
+		_, login, err := auth.startLogin()
+		if err != nil {
+			t.Fatalf("start login: %v", err)
+		}
+		_, err = auth.finishLogin(context.Background(), login, "other", "code")
+		if !errors.Is(err, errOIDCStateMismatch) {
+			t.Errorf("err = %v, want %v", err, errOIDCStateMismatch)
+		}
+	})
+
+	t.Run("nonce mismatch", func(t *testing.T) {
+		authURL, login, err := auth.startLogin()
+		if err != nil {
+			t.Fatalf("start login: %v", err)
+		}
+		parsed, _ := url.Parse(authURL)
+		provider.mu.Lock()
+		provider.claims = map[string]any{"sub": "4"}
+		provider.nonce = "replayed"
+		provider.mu.Unlock()
+
+		_, err = auth.finishLogin(context.Background(), login, parsed.Query().Get("state"), "code")
+		if !errors.Is(err, errOIDCStateMismatch) {
+			t.Errorf("err = %v, want %v", err, errOIDCStateMismatch)
+		}
+	})
+}
+
+func TestProvisionOIDCUser(t *testing.T) {
+	ctx := context.Background()
+	pool, queries := openTestDB(t)
+	provider := newTestOIDCProvider(t)
+	auth := newTestOIDCAuth(t, provider)
+
+	// provision logs in with the claims and provisions the identity, as the
+	// callback route does.
+	provision := func(t *testing.T, claims map[string]any, signedIn pgtype.Int8) (int64, error) {
+		t.Helper()
+		identity, err := provider.login(t, auth, claims)
+		if err != nil {
+			t.Fatalf("finish login: %v", err)
+		}
+		return provisionOIDCUser(ctx, pool, queries, auditSource{}, auth.issuer, identity, signedIn)
+	}
+	createUser := func(t *testing.T, username string) int64 {
+		t.Helper()
+		user, err := queries.CreateUser(ctx, username)
+		if err != nil {
+			t.Fatalf("create user %s: %v", username, err)
+		}
+		return user.ID
+	}
+	// linkEmail gives userID an identity from another provider with a
+	// verified email, as an earlier single sign-on would have.
+	linkEmail := func(t *testing.T, userID int64, email string) {
+		t.Helper()
+		if err := queries.CreateUserIdentity(ctx, db.CreateUserIdentityParams{
+			Issuer:        "https://other.example.com",
+			Subject:       email,
+			UserID:        userID,
+			Email:         pgtype.Text{String: email, Valid: true},
+			EmailVerified: true,
+		}); err != nil {
+			t.Fatalf("create identity: %v", err)
+		}
+	}
+	signedIn := func(userID int64) pgtype.Int8 {
+		return pgtype.Int8{Int64: userID, Valid: true}
+	}
+
+	t.Run("first login creates a user and later logins find it", func(t *testing.T) {
+		claims := map[string]any{"sub": "new", "preferred_username": "newcomer"}
+		userID, err := provision(t, claims, pgtype.Int8{})
+		if err != nil {
+			t.Fatalf("provision: %v", err)
+		}
+		user, err := queries.GetUser(ctx, userID)
+		if err != nil || user.Username != "newcomer" {
+			t.Fatalf("created user = %+v, %v", user, err)
+		}
+
+		again, err := provision(t, claims, pgtype.Int8{})
+		if err != nil || again != userID {
+			t.Errorf("second login = %d, %v, want %d", again, err, userID)
+		}
+	})
+
+	t.Run("a matching username is not linked", func(t *testing.T) {
+		createUser(t, "victim")
+		_, err := provision(t, map[string]any{"sub": "squatter", "preferred_username": "victim"}, pgtype.Int8{})
+		if !errors.Is(err, errOIDCUsernameTaken) {
+			t.Errorf("err = %v, want %v", err, errOIDCUsernameTaken)
+		}
+	})
+
+	t.Run("a signed-in user links the identity to their account", func(t *testing.T) {
+		userID := createUser(t, "linker")
+		got, err := provision(t, map[string]any{"sub": "linker", "preferred_username": "someone-else"}, signedIn(userID))
+		if err != nil || got != userID {
+			t.Fatalf("provision = %d, %v, want %d", got, err, userID)
+		}
+
+		_, err = provision(t, map[string]any{"sub": "linker-2"}, signedIn(userID))
+		if !errors.Is(err, errOIDCIdentityTaken) {
+			t.Errorf("second identity err = %v, want %v", err, errOIDCIdentityTaken)
+		}
+	})
+
+	t.Run("a verified email links to the account with that verified email", func(t *testing.T) {
+		userID := createUser(t, "emailed")
+		linkEmail(t, userID, "emailed@example.com")
+
+		got, err := provision(t, map[string]any{"sub": "emailed", "email": "Emailed@example.com", "email_verified": true}, pgtype.Int8{})
+		if err != nil || got != userID {
+			t.Errorf("provision = %d, %v, want %d", got, err, userID)
+		}
+	})
+
+	t.Run("an unverified email is not linked", func(t *testing.T) {
+		userID := createUser(t, "unverified")
+		linkEmail(t, userID, "unverified@example.com")
+
+		got, err := provision(t, map[string]any{"sub": "unverified", "email": "unverified@example.com"}, pgtype.Int8{})
+		if err != nil {
+			t.Fatalf("provision: %v", err)
+		}
+		if got == userID {
+			t.Error("unverified email was linked to the existing account")
+		}
+	})
+
+	t.Run("accounts with a password are not linked by email", func(t *testing.T) {
+		userID := createUser(t, "passworded")
+		linkEmail(t, userID, "passworded@example.com")
+		if err := queries.CreateUserCredentials(ctx, db.CreateUserCredentialsParams{UserID: userID, PasswordHash: "hash"}); err != nil {
+			t.Fatalf("create credentials: %v", err)
+		}
+
+		got, err := provision(t, map[string]any{"sub": "passworded", "email": "passworded@example.com", "email_verified": true}, pgtype.Int8{})
+		if err != nil {
+			t.Fatalf("provision: %v", err)
+		}
+		if got == userID {
+			t.Error("verified email was linked to an account with a password")
+		}
+	})
+
+	t.Run("admins are not linked by email", func(t *testing.T) {
+		userID := createUser(t, "administrator")
+		linkEmail(t, userID, "admin@example.com")
+		if _, err := queries.SetUserRole(ctx, db.SetUserRoleParams{Role: "admin", ID: userID}); err != nil {
+			t.Fatalf("set role: %v", err)
+		}
+
+		got, err := provision(t, map[string]any{"sub": "administrator", "email": "admin@example.com", "email_verified": true}, pgtype.Int8{})
+		if err != nil {
+			t.Fatalf("provision: %v", err)
+		}
+		if got == userID {
+			t.Error("verified email was linked to an admin")
+		}
+	})
+}
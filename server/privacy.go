@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+)
+
+// logVisibilities are the audiences a log or a single entry can be shown to.
+// An entry without its own visibility follows the user's log_visibility.
+var logVisibilities = []string{"public", "friends", "private"}
+
+var errInvalidVisibility = errors.New("visibility must be public, friends or private")
+
+// logAccess is what one viewer may see of another user's log.
+type logAccess struct {
+	owner             bool
+	friend            bool
+	profileVisibility string
+	hideNotes         bool
+}
+
+// resolveLogAccess works out what viewerID may see of ownerID's log. An
+// invalid viewerID stands for an anonymous reader, who only sees public
+// entries.
+func resolveLogAccess(ctx context.Context, queries *db.Queries, ownerID int64, viewerID pgtype.Int8) (logAccess, error) {
+	privacy, err := queries.GetUserPrivacy(ctx, ownerID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return logAccess{}, errUserNotFound
+		}
+		return logAccess{}, fmt.Errorf("get user privacy: %w", err)
+	}
+
+	access := logAccess{
+		owner:             viewerID.Valid && viewerID.Int64 == ownerID,
+		profileVisibility: privacy.LogVisibility,
+		hideNotes:         privacy.HideNotes,
+	}
+	if viewerID.Valid && !access.owner {
+		access.friend, err = queries.AreFriends(ctx, db.AreFriendsParams{
+			UserID:      ownerID,
+			OtherUserID: viewerID.Int64,
+		})
+		if err != nil {
+			return logAccess{}, fmt.Errorf("check friendship: %w", err)
+		}
+	}
+	return access, nil
+}
+
+// intersect narrows a to what both viewers may see of the same log. Each
+// level of access includes everything below it, so that is the lower of the
+// two.
+func (a logAccess) intersect(b logAccess) logAccess {
+	owner := a.owner && b.owner
+	a.friend = !owner && (a.owner || a.friend) && (b.owner || b.friend)
+	a.owner = owner
+	return a
+}
+
+// visibilities lists the entry visibilities the viewer may see.
+func (a logAccess) visibilities() []string {
+	switch {
+	case a.owner:
+		return logVisibilities
+	case a.friend:
+		return []string{"public", "friends"}
+	default:
+		return []string{"public"}
+	}
+}
+
+// canSee reports whether the viewer may see an entry with the given override.
+func (a logAccess) canSee(entryVisibility pgtype.Text) bool {
+	visibility := a.profileVisibility
+	if entryVisibility.Valid {
+		visibility = entryVisibility.String
+	}
+	return slices.Contains(a.visibilities(), visibility)
+}
+
+// showNotes reports whether the viewer may read notes on entries they can see.
+func (a logAccess) showNotes() bool {
+	return a.owner || !a.hideNotes
+}
+
+// requestLogAccess resolves what the signed-in user, or an anonymous reader,
+// may see of ownerID's log.
+func requestLogAccess(c echo.Context, queries *db.Queries, ownerID int64) (logAccess, error) {
+	return resolveLogAccess(c.Request().Context(), queries, ownerID, viewerID(c))
+}
+
+func logAccessErrorResponse(c echo.Context, err error) error {
+	if errors.Is(err, errUserNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "user not found",
+		})
+	}
+
+	log.Printf("resolve log access error: %v", err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{
+		"error": "failed to verify user",
+	})
+}
+
+func logEntryVisibleErrorResponse(c echo.Context, err error) error {
+	if errors.Is(err, errLogEntryNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	log.Printf("check log entry visibility error: %v", err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{
+		"error": "failed to verify movie log entry",
+	})
+}
+
+// parseVisibility checks a visibility setting. A nil value clears an entry's
+// override.
+func parseVisibility(raw *string) (pgtype.Text, error) {
+	if raw == nil {
+		return pgtype.Text{}, nil
+	}
+	if !slices.Contains(logVisibilities, *raw) {
+		return pgtype.Text{}, errInvalidVisibility
+	}
+	return pgtype.Text{String: *raw, Valid: true}, nil
+}
+
+// checkLogEntryVisible makes sure the entry exists and the viewer may see it.
+// Entries the viewer may not see are reported as missing so their existence
+// does not leak.
+func checkLogEntryVisible(ctx context.Context, queries *db.Queries, access logAccess, ownerID, logID int64) error {
+	visibility, err := queries.GetMovieLogVisibility(ctx, db.GetMovieLogVisibilityParams{
+		ID:     logID,
+		UserID: ownerID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errLogEntryNotFound
+		}
+		return fmt.Errorf("get log entry visibility: %w", err)
+	}
+	if !access.canSee(visibility) {
+		return errLogEntryNotFound
+	}
+	return nil
+}
@@ -0,0 +1,375 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// testViewers are the kinds of reader a log can have. A signed-in stranger
+// and an anonymous reader resolve to the same access, but both are listed so
+// the matrix reads the way the privacy rules are written.
+var testViewers = []struct {
+	name   string
+	access logAccess
+}{
+	{"owner", logAccess{owner: true}},
+	{"friend", logAccess{friend: true}},
+	{"non-friend", logAccess{}},
+	{"anonymous", logAccess{}},
+}
+
+func TestLogAccessCanSee(t *testing.T) {
+	// want lists, per viewer, whether an entry with the given visibility is
+	// shown.
+	tests := []struct {
+		visibility string
+		want       map[string]bool
+	}{
+		{"public", map[string]bool{"owner": true, "friend": true, "non-friend": true, "anonymous": true}},
+		{"friends", map[string]bool{"owner": true, "friend": true, "non-friend": false, "anonymous": false}},
+		{"private", map[string]bool{"owner": true, "friend": false, "non-friend": false, "anonymous": false}},
+	}
+
+	for _, tt := range tests {
+		for _, viewer := range testViewers {
+			t.Run(tt.visibility+"/"+viewer.name, func(t *testing.T) {
+				want := tt.want[viewer.name]
+
+				profile := viewer.access
+				profile.profileVisibility = tt.visibility
+				if got := profile.canSee(pgtype.Text{}); got != want {
+					t.Errorf("profile %s: canSee = %v, want %v", tt.visibility, got, want)
+				}
+				if got := slices.Contains(profile.visibilities(), tt.visibility); got != want {
+					t.Errorf("visibilities() = %v, contains %s = %v, want %v", profile.visibilities(), tt.visibility, got, want)
+				}
+
+				// An entry's own visibility wins over the profile's, whichever
+				// way it points.
+				for _, profileVisibility := range logVisibilities {
+					overridden := viewer.access
+					overridden.profileVisibility = profileVisibility
+					entry := pgtype.Text{String: tt.visibility, Valid: true}
+					if got := overridden.canSee(entry); got != want {
+						t.Errorf("profile %s, entry %s: canSee = %v, want %v", profileVisibility, tt.visibility, got, want)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestLogAccessShowNotes(t *testing.T) {
+	for _, viewer := range testViewers {
+		for _, hideNotes := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/hide_notes=%v", viewer.name, hideNotes), func(t *testing.T) {
+				access := viewer.access
+				access.hideNotes = hideNotes
+				want := viewer.name == "owner" || !hideNotes
+				if got := access.showNotes(); got != want {
+					t.Errorf("showNotes = %v, want %v", got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestLogAccessIntersect(t *testing.T) {
+	// Viewers are listed from least to most access; the intersection of two
+	// is whichever comes first.
+	levels := []string{"anonymous", "friend", "owner"}
+	accessFor := map[string]logAccess{
+		"anonymous": {},
+		"friend":    {friend: true},
+		"owner":     {owner: true},
+	}
+
+	for i, a := range levels {
+		for j, b := range levels {
+			t.Run(a+"/"+b, func(t *testing.T) {
+				want := accessFor[levels[min(i, j)]]
+				got := accessFor[a].intersect(accessFor[b])
+				if got != want {
+					t.Errorf("intersect = %+v, want %+v", got, want)
+				}
+			})
+		}
+	}
+}
+
+// privacyFixture is a log owner with one entry of each visibility, and the
+// readers it is checked against. follower follows the owner without being
+// followed back, so is not a friend.
+type privacyFixture struct {
+	owner, friend, follower, stranger int64
+	// movies maps each entry's visibility to its movie id.
+	movies map[string]int32
+}
+
+func seedPrivacyFixture(t *testing.T, ctx context.Context, pool *pgxpool.Pool, queries *db.Queries) privacyFixture {
+	t.Helper()
+
+	createUser := func(username string) int64 {
+		user, err := queries.CreateUser(ctx, username)
+		if err != nil {
+			t.Fatalf("create user %s: %v", username, err)
+		}
+		return user.ID
+	}
+	follow := func(followerID, followeeID int64) {
+		params := db.CreateFollowRequestParams{FollowerID: followerID, FolloweeID: followeeID}
+		if _, err := queries.CreateFollowRequest(ctx, params); err != nil {
+			t.Fatalf("create follow request: %v", err)
+		}
+		if _, err := queries.AcceptFollowRequest(ctx, db.AcceptFollowRequestParams(params)); err != nil {
+			t.Fatalf("accept follow request: %v", err)
+		}
+	}
+
+	fixture := privacyFixture{
+		owner:    createUser("owner"),
+		friend:   createUser("friend"),
+		follower: createUser("follower"),
+		stranger: createUser("stranger"),
+		movies:   map[string]int32{"public": 1, "friends": 2, "private": 3},
+	}
+	follow(fixture.friend, fixture.owner)
+	follow(fixture.owner, fixture.friend)
+	follow(fixture.follower, fixture.owner)
+
+	if _, err := queries.UpdateUserPrivacy(ctx, db.UpdateUserPrivacyParams{
+		HideNotes: pgtype.Bool{Bool: true, Valid: true},
+		ID:        fixture.owner,
+	}); err != nil {
+		t.Fatalf("update privacy: %v", err)
+	}
+
+	for visibility, movieID := range fixture.movies {
+		if _, err := pool.Exec(ctx, `INSERT INTO movie_ids (id, original_title, popularity) VALUES ($1, $2, 1)`, movieID, visibility+" movie"); err != nil {
+			t.Fatalf("insert movie: %v", err)
+		}
+		entry, err := queries.CreateMovieLogEntry(ctx, db.CreateMovieLogEntryParams{
+			UserID:     fixture.owner,
+			MovieID:    movieID,
+			WatchedOn:  pgtype.Date{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+			Note:       pgtype.Text{String: "a note", Valid: true},
+			Visibility: pgtype.Text{String: visibility, Valid: true},
+		})
+		if err != nil {
+			t.Fatalf("create log entry: %v", err)
+		}
+		if err := queries.RecordActivityEvent(ctx, db.RecordActivityEventParams{
+			UserID:    fixture.owner,
+			LogID:     entry.ID,
+			Kind:      "logged",
+			Note:      entry.Note,
+			WatchedOn: entry.WatchedOn,
+		}); err != nil {
+			t.Fatalf("record activity event: %v", err)
+		}
+	}
+	return fixture
+}
+
+// visibleMovies maps the movie ids of the fixture's entries back to their
+// visibilities.
+func (f privacyFixture) visibleMovies(movieIDs []int32) []string {
+	var visible []string
+	for visibility, movieID := range f.movies {
+		if slices.Contains(movieIDs, movieID) {
+			visible = append(visible, visibility)
+		}
+	}
+	slices.Sort(visible)
+	return visible
+}
+
+// privacyReader is someone reading the fixture owner's log, with the entry
+// visibilities they should get back.
+type privacyReader struct {
+	name     string
+	viewerID pgtype.Int8
+	want     []string
+}
+
+func (f privacyFixture) readers() []privacyReader {
+	signedIn := func(id int64) pgtype.Int8 { return pgtype.Int8{Int64: id, Valid: true} }
+	return []privacyReader{
+		{"owner", signedIn(f.owner), []string{"friends", "private", "public"}},
+		{"friend", signedIn(f.friend), []string{"friends", "public"}},
+		{"follower", signedIn(f.follower), []string{"public"}},
+		{"stranger", signedIn(f.stranger), []string{"public"}},
+		{"anonymous", pgtype.Int8{}, []string{"public"}},
+	}
+}
+
+func TestListMovieLogPagePrivacy(t *testing.T) {
+	ctx := context.Background()
+	pool, queries := openTestDB(t)
+	fixture := seedPrivacyFixture(t, ctx, pool, queries)
+
+	for _, reader := range fixture.readers() {
+		for sortBy, descending := range movieLogSortDescending {
+			t.Run(reader.name+"/"+sortBy, func(t *testing.T) {
+				access, err := resolveLogAccess(ctx, queries, fixture.owner, reader.viewerID)
+				if err != nil {
+					t.Fatalf("resolve access: %v", err)
+				}
+				params := movieLogListParams{
+					ListMovieLogIDsParams: db.ListMovieLogIDsParams{
+						UserID:       fixture.owner,
+						Visibilities: access.visibilities(),
+						ShowNotes:    access.showNotes(),
+						SortBy:       sortBy,
+						Descending:   descending,
+						PageSize:     10,
+					},
+					ViewerID: reader.viewerID,
+				}
+
+				rows, err := listMovieLogPage(ctx, queries, params)
+				if err != nil {
+					t.Fatalf("list page: %v", err)
+				}
+				var movieIDs []int32
+				for _, row := range rows {
+					movieIDs = append(movieIDs, row.MovieID)
+					if row.Note.Valid != (reader.name == "owner") {
+						t.Errorf("movie %d: note shown = %v with hide_notes on", row.MovieID, row.Note.Valid)
+					}
+				}
+				if got := fixture.visibleMovies(movieIDs); !slices.Equal(got, reader.want) {
+					t.Errorf("listed %v, want %v", got, reader.want)
+				}
+			})
+		}
+	}
+}
+
+func TestExportMovieLogPagePrivacy(t *testing.T) {
+	ctx := context.Background()
+	pool, queries := openTestDB(t)
+	fixture := seedPrivacyFixture(t, ctx, pool, queries)
+
+	for _, reader := range fixture.readers() {
+		t.Run(reader.name, func(t *testing.T) {
+			access, err := resolveLogAccess(ctx, queries, fixture.owner, reader.viewerID)
+			if err != nil {
+				t.Fatalf("resolve access: %v", err)
+			}
+			rows, err := queries.ExportMovieLogPage(ctx, db.ExportMovieLogPageParams{
+				ShowNotes:    access.showNotes(),
+				UserID:       fixture.owner,
+				Visibilities: access.visibilities(),
+				PageSize:     exportPageSize,
+			})
+			if err != nil {
+				t.Fatalf("export page: %v", err)
+			}
+			var movieIDs []int32
+			for _, row := range rows {
+				movieIDs = append(movieIDs, row.MovieID)
+				if row.Note.Valid != (reader.name == "owner") {
+					t.Errorf("movie %d: note exported = %v with hide_notes on", row.MovieID, row.Note.Valid)
+				}
+			}
+			if got := fixture.visibleMovies(movieIDs); !slices.Equal(got, reader.want) {
+				t.Errorf("exported %v, want %v", got, reader.want)
+			}
+		})
+	}
+}
+
+func TestListFeedEventsPrivacy(t *testing.T) {
+	ctx := context.Background()
+	pool, queries := openTestDB(t)
+	fixture := seedPrivacyFixture(t, ctx, pool, queries)
+
+	// Only accepted followers have the owner in their feed; the stranger
+	// follows nobody and sees nothing.
+	tests := []struct {
+		name     string
+		readerID int64
+		want     []string
+	}{
+		{"friend", fixture.friend, []string{"friends", "public"}},
+		{"follower", fixture.follower, []string{"public"}},
+		{"stranger", fixture.stranger, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := queries.ListFeedEvents(ctx, db.ListFeedEventsParams{
+				UserID:   tt.readerID,
+				PageSize: 10,
+			})
+			if err != nil {
+				t.Fatalf("list feed: %v", err)
+			}
+			var movieIDs []int32
+			for _, row := range rows {
+				movieIDs = append(movieIDs, row.MovieID)
+				if row.Note.Valid {
+					t.Errorf("movie %d: note shown in feed with hide_notes on", row.MovieID)
+				}
+			}
+			if got := fixture.visibleMovies(movieIDs); !slices.Equal(got, tt.want) {
+				t.Errorf("feed has %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// With notes shown, the feed follows an edit made after the event was
+	// recorded rather than keeping the note the entry was logged with.
+	t.Run("edited note", func(t *testing.T) {
+		if _, err := queries.UpdateUserPrivacy(ctx, db.UpdateUserPrivacyParams{
+			HideNotes: pgtype.Bool{Bool: false, Valid: true},
+			ID:        fixture.owner,
+		}); err != nil {
+			t.Fatalf("update privacy: %v", err)
+		}
+
+		rows, err := queries.ListFeedEvents(ctx, db.ListFeedEventsParams{UserID: fixture.friend, PageSize: 10})
+		if err != nil {
+			t.Fatalf("list feed: %v", err)
+		}
+		var logID int64
+		for _, row := range rows {
+			if row.MovieID == fixture.movies["public"] {
+				logID = row.LogID
+			}
+		}
+		if logID == 0 {
+			t.Fatal("public entry missing from the friend's feed")
+		}
+		if _, err := queries.PatchMovieLogEntry(ctx, db.PatchMovieLogEntryParams{
+			SetNote: true,
+			Note:    pgtype.Text{String: "an edited note", Valid: true},
+			ID:      logID,
+			UserID:  fixture.owner,
+		}); err != nil {
+			t.Fatalf("patch note: %v", err)
+		}
+
+		rows, err = queries.ListFeedEvents(ctx, db.ListFeedEventsParams{UserID: fixture.friend, PageSize: 10})
+		if err != nil {
+			t.Fatalf("list feed: %v", err)
+		}
+		for _, row := range rows {
+			want := "a note"
+			if row.LogID == logID {
+				want = "an edited note"
+			}
+			if row.Note.String != want {
+				t.Errorf("movie %d: note = %q, want %q", row.MovieID, row.Note.String, want)
+			}
+		}
+	})
+}
@@ -0,0 +1,495 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	errLogEntryNotFound    = errors.New("movie log entry not found")
+	errRankSessionNotFound = errors.New("rank session not found")
+	errLogEntryNotRanked   = errors.New("movie log entry is not ranked")
+	errSentimentRequired   = errors.New("sentiment must be liked, fine or disliked")
+	errRankOutsideBand     = errors.New("rank_position is outside the sentiment band")
+	errRankBandMismatch    = errors.New("log entries are in different sentiment bands")
+	errRankBandOrder       = errors.New("log_ids must list liked, then fine, then disliked entries")
+)
+
+// rankSentiments lists the sentiment bands in ranking order. Every liked entry
+// ranks above every fine entry, which ranks above every disliked one, so each
+// band occupies one contiguous run of rank positions.
+var rankSentiments = []string{"liked", "fine", "disliked"}
+
+// sentimentScoreRanges is the slice of the 0-10 scale each band spreads its
+// entries across, best entry first.
+var sentimentScoreRanges = map[string][2]float64{
+	"liked":    {10, 6.7},
+	"fine":     {6.6, 3.4},
+	"disliked": {3.3, 0},
+}
+
+func sentimentBand(sentiment string) int {
+	for i, candidate := range rankSentiments {
+		if candidate == sentiment {
+			return i
+		}
+	}
+	return -1
+}
+
+// rankScore derives an entry's 0-10 score from its place within its sentiment
+// band. Unranked entries have no score.
+func rankScore(sentiment pgtype.Text, rankPosition pgtype.Int4, bandPosition, bandSize int64) *float64 {
+	scoreRange, ok := sentimentScoreRanges[sentiment.String]
+	if !rankPosition.Valid || !sentiment.Valid || !ok {
+		return nil
+	}
+
+	score := scoreRange[0]
+	if bandSize > 1 {
+		step := (scoreRange[0] - scoreRange[1]) / float64(bandSize-1)
+		score -= step * float64(bandPosition-1)
+	}
+	score = math.Round(score*10) / 10
+	return &score
+}
+
+// rankBandBounds returns the first and last positions currently held by the
+// sentiment band, plus the total number of ranked entries. An empty band has
+// end == start-1, with start being where its first entry would go.
+func rankBandBounds(ctx context.Context, qtx *db.Queries, userID int64, sentiment string) (start, end, total int32, err error) {
+	counts, err := qtx.CountRankedMovieLogBySentiment(ctx, userID)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("count ranked entries by sentiment: %w", err)
+	}
+
+	band := sentimentBand(sentiment)
+	start = 1
+	var size int32
+	for _, row := range counts {
+		total += int32(row.RankedCount)
+		rowBand := sentimentBand(row.Sentiment.String)
+		if rowBand < band {
+			start += int32(row.RankedCount)
+		} else if rowBand == band {
+			size = int32(row.RankedCount)
+		}
+	}
+	return start, start + size - 1, total, nil
+}
+
+// rankBandSizes holds how many ranked entries each sentiment band has. It lets
+// a page of the log derive scores without reading the rest of the ranking.
+type rankBandSizes map[string]int64
+
+func loadRankBandSizes(ctx context.Context, queries *db.Queries, userID int64) (rankBandSizes, error) {
+	counts, err := queries.CountRankedMovieLogBySentiment(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("count ranked entries by sentiment: %w", err)
+	}
+
+	sizes := make(rankBandSizes, len(counts))
+	for _, row := range counts {
+		sizes[row.Sentiment.String] = row.RankedCount
+	}
+	return sizes, nil
+}
+
+// score is rankScore for an entry whose band position is worked out from its
+// rank position and the sizes of the bands above it.
+func (sizes rankBandSizes) score(sentiment pgtype.Text, rankPosition pgtype.Int4) *float64 {
+	band := sentimentBand(sentiment.String)
+	if !rankPosition.Valid || band < 0 {
+		return nil
+	}
+
+	bandStart := int64(1)
+	for _, above := range rankSentiments[:band] {
+		bandStart += sizes[above]
+	}
+	return rankScore(sentiment, rankPosition, int64(rankPosition.Int32)-bandStart+1, sizes[sentiment.String])
+}
+
+// shiftRankPositions moves every ranked entry between fromPosition and
+// toPosition by delta. Rows are parked above the live range first so the
+// movie_log_user_rank_unique index never sees two rows on the same position.
+func shiftRankPositions(ctx context.Context, qtx *db.Queries, userID int64, fromPosition, toPosition, delta int32) error {
+	if fromPosition > toPosition {
+		return nil
+	}
+
+	if err := qtx.ParkRankPositions(ctx, db.ParkRankPositionsParams{
+		UserID:       userID,
+		FromPosition: fromPosition,
+		ToPosition:   toPosition,
+	}); err != nil {
+		return fmt.Errorf("park rank positions: %w", err)
+	}
+
+	if err := qtx.UnparkRankPositions(ctx, db.UnparkRankPositionsParams{
+		Delta:  delta,
+		UserID: userID,
+	}); err != nil {
+		return fmt.Errorf("unpark rank positions: %w", err)
+	}
+	return nil
+}
+
+// insertAtRank places an unranked log entry in the sentiment band at
+// position, clamped to the band, and returns the position it was stored at.
+func insertAtRank(ctx context.Context, qtx *db.Queries, userID, logID int64, sentiment string, position int32) (int32, error) {
+	start, end, total, err := rankBandBounds(ctx, qtx, userID, sentiment)
+	if err != nil {
+		return 0, err
+	}
+
+	if position < start {
+		position = start
+	}
+	if position > end+1 {
+		position = end + 1
+	}
+
+	if err := qtx.SetMovieLogSentiment(ctx, db.SetMovieLogSentimentParams{
+		Sentiment: pgtype.Text{String: sentiment, Valid: true},
+		ID:        logID,
+		UserID:    userID,
+	}); err != nil {
+		return 0, fmt.Errorf("set sentiment: %w", err)
+	}
+
+	if err := shiftRankPositions(ctx, qtx, userID, position, total, 1); err != nil {
+		return 0, err
+	}
+
+	if err := qtx.SetMovieLogRankPosition(ctx, db.SetMovieLogRankPositionParams{
+		RankPosition: pgtype.Int4{Int32: position, Valid: true},
+		ID:           logID,
+		UserID:       userID,
+	}); err != nil {
+		return 0, fmt.Errorf("set rank position: %w", err)
+	}
+	return position, nil
+}
+
+// removeFromRank clears a log entry's rank and closes the gap it leaves behind.
+func removeFromRank(ctx context.Context, qtx *db.Queries, userID, logID int64, position int32) error {
+	count, err := qtx.CountRankedMovieLog(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("count ranked entries: %w", err)
+	}
+
+	if err := qtx.SetMovieLogRankPosition(ctx, db.SetMovieLogRankPositionParams{
+		RankPosition: pgtype.Int4{},
+		ID:           logID,
+		UserID:       userID,
+	}); err != nil {
+		return fmt.Errorf("clear rank position: %w", err)
+	}
+
+	return shiftRankPositions(ctx, qtx, userID, position+1, int32(count), -1)
+}
+
+// advanceRankSession narrows a binary insertion search over the ranked
+// positions [low, high], kept inside the entry's sentiment band. An entry
+// that is already ranked keeps its place until the search ends, so positions
+// are counted as if it were not in the list; see rankSearchPosition. Once the
+// range is empty the entry is moved to low and the session is removed;
+// otherwise the entry at the midpoint becomes the next comparison.
+func advanceRankSession(ctx context.Context, qtx *db.Queries, userID, logID int64, current pgtype.Int4, sentiment string, low, high int32) (RankSessionResponse, error) {
+	start, end, _, err := rankBandBounds(ctx, qtx, userID, sentiment)
+	if err != nil {
+		return RankSessionResponse{}, err
+	}
+	if current.Valid {
+		if current.Int32 < start {
+			start--
+			end--
+		} else if current.Int32 <= end {
+			end--
+		}
+	}
+	if low < start {
+		low = start
+	}
+	if high > end {
+		high = end
+	}
+
+	if low > high {
+		if current.Valid {
+			if err := removeFromRank(ctx, qtx, userID, logID, current.Int32); err != nil {
+				return RankSessionResponse{}, err
+			}
+		}
+		position, err := insertAtRank(ctx, qtx, userID, logID, sentiment, low)
+		if err != nil {
+			return RankSessionResponse{}, err
+		}
+		if err := qtx.DeleteRankSessionByLogID(ctx, logID); err != nil {
+			return RankSessionResponse{}, fmt.Errorf("delete rank session: %w", err)
+		}
+		if err := recordRankActivity(ctx, qtx, userID, logID, position); err != nil {
+			return RankSessionResponse{}, err
+		}
+		return RankSessionResponse{
+			LogID:        logID,
+			Status:       "ranked",
+			RankPosition: &position,
+		}, nil
+	}
+
+	midpoint := low + (high-low)/2
+	if current.Valid && midpoint >= current.Int32 {
+		midpoint++
+	}
+	comparison, err := qtx.GetMovieLogEntryAtRank(ctx, db.GetMovieLogEntryAtRankParams{
+		UserID:       userID,
+		RankPosition: midpoint,
+	})
+	if err != nil {
+		return RankSessionResponse{}, fmt.Errorf("get comparison entry: %w", err)
+	}
+
+	session, err := qtx.UpsertRankSession(ctx, db.UpsertRankSessionParams{
+		UserID:          userID,
+		LogID:           logID,
+		LowPosition:     low,
+		HighPosition:    high,
+		ComparisonLogID: pgtype.Int8{Int64: comparison.LogID, Valid: true},
+		Sentiment:       sentiment,
+	})
+	if err != nil {
+		return RankSessionResponse{}, fmt.Errorf("save rank session: %w", err)
+	}
+
+	return RankSessionResponse{
+		SessionID: &session.ID,
+		LogID:     logID,
+		Status:    "comparing",
+		Comparison: &RankEntryResponse{
+			LogID:         comparison.LogID,
+			MovieID:       comparison.MovieID,
+			OriginalTitle: comparison.OriginalTitle,
+			RankPosition:  int4Ptr(comparison.RankPosition),
+			Sentiment:     textPtr(comparison.Sentiment),
+		},
+	}, nil
+}
+
+// rankSearchPosition converts a ranked position into the positions a session
+// searches over, which leave out the entry being placed while it is still
+// ranked at current.
+func rankSearchPosition(current pgtype.Int4, position int32) int32 {
+	if current.Valid && position > current.Int32 {
+		return position - 1
+	}
+	return position
+}
+
+// loadRankSessionResponse describes a stored session. The comparison is nil
+// when the compared entry has been unranked or deleted since it was chosen.
+func loadRankSessionResponse(ctx context.Context, queries *db.Queries, session db.RankSession) (RankSessionResponse, error) {
+	response := RankSessionResponse{
+		SessionID: &session.ID,
+		LogID:     session.LogID,
+		Status:    "comparing",
+	}
+	if !session.ComparisonLogID.Valid {
+		return response, nil
+	}
+
+	comparison, err := queries.GetMovieLogRankEntry(ctx, db.GetMovieLogRankEntryParams{
+		ID:     session.ComparisonLogID.Int64,
+		UserID: session.UserID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return response, nil
+		}
+		return RankSessionResponse{}, fmt.Errorf("get comparison entry: %w", err)
+	}
+	if !comparison.RankPosition.Valid {
+		return response, nil
+	}
+
+	response.Comparison = &RankEntryResponse{
+		LogID:         comparison.LogID,
+		MovieID:       comparison.MovieID,
+		OriginalTitle: comparison.OriginalTitle,
+		RankPosition:  int4Ptr(comparison.RankPosition),
+		Sentiment:     textPtr(comparison.Sentiment),
+	}
+	return response, nil
+}
+
+// moveToRank ranks a log entry at position, moving it out of its current slot
+// first when it is already ranked. An empty sentiment keeps the entry's
+// current band; the position must fall inside the band it ends up in.
+func moveToRank(ctx context.Context, qtx *db.Queries, userID, logID int64, sentiment string, position int32) (int32, error) {
+	entry, err := qtx.GetMovieLogRankEntry(ctx, db.GetMovieLogRankEntryParams{
+		ID:     logID,
+		UserID: userID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, errLogEntryNotFound
+		}
+		return 0, fmt.Errorf("get log entry: %w", err)
+	}
+
+	if sentiment == "" {
+		sentiment = entry.Sentiment.String
+	}
+	if sentimentBand(sentiment) < 0 {
+		return 0, errSentimentRequired
+	}
+
+	if entry.RankPosition.Valid {
+		if err := removeFromRank(ctx, qtx, userID, logID, entry.RankPosition.Int32); err != nil {
+			return 0, err
+		}
+	}
+
+	start, end, _, err := rankBandBounds(ctx, qtx, userID, sentiment)
+	if err != nil {
+		return 0, err
+	}
+	if position < start || position > end+1 {
+		return 0, fmt.Errorf("%w: %s entries can take positions %d-%d", errRankOutsideBand, sentiment, start, end+1)
+	}
+
+	// A direct placement supersedes any comparison session for the entry.
+	if err := qtx.DeleteRankSessionByLogID(ctx, logID); err != nil {
+		return 0, fmt.Errorf("delete rank session: %w", err)
+	}
+
+	return insertAtRank(ctx, qtx, userID, logID, sentiment, position)
+}
+
+// swapRanks exchanges the positions of two ranked log entries.
+func swapRanks(ctx context.Context, qtx *db.Queries, userID, logID, otherLogID int64) error {
+	entry, err := qtx.GetMovieLogRankEntry(ctx, db.GetMovieLogRankEntryParams{
+		ID:     logID,
+		UserID: userID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errLogEntryNotFound
+		}
+		return fmt.Errorf("get log entry: %w", err)
+	}
+
+	other, err := qtx.GetMovieLogRankEntry(ctx, db.GetMovieLogRankEntryParams{
+		ID:     otherLogID,
+		UserID: userID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errLogEntryNotFound
+		}
+		return fmt.Errorf("get log entry: %w", err)
+	}
+
+	if !entry.RankPosition.Valid || !other.RankPosition.Valid {
+		return errLogEntryNotRanked
+	}
+	if entry.Sentiment != other.Sentiment {
+		return errRankBandMismatch
+	}
+
+	updates := []db.SetMovieLogRankPositionParams{
+		{RankPosition: pgtype.Int4{}, ID: entry.LogID, UserID: userID},
+		{RankPosition: entry.RankPosition, ID: other.LogID, UserID: userID},
+		{RankPosition: other.RankPosition, ID: entry.LogID, UserID: userID},
+	}
+	for _, update := range updates {
+		if err := qtx.SetMovieLogRankPosition(ctx, update); err != nil {
+			return fmt.Errorf("set rank position: %w", err)
+		}
+	}
+	return nil
+}
+
+// replaceRankOrder makes logIDs the user's complete ranking, in order. Entries
+// missing from logIDs stay in the log but become unranked. Every listed entry
+// needs a sentiment, and the list has to keep the bands in order.
+func replaceRankOrder(ctx context.Context, qtx *db.Queries, userID int64, logIDs []int64) error {
+	sentiments, err := qtx.ListMovieLogSentiments(ctx, db.ListMovieLogSentimentsParams{
+		UserID: userID,
+		LogIds: logIDs,
+	})
+	if err != nil {
+		return fmt.Errorf("list sentiments: %w", err)
+	}
+	if len(sentiments) != len(logIDs) {
+		return errLogEntryNotFound
+	}
+
+	bandByLogID := make(map[int64]int, len(sentiments))
+	for _, row := range sentiments {
+		bandByLogID[row.ID] = sentimentBand(row.Sentiment.String)
+	}
+	previousBand := 0
+	for _, logID := range logIDs {
+		band := bandByLogID[logID]
+		if band < 0 {
+			return errSentimentRequired
+		}
+		if band < previousBand {
+			return errRankBandOrder
+		}
+		previousBand = band
+	}
+
+	if err := qtx.ClearRankPositions(ctx, userID); err != nil {
+		return fmt.Errorf("clear rank positions: %w", err)
+	}
+
+	updated, err := qtx.ApplyRankOrder(ctx, db.ApplyRankOrderParams{
+		LogIds: logIDs,
+		UserID: userID,
+	})
+	if err != nil {
+		return fmt.Errorf("apply rank order: %w", err)
+	}
+	if updated != int64(len(logIDs)) {
+		return errLogEntryNotFound
+	}
+
+	if err := qtx.DeleteRankSessionsByLogIDs(ctx, logIDs); err != nil {
+		return fmt.Errorf("delete rank sessions: %w", err)
+	}
+	return nil
+}
+
+func listRankEntries(ctx context.Context, queries *db.Queries, userID int64) ([]RankEntryResponse, string, error) {
+	return listVisibleRankEntries(ctx, queries, userID, logAccess{owner: true})
+}
+
+// listVisibleRankEntries lists the ranked entries access allows. Only the
+// owner gets the ranking's ETag, since it fingerprints hidden entries too.
+func listVisibleRankEntries(ctx context.Context, queries *db.Queries, userID int64, access logAccess) ([]RankEntryResponse, string, error) {
+	results, err := queries.ListRankedMovieLogByUser(ctx, userID)
+	if err != nil {
+		return nil, "", fmt.Errorf("list ranked entries: %w", err)
+	}
+
+	entries := make([]RankEntryResponse, 0, len(results))
+	for _, item := range results {
+		if access.canSee(item.Visibility) {
+			entries = append(entries, toRankEntryResponse(item))
+		}
+	}
+	if !access.owner {
+		return entries, "", nil
+	}
+	return entries, rankingETag(results), nil
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/labstack/echo/v4"
+)
+
+// rateLimitSweepInterval is how often a store drops buckets that have been
+// idle long enough to be full again.
+const rateLimitSweepInterval = 10 * time.Minute
+
+// rateLimit allows Burst requests at once, refilled evenly over Period. A
+// zero Burst means no limit.
+type rateLimit struct {
+	Burst  int
+	Period time.Duration
+}
+
+func (l rateLimit) perSecond() float64 {
+	return float64(l.Burst) / l.Period.Seconds()
+}
+
+// rateLimitGroupLimits applies per IP address to every request, and per user
+// as well to signed-in ones.
+type rateLimitGroupLimits struct {
+	PerIP   rateLimit
+	PerUser rateLimit
+}
+
+type rateLimitResult struct {
+	allowed    bool
+	limit      int
+	remaining  int
+	reset      time.Duration
+	retryAfter time.Duration
+}
+
+// tokenBucket is one caller's budget within a route group.
+type tokenBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+func newTokenBucket(limit rateLimit, now time.Time) tokenBucket {
+	return tokenBucket{tokens: float64(limit.Burst), updatedAt: now}
+}
+
+// take refills the bucket for the time since it was last used and spends one
+// token if there is one. A now before the last use, as from a clock that went
+// back, refills nothing and leaves updatedAt where it was.
+func (b *tokenBucket) take(limit rateLimit, now time.Time) rateLimitResult {
+	burst := float64(limit.Burst)
+	perSecond := limit.perSecond()
+	if elapsed := now.Sub(b.updatedAt).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(burst, b.tokens+elapsed*perSecond)
+		b.updatedAt = now
+	}
+
+	result := rateLimitResult{limit: limit.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.allowed = true
+	} else {
+		result.retryAfter = secondsDuration((1 - b.tokens) / perSecond)
+	}
+	result.remaining = int(b.tokens)
+	result.reset = secondsDuration((burst - b.tokens) / perSecond)
+	return result
+}
+
+func secondsDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// rateLimitStore keeps token buckets by key. The memory store suits a single
+// instance; the Postgres store shares buckets between instances and times
+// them by the database clock, so instances with skewed clocks agree.
+type rateLimitStore interface {
+	take(ctx context.Context, key string, limit rateLimit) (rateLimitResult, error)
+}
+
+func newRateLimitStore(config rateLimitConfig, pool *pgxpool.Pool, queries *db.Queries) rateLimitStore {
+	if config.Store == "postgres" {
+		return &postgresRateLimitStore{pool: pool, queries: queries, idleAfter: config.idleAfter()}
+	}
+	return &memoryRateLimitStore{buckets: map[string]tokenBucket{}, idleAfter: config.idleAfter()}
+}
+
+type memoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]tokenBucket
+	idleAfter time.Duration
+	lastSweep time.Time
+}
+
+func (s *memoryRateLimitStore) take(_ context.Context, key string, limit rateLimit) (rateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastSweep) > rateLimitSweepInterval {
+		for bucketKey, bucket := range s.buckets {
+			if now.Sub(bucket.updatedAt) > s.idleAfter {
+				delete(s.buckets, bucketKey)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = newTokenBucket(limit, now)
+	}
+	result := bucket.take(limit, now)
+	s.buckets[key] = bucket
+	return result, nil
+}
+
+type postgresRateLimitStore struct {
+	pool      *pgxpool.Pool
+	queries   *db.Queries
+	idleAfter time.Duration
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+func (s *postgresRateLimitStore) take(ctx context.Context, key string, limit rateLimit) (rateLimitResult, error) {
+	s.sweep(ctx)
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return rateLimitResult{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+	if err := qtx.EnsureRateLimitBucket(ctx, db.EnsureRateLimitBucketParams{
+		Key:    key,
+		Tokens: float64(limit.Burst),
+	}); err != nil {
+		return rateLimitResult{}, fmt.Errorf("ensure bucket: %w", err)
+	}
+	row, err := qtx.LockRateLimitBucket(ctx, key)
+	if err != nil {
+		return rateLimitResult{}, fmt.Errorf("lock bucket: %w", err)
+	}
+
+	bucket := tokenBucket{tokens: row.Tokens, updatedAt: row.UpdatedAt.Time}
+	result := bucket.take(limit, row.Now.Time)
+	if err := qtx.UpdateRateLimitBucket(ctx, db.UpdateRateLimitBucketParams{
+		Tokens:    bucket.tokens,
+		UpdatedAt: pgtype.Timestamptz{Time: bucket.updatedAt, Valid: true},
+		Key:       key,
+	}); err != nil {
+		return rateLimitResult{}, fmt.Errorf("update bucket: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return rateLimitResult{}, fmt.Errorf("commit transaction: %w", err)
+	}
+	return result, nil
+}
+
+// sweep deletes idle buckets at most once per rateLimitSweepInterval from
+// this instance. Only the interval is timed locally; idleness is judged by
+// the database clock.
+func (s *postgresRateLimitStore) sweep(ctx context.Context) {
+	now := time.Now()
+	s.mu.Lock()
+	due := now.Sub(s.lastSweep) > rateLimitSweepInterval
+	if due {
+		s.lastSweep = now
+	}
+	s.mu.Unlock()
+	if !due {
+		return
+	}
+
+	if _, err := s.queries.DeleteIdleRateLimitBuckets(ctx, s.idleAfter.Seconds()); err != nil {
+		log.Printf("delete idle rate limit buckets error: %v", err)
+	}
+}
+
+// rateLimitGroupFor names the route group a request is limited under, or
+// returns "" for requests that are not limited.
+func rateLimitGroupFor(path, method string) string {
+	switch {
+	case path == "/api/movies/search":
+		return "search"
+	case method == http.MethodGet, method == http.MethodHead, method == http.MethodOptions:
+		return ""
+	case strings.HasPrefix(path, "/api/"):
+		return "write"
+	}
+	return ""
+}
+
+// rateLimitKey is one bucket a request spends a token from.
+type rateLimitKey struct {
+	limit rateLimit
+	key   string
+}
+
+// rateLimiter applies the configured token-bucket limits and reports them in
+// RateLimit-* headers. Every request spends from its IP address's bucket, and
+// a signed-in one from its user's bucket too, so signing in to many accounts
+// from one address does not multiply its budget. The headers report whichever
+// bucket is closest to running out. If the store fails, requests are let
+// through rather than taking the API down with it.
+func rateLimiter(store rateLimitStore, config rateLimitConfig) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			group := rateLimitGroupFor(c.Path(), c.Request().Method)
+			if group == "" {
+				return next(c)
+			}
+
+			limits := config.Groups[group]
+			keys := []rateLimitKey{{limits.PerIP, group + ":ip:" + c.RealIP()}}
+			if user, ok := sessionUser(c); ok {
+				keys = append(keys, rateLimitKey{limits.PerUser, group + ":user:" + strconv.FormatInt(user.ID, 10)})
+			}
+
+			var result rateLimitResult
+			limited := false
+			for _, key := range keys {
+				if key.limit.Burst == 0 {
+					continue
+				}
+				taken, err := store.take(c.Request().Context(), key.key, key.limit)
+				if err != nil {
+					if !errors.Is(err, context.Canceled) {
+						log.Printf("rate limit error: %v", err)
+					}
+					continue
+				}
+				if !limited || !taken.allowed || taken.remaining < result.remaining {
+					result = taken
+				}
+				limited = true
+				if !taken.allowed {
+					break
+				}
+			}
+			if !limited {
+				return next(c)
+			}
+
+			header := c.Response().Header()
+			header.Set("RateLimit-Limit", strconv.Itoa(result.limit))
+			header.Set("RateLimit-Remaining", strconv.Itoa(result.remaining))
+			header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.reset)))
+			if !result.allowed {
+				header.Set(echo.HeaderRetryAfter, strconv.Itoa(max(1, ceilSeconds(result.retryAfter))))
+				return c.JSON(http.StatusTooManyRequests, map[string]string{
+					"error": "too many requests",
+				})
+			}
+			return next(c)
+		}
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
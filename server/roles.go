@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/labstack/echo/v4"
+)
+
+// userRoles are the roles a user can hold, from least to most privileged.
+// Moderators may remove any comment; admins may also use /api/admin.
+var userRoles = []string{"user", "moderator", "admin"}
+
+var (
+	errInvalidRole      = errors.New("role must be user, moderator or admin")
+	errChangeOwnRole    = errors.New("admins cannot change their own role")
+	errGrantAdminUsage  = errors.New("usage: moviestack grant-admin <username>")
+	errSetPasswordUsage = errors.New("usage: moviestack set-password <username> < password-file")
+)
+
+// hasRole reports whether role is at least as privileged as minimum.
+func hasRole(role, minimum string) bool {
+	return slices.Index(userRoles, role) >= slices.Index(userRoles, minimum)
+}
+
+// requireAdmin only lets admins reach routes under /api/admin. Anonymous
+// callers get the same 403 as signed-in users without the role, so every
+// denied admin request looks alike.
+func requireAdmin(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if !strings.HasPrefix(c.Path(), "/api/admin/") {
+			return next(c)
+		}
+
+		user, ok := sessionUser(c)
+		if !ok || !hasRole(user.Role, "admin") {
+			return roleRequiredResponse(c, "admin")
+		}
+		return next(c)
+	}
+}
+
+func roleRequiredResponse(c echo.Context, role string) error {
+	return c.JSON(http.StatusForbidden, map[string]string{
+		"error": role + " role required",
+	})
+}
+
+// setUserRole changes another user's role on behalf of actorID, recording the
+// change in the audit log.
+func setUserRole(ctx context.Context, pool *pgxpool.Pool, queries *db.Queries, source auditSource, actorID, userID int64, role string) (db.User, error) {
+	if !slices.Contains(userRoles, role) {
+		return db.User{}, errInvalidRole
+	}
+	if actorID == userID {
+		return db.User{}, errChangeOwnRole
+	}
+
+	var user db.User
+	err := withUserTx(ctx, pool, queries, userID, func(qtx *db.Queries) error {
+		before, err := qtx.GetUser(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("get user: %w", err)
+		}
+		user, err = qtx.SetUserRole(ctx, db.SetUserRoleParams{
+			Role: role,
+			ID:   userID,
+		})
+		if err != nil {
+			return fmt.Errorf("set user role: %w", err)
+		}
+		return recordAudit(ctx, qtx, source, auditEvent{
+			Action:     auditUserRoleChange,
+			TargetType: "user",
+			TargetID:   auditTarget(userID),
+			Before:     before,
+			After:      user,
+		})
+	})
+	return user, err
+}
+
+// runCommand runs a maintenance command given on the command line instead of
+// starting the server. grant-admin makes an existing user an admin, which is
+// how the first admin is created. set-password gives a user the password read
+// from the first line of stdin, so it stays out of the shell history.
+func runCommand(ctx context.Context, pool *pgxpool.Pool, queries *db.Queries, args []string) error {
+	switch args[0] {
+	case "grant-admin":
+		if len(args) != 2 {
+			return errGrantAdminUsage
+		}
+		userID, err := commandUserID(ctx, queries, args[1])
+		if err != nil {
+			return err
+		}
+		user, err := setUserRole(ctx, pool, queries, auditSource{}, 0, userID, "admin")
+		if err != nil {
+			return fmt.Errorf("grant admin: %w", err)
+		}
+		fmt.Printf("%s (id %d) is now an admin\n", user.Username, user.ID)
+		return nil
+	case "set-password":
+		if len(args) != 2 {
+			return errSetPasswordUsage
+		}
+		userID, err := commandUserID(ctx, queries, args[1])
+		if err != nil {
+			return err
+		}
+		password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("read password: %w", err)
+		}
+		password = strings.TrimRight(password, "\r\n")
+		if err := setUserPassword(ctx, pool, queries, auditSource{}, userID, password); err != nil {
+			return fmt.Errorf("set password: %w", err)
+		}
+		fmt.Printf("password set for %s (id %d)\n", args[1], userID)
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
+func commandUserID(ctx context.Context, queries *db.Queries, username string) (int64, error) {
+	userID, err := queries.GetUserIDByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, fmt.Errorf("user %q not found", username)
+		}
+		return 0, fmt.Errorf("get user: %w", err)
+	}
+	return userID, nil
+}
@@ -9,11 +9,11 @@ import (
 
 	db "github.com/seanlee/moviestack/db/sqlc"
 
-	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/labstack/echo/v4"
 )
 
-func registerAdminUserRoutes(e *echo.Echo, queries *db.Queries) {
+func registerAdminUserRoutes(e *echo.Echo, queries *db.Queries, pool *pgxpool.Pool) {
 	e.GET("/api/admin/users", func(c echo.Context) error {
 		results, err := queries.ListUsers(c.Request().Context())
 		if err != nil {
@@ -46,12 +46,11 @@ func registerAdminUserRoutes(e *echo.Echo, queries *db.Queries) {
 			})
 		}
 
-		user, err := queries.CreateUser(c.Request().Context(), username)
+		user, err := createUser(c.Request().Context(), pool, queries, auditSourceFrom(c), username)
 		if err != nil {
-			var pgErr *pgconn.PgError
-			if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "users_username_lower_unique" {
+			if errors.Is(err, errUsernameTaken) {
 				return c.JSON(http.StatusConflict, map[string]string{
-					"error": "username already exists",
+					"error": err.Error(),
 				})
 			}
 
@@ -72,20 +71,124 @@ func registerAdminUserRoutes(e *echo.Echo, queries *db.Queries) {
 			})
 		}
 
-		rowsAffected, err := queries.DeleteUser(c.Request().Context(), id)
-		if err != nil {
+		if err := deleteUser(c.Request().Context(), pool, queries, auditSourceFrom(c), id); err != nil {
+			if errors.Is(err, errUserNotFound) {
+				return c.JSON(http.StatusNotFound, map[string]string{
+					"error": "user not found",
+				})
+			}
 			log.Printf("delete user error: %v", err)
 			return c.JSON(http.StatusInternalServerError, map[string]string{
 				"error": "failed to delete user",
 			})
 		}
 
-		if rowsAffected == 0 {
-			return c.JSON(http.StatusNotFound, map[string]string{
-				"error": "user not found",
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	e.PUT("/api/admin/users/:id/role", func(c echo.Context) error {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		var req SetUserRoleRequest
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid request body",
+			})
+		}
+
+		actor, _ := sessionUser(c)
+		user, err := setUserRole(c.Request().Context(), pool, queries, auditSourceFrom(c), actor.ID, id, req.Role)
+		if err != nil {
+			switch {
+			case errors.Is(err, errInvalidRole), errors.Is(err, errChangeOwnRole):
+				return c.JSON(http.StatusBadRequest, map[string]string{
+					"error": err.Error(),
+				})
+			case errors.Is(err, errUserNotFound):
+				return c.JSON(http.StatusNotFound, map[string]string{
+					"error": "user not found",
+				})
+			}
+
+			log.Printf("set user role error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to set user role",
+			})
+		}
+
+		return c.JSON(http.StatusOK, toAdminUserResponse(user))
+	})
+
+	// Admin-created users start without a password; this gives them one, or
+	// resets a forgotten one.
+	e.PUT("/api/admin/users/:id/password", func(c echo.Context) error {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		var req SetUserPasswordRequest
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid request body",
+			})
+		}
+
+		if err := setUserPassword(c.Request().Context(), pool, queries, auditSourceFrom(c), id, req.Password); err != nil {
+			switch {
+			case errors.Is(err, errInvalidPassword):
+				return c.JSON(http.StatusBadRequest, map[string]string{
+					"error": err.Error(),
+				})
+			case errors.Is(err, errUserNotFound):
+				return c.JSON(http.StatusNotFound, map[string]string{
+					"error": "user not found",
+				})
+			}
+
+			log.Printf("set user password error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to set user password",
 			})
 		}
 
 		return c.NoContent(http.StatusNoContent)
 	})
+
+	e.GET("/api/admin/audit", func(c echo.Context) error {
+		params, limit, err := parseAuditParams(c)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		events, err := queries.ListAuditEvents(c.Request().Context(), params)
+		if err != nil {
+			log.Printf("list audit events error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to list audit events",
+			})
+		}
+
+		response := AuditEventPageResponse{}
+		if len(events) > limit {
+			events = events[:limit]
+			nextCursor := encodeTimelineCursor(events[limit-1].CreatedAt, events[limit-1].ID)
+			response.NextCursor = &nextCursor
+		}
+		response.Events = make([]AuditEventResponse, len(events))
+		for i, event := range events {
+			response.Events[i] = toAuditEventResponse(event)
+		}
+
+		return c.JSON(http.StatusOK, response)
+	})
 }
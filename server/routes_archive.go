@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/labstack/echo/v4"
+)
+
+func registerArchiveRoutes(e *echo.Echo, queries *db.Queries, pool *pgxpool.Pool) {
+	e.GET("/api/users/:userId/archive", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		ctx := c.Request().Context()
+		tx, err := pool.BeginTx(ctx, pgx.TxOptions{
+			IsoLevel:   pgx.RepeatableRead,
+			AccessMode: pgx.ReadOnly,
+		})
+		if err != nil {
+			log.Printf("begin archive transaction error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to build archive",
+			})
+		}
+		defer tx.Rollback(ctx)
+
+		archive, err := buildAccountArchive(ctx, queries.WithTx(tx), userID)
+		if err != nil {
+			if errors.Is(err, errUserNotFound) {
+				return c.JSON(http.StatusNotFound, map[string]string{
+					"error": "user not found",
+				})
+			}
+			log.Printf("build archive error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to build archive",
+			})
+		}
+
+		c.Response().Header().Set(echo.HeaderContentDisposition,
+			fmt.Sprintf(`attachment; filename="moviestack-archive-%d.json"`, userID))
+		return c.JSON(http.StatusOK, archive)
+	})
+
+	e.POST("/api/users/:userId/archive/restore", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, maxAccountArchiveSize)
+		var archive AccountArchive
+		if err := c.Bind(&archive); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid archive",
+			})
+		}
+		if archive.Format != accountArchiveFormat || archive.Version != accountArchiveVersion {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": errArchiveUnsupported.Error(),
+			})
+		}
+		if _, err := parseVisibility(archive.Profile.LogVisibility); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "profile log_visibility must be public, friends or private",
+			})
+		}
+
+		ctx := c.Request().Context()
+		var response ArchiveRestoreResponse
+		var requested []int64
+		err = withUserTx(ctx, pool, queries, userID, func(qtx *db.Queries) error {
+			response, requested, err = restoreAccountArchive(ctx, qtx, userID, archive)
+			return err
+		})
+		if err != nil {
+			if errors.Is(err, errUserNotFound) {
+				return c.JSON(http.StatusNotFound, map[string]string{
+					"error": "user not found",
+				})
+			}
+			log.Printf("restore archive error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to restore archive",
+			})
+		}
+
+		// Notifications go out after the commit: a failed insert inside the
+		// transaction would abort the whole restore.
+		for _, followeeID := range requested {
+			notify(ctx, queries, db.CreateNotificationParams{
+				UserID:  followeeID,
+				ActorID: userID,
+				Kind:    "follow_request",
+			})
+		}
+
+		return c.JSON(http.StatusOK, response)
+	})
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/labstack/echo/v4"
+)
+
+func registerAuthRoutes(e *echo.Echo, queries *db.Queries, pool *pgxpool.Pool, secureCookies bool) {
+	e.POST("/api/auth/signup", func(c echo.Context) error {
+		var req AuthCredentialsRequest
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid request body",
+			})
+		}
+
+		username := strings.TrimSpace(req.Username)
+		if username == "" {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "username is required",
+			})
+		}
+
+		user, err := signUp(c.Request().Context(), pool, queries, auditSourceFrom(c), username, req.Password)
+		if err != nil {
+			return authErrorResponse(c, err, "sign up", "failed to sign up")
+		}
+		if err := startSession(c, queries, user.ID, secureCookies); err != nil {
+			return authErrorResponse(c, err, "start session", "failed to sign in")
+		}
+
+		return c.JSON(http.StatusCreated, toAuthUserResponse(user.ID, user.Username, user.DisplayName, user.AvatarUrl, user.Role))
+	})
+
+	e.POST("/api/auth/login", func(c echo.Context) error {
+		var req AuthCredentialsRequest
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid request body",
+			})
+		}
+
+		ctx := c.Request().Context()
+		userID, err := logIn(ctx, queries, strings.TrimSpace(req.Username), req.Password)
+		if err != nil {
+			return authErrorResponse(c, err, "log in", "failed to sign in")
+		}
+
+		user, err := queries.GetUser(ctx, userID)
+		if err != nil {
+			return authErrorResponse(c, err, "get user", "failed to sign in")
+		}
+		if err := startSession(c, queries, user.ID, secureCookies); err != nil {
+			return authErrorResponse(c, err, "start session", "failed to sign in")
+		}
+
+		return c.JSON(http.StatusOK, toAuthUserResponse(user.ID, user.Username, user.DisplayName, user.AvatarUrl, user.Role))
+	})
+
+	e.POST("/api/auth/logout", func(c echo.Context) error {
+		if err := endSession(c, queries, secureCookies); err != nil {
+			return authErrorResponse(c, err, "end session", "failed to sign out")
+		}
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	e.GET("/api/auth/me", func(c echo.Context) error {
+		user, ok := sessionUser(c)
+		if !ok {
+			return authRequiredResponse(c)
+		}
+		return c.JSON(http.StatusOK, toAuthUserResponse(user.ID, user.Username, user.DisplayName, user.AvatarUrl, user.Role))
+	})
+}
+
+func authErrorResponse(c echo.Context, err error, operation, message string) error {
+	switch {
+	case errors.Is(err, errInvalidCredentials):
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": err.Error(),
+		})
+	case errors.Is(err, errInvalidPassword):
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	case errors.Is(err, errUsernameTaken):
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	log.Printf("%s error: %v", operation, err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{
+		"error": message,
+	})
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+)
+
+func registerCommentRoutes(e *echo.Echo, queries *db.Queries) {
+	e.GET("/api/users/:userId/log/:logId/comments", func(c echo.Context) error {
+		userID, logID, err := parseLogEntryPath(c)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		access, err := requestLogAccess(c, queries, userID)
+		if err != nil {
+			return logAccessErrorResponse(c, err)
+		}
+		if err := checkLogEntryVisible(c.Request().Context(), queries, access, userID, logID); err != nil {
+			return logEntryVisibleErrorResponse(c, err)
+		}
+
+		rows, err := queries.ListLogComments(c.Request().Context(), logID)
+		if err != nil {
+			log.Printf("list comments error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to list comments",
+			})
+		}
+
+		return c.JSON(http.StatusOK, buildCommentThreads(rows))
+	})
+
+	e.POST("/api/users/:userId/log/:logId/comments", func(c echo.Context) error {
+		userID, logID, err := parseLogEntryPath(c)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		actor, ok := sessionUser(c)
+		if !ok {
+			return authRequiredResponse(c)
+		}
+
+		var req CreateLogCommentRequest
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid request body",
+			})
+		}
+
+		comment, err := createLogComment(c.Request().Context(), queries, userID, logID, actor.ID, req)
+		if err != nil {
+			return commentErrorResponse(c, err, "create comment", "failed to create comment")
+		}
+
+		return c.JSON(http.StatusCreated, toLogCommentResponse(comment))
+	})
+
+	e.PATCH("/api/users/:userId/log/:logId/comments/:commentId", func(c echo.Context) error {
+		userID, logID, err := parseLogEntryPath(c)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		commentID, err := strconv.ParseInt(c.Param("commentId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid comment id",
+			})
+		}
+
+		actor, ok := sessionUser(c)
+		if !ok {
+			return authRequiredResponse(c)
+		}
+
+		var req UpdateLogCommentRequest
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid request body",
+			})
+		}
+
+		comment, err := editLogComment(c.Request().Context(), queries, userID, logID, commentID, actor.ID, req)
+		if err != nil {
+			return commentErrorResponse(c, err, "update comment", "failed to update comment")
+		}
+
+		return c.JSON(http.StatusOK, toLogCommentResponse(comment))
+	})
+
+	e.DELETE("/api/users/:userId/log/:logId/comments/:commentId", func(c echo.Context) error {
+		userID, logID, err := parseLogEntryPath(c)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		commentID, err := strconv.ParseInt(c.Param("commentId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid comment id",
+			})
+		}
+
+		actor, ok := sessionUser(c)
+		if !ok {
+			return authRequiredResponse(c)
+		}
+
+		if err := deleteLogComment(c.Request().Context(), queries, userID, logID, commentID, actor); err != nil {
+			return commentErrorResponse(c, err, "delete comment", "failed to delete comment")
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	e.GET("/api/users/:userId/log/:logId/likes", func(c echo.Context) error {
+		userID, logID, err := parseLogEntryPath(c)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		access, err := requestLogAccess(c, queries, userID)
+		if err != nil {
+			return logAccessErrorResponse(c, err)
+		}
+		if err := checkLogEntryVisible(c.Request().Context(), queries, access, userID, logID); err != nil {
+			return logEntryVisibleErrorResponse(c, err)
+		}
+
+		rows, err := queries.ListMovieLogLikes(c.Request().Context(), logID)
+		if err != nil {
+			log.Printf("list likes error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to list likes",
+			})
+		}
+
+		response := make([]LogLikeResponse, len(rows))
+		for i, row := range rows {
+			response[i] = toLogLikeResponse(row)
+		}
+		return c.JSON(http.StatusOK, response)
+	})
+
+	e.POST("/api/users/:userId/log/:logId/likes", func(c echo.Context) error {
+		userID, logID, err := parseLogEntryPath(c)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		actor, ok := sessionUser(c)
+		if !ok {
+			return authRequiredResponse(c)
+		}
+
+		ctx := c.Request().Context()
+		if err := checkLogEntryActor(ctx, queries, userID, logID, actor.ID); err != nil {
+			return commentErrorResponse(c, err, "like log entry", "failed to like movie log entry")
+		}
+
+		// Liking twice is harmless; the second like just reports the counts.
+		added, err := queries.LikeMovieLogEntry(ctx, db.LikeMovieLogEntryParams{
+			LogID:  logID,
+			UserID: actor.ID,
+		})
+		if err != nil {
+			return commentErrorResponse(c, err, "like log entry", "failed to like movie log entry")
+		}
+
+		engagement, err := queries.GetMovieLogEngagement(ctx, logID)
+		if err != nil {
+			return commentErrorResponse(c, err, "like log entry", "failed to like movie log entry")
+		}
+
+		status := http.StatusOK
+		if added > 0 {
+			status = http.StatusCreated
+			notify(ctx, queries, db.CreateNotificationParams{
+				UserID:  userID,
+				ActorID: actor.ID,
+				Kind:    "like",
+				LogID:   pgtype.Int8{Int64: logID, Valid: true},
+			})
+		}
+		return c.JSON(status, LogEngagementResponse{
+			LogID:        logID,
+			LikeCount:    engagement.LikeCount,
+			CommentCount: engagement.CommentCount,
+			LikedByMe:    true,
+		})
+	})
+
+	e.DELETE("/api/users/:userId/log/:logId/likes/:likerId", func(c echo.Context) error {
+		userID, logID, err := parseLogEntryPath(c)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		likerID, err := strconv.ParseInt(c.Param("likerId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid liker id",
+			})
+		}
+
+		// Only the user who liked an entry may take the like back.
+		actor, ok := sessionUser(c)
+		if !ok {
+			return authRequiredResponse(c)
+		}
+		if actor.ID != likerID {
+			return c.JSON(http.StatusForbidden, map[string]string{
+				"error": "not allowed to remove another user's like",
+			})
+		}
+
+		ctx := c.Request().Context()
+		if err := checkLogEntryActor(ctx, queries, userID, logID, likerID); err != nil {
+			return commentErrorResponse(c, err, "unlike log entry", "failed to unlike movie log entry")
+		}
+
+		removed, err := queries.UnlikeMovieLogEntry(ctx, db.UnlikeMovieLogEntryParams{
+			LogID:  logID,
+			UserID: likerID,
+		})
+		if err == nil && removed == 0 {
+			err = errLogLikeNotFound
+		}
+		if err != nil {
+			return commentErrorResponse(c, err, "unlike log entry", "failed to unlike movie log entry")
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	})
+}
+
+// parseLogEntryPath reads the userId and logId path parameters.
+func parseLogEntryPath(c echo.Context) (int64, int64, error) {
+	userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+	if err != nil {
+		return 0, 0, errors.New("invalid user id")
+	}
+
+	logID, err := strconv.ParseInt(c.Param("logId"), 10, 64)
+	if err != nil {
+		return 0, 0, errors.New("invalid log id")
+	}
+	return userID, logID, nil
+}
+
+func commentErrorResponse(c echo.Context, err error, operation, message string) error {
+	switch {
+	case errors.Is(err, errUserNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "user not found",
+		})
+	case errors.Is(err, errLogEntryNotFound),
+		errors.Is(err, errCommentNotFound),
+		errors.Is(err, errCommentParentNotFound),
+		errors.Is(err, errLogLikeNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": err.Error(),
+		})
+	case errors.Is(err, errInvalidCommentBody):
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	case errors.Is(err, errCommentForbidden):
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	log.Printf("%s error: %v", operation, err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{
+		"error": message,
+	})
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+)
+
+func registerCompareRoutes(e *echo.Echo, queries *db.Queries) {
+	// Each user's side of the comparison only draws on the entries both the
+	// other user and the caller are allowed to see.
+	e.GET("/api/users/:userId/compare/:otherUserId", func(c echo.Context) error {
+		userID, otherUserID, err := parseFriendPair(c)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		if userID == otherUserID {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "cannot compare a user with themselves",
+			})
+		}
+
+		limit := defaultCompareLimit
+		if raw := c.QueryParam("limit"); raw != "" {
+			limit, err = strconv.Atoi(raw)
+			if err != nil || limit < 1 || limit > maxCompareLimit {
+				return c.JSON(http.StatusBadRequest, map[string]string{
+					"error": fmt.Sprintf("limit must be between 1 and %d", maxCompareLimit),
+				})
+			}
+		}
+
+		ctx := c.Request().Context()
+		viewer := viewerID(c)
+		user, userLogged, err := comparedRanking(ctx, queries, userID, otherUserID, viewer)
+		if err != nil {
+			return compareErrorResponse(c, err)
+		}
+		other, otherLogged, err := comparedRanking(ctx, queries, otherUserID, userID, viewer)
+		if err != nil {
+			return compareErrorResponse(c, err)
+		}
+
+		response := compareRankings(user, other, userLogged, otherLogged, limit)
+		response.UserID = userID
+		response.OtherUserID = otherUserID
+		return c.JSON(http.StatusOK, response)
+	})
+}
+
+// comparedRanking loads the ranked entries and logged movies of userID that
+// both otherUserID and the caller may see. An invalid viewer stands for an
+// anonymous caller.
+func comparedRanking(ctx context.Context, queries *db.Queries, userID, otherUserID int64, viewer pgtype.Int8) ([]RankEntryResponse, map[int32]bool, error) {
+	access, err := resolveLogAccess(ctx, queries, userID, pgtype.Int8{Int64: otherUserID, Valid: true})
+	if err != nil {
+		return nil, nil, err
+	}
+	viewerAccess, err := resolveLogAccess(ctx, queries, userID, viewer)
+	if err != nil {
+		return nil, nil, err
+	}
+	access = access.intersect(viewerAccess)
+
+	entries, _, err := listVisibleRankEntries(ctx, queries, userID, access)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	movieIDs, err := queries.ListLoggedMovieIDsByUser(ctx, db.ListLoggedMovieIDsByUserParams{
+		UserID:       userID,
+		Visibilities: access.visibilities(),
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("list logged movies: %w", err)
+	}
+	logged := make(map[int32]bool, len(movieIDs))
+	for _, movieID := range movieIDs {
+		logged[movieID] = true
+	}
+	return entries, logged, nil
+}
+
+func compareErrorResponse(c echo.Context, err error) error {
+	if errors.Is(err, errUserNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "user not found",
+		})
+	}
+
+	log.Printf("compare users error: %v", err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{
+		"error": "failed to compare users",
+	})
+}
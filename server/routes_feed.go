@@ -0,0 +1,62 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/labstack/echo/v4"
+)
+
+func registerFeedRoutes(e *echo.Echo, queries *db.Queries) {
+	e.GET("/api/users/:userId/feed", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		params, limit, err := parseFeedParams(c, userID)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		userExists, err := queries.UserExists(c.Request().Context(), userID)
+		if err != nil {
+			log.Printf("user exists error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to verify user",
+			})
+		}
+		if !userExists {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "user not found",
+			})
+		}
+
+		rows, err := queries.ListFeedEvents(c.Request().Context(), params)
+		if err != nil {
+			log.Printf("list feed error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to load feed",
+			})
+		}
+
+		var nextCursor *string
+		if len(rows) > limit {
+			rows = rows[:limit]
+			cursor := encodeFeedCursor(rows[len(rows)-1])
+			nextCursor = &cursor
+		}
+
+		return c.JSON(http.StatusOK, FeedPageResponse{
+			Items:      groupFeedEvents(rows),
+			NextCursor: nextCursor,
+		})
+	})
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+)
+
+func registerFriendRoutes(e *echo.Echo, queries *db.Queries) {
+	e.GET("/api/users/:userId/friends", listFriendUsersHandler(queries, "list friends",
+		func(ctx context.Context, userID int64) ([]FriendUserResponse, error) {
+			rows, err := queries.ListFriends(ctx, userID)
+			response := make([]FriendUserResponse, len(rows))
+			for i, row := range rows {
+				response[i] = toFriendUserResponse(row.ID, row.Username, row.DisplayName, row.AvatarUrl, row.Since)
+			}
+			return response, err
+		}))
+
+	e.GET("/api/users/:userId/friends/followers", listFriendUsersHandler(queries, "list followers",
+		func(ctx context.Context, userID int64) ([]FriendUserResponse, error) {
+			rows, err := queries.ListFollowers(ctx, userID)
+			response := make([]FriendUserResponse, len(rows))
+			for i, row := range rows {
+				response[i] = toFriendUserResponse(row.ID, row.Username, row.DisplayName, row.AvatarUrl, row.Since)
+			}
+			return response, err
+		}))
+
+	e.GET("/api/users/:userId/friends/following", listFriendUsersHandler(queries, "list following",
+		func(ctx context.Context, userID int64) ([]FriendUserResponse, error) {
+			rows, err := queries.ListFollowing(ctx, userID)
+			response := make([]FriendUserResponse, len(rows))
+			for i, row := range rows {
+				response[i] = toFriendUserResponse(row.ID, row.Username, row.DisplayName, row.AvatarUrl, row.Since)
+			}
+			return response, err
+		}))
+
+	listIncoming := listFriendUsersHandler(queries, "list incoming follow requests",
+		func(ctx context.Context, userID int64) ([]FriendUserResponse, error) {
+			rows, err := queries.ListIncomingFollowRequests(ctx, userID)
+			response := make([]FriendUserResponse, len(rows))
+			for i, row := range rows {
+				response[i] = toFriendUserResponse(row.ID, row.Username, row.DisplayName, row.AvatarUrl, row.Since)
+			}
+			return response, err
+		})
+	listOutgoing := listFriendUsersHandler(queries, "list outgoing follow requests",
+		func(ctx context.Context, userID int64) ([]FriendUserResponse, error) {
+			rows, err := queries.ListOutgoingFollowRequests(ctx, userID)
+			response := make([]FriendUserResponse, len(rows))
+			for i, row := range rows {
+				response[i] = toFriendUserResponse(row.ID, row.Username, row.DisplayName, row.AvatarUrl, row.Since)
+			}
+			return response, err
+		})
+	e.GET("/api/users/:userId/friends/requests", func(c echo.Context) error {
+		switch c.QueryParam("direction") {
+		case "", "incoming":
+			return listIncoming(c)
+		case "outgoing":
+			return listOutgoing(c)
+		default:
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "direction must be incoming or outgoing",
+			})
+		}
+	})
+
+	e.POST("/api/users/:userId/friends/requests", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		var req FollowRequestRequest
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid request body",
+			})
+		}
+		if req.UserID <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "user_id is required",
+			})
+		}
+
+		friendship, err := sendFollowRequest(c.Request().Context(), queries, userID, req.UserID)
+		if err != nil {
+			return friendshipErrorResponse(c, err, "send follow request", "failed to send follow request")
+		}
+
+		return c.JSON(http.StatusCreated, toFriendshipResponse(friendship))
+	})
+
+	e.POST("/api/users/:userId/friends/requests/:otherUserId/accept", func(c echo.Context) error {
+		userID, otherUserID, err := parseFriendPair(c)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		friendship, err := queries.AcceptFollowRequest(c.Request().Context(), db.AcceptFollowRequestParams{
+			FollowerID: otherUserID,
+			FolloweeID: userID,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				err = errFollowRequestNotFound
+			}
+			return friendshipErrorResponse(c, err, "accept follow request", "failed to accept follow request")
+		}
+
+		notify(c.Request().Context(), queries, db.CreateNotificationParams{
+			UserID:  otherUserID,
+			ActorID: userID,
+			Kind:    "follow_accepted",
+		})
+
+		return c.JSON(http.StatusOK, toFriendshipResponse(friendship))
+	})
+
+	e.POST("/api/users/:userId/friends/requests/:otherUserId/decline", func(c echo.Context) error {
+		userID, otherUserID, err := parseFriendPair(c)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		deleted, err := queries.DeleteFollowRequest(c.Request().Context(), db.DeleteFollowRequestParams{
+			FollowerID: otherUserID,
+			FolloweeID: userID,
+		})
+		if err == nil && deleted == 0 {
+			err = errFollowRequestNotFound
+		}
+		if err != nil {
+			return friendshipErrorResponse(c, err, "decline follow request", "failed to decline follow request")
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	e.DELETE("/api/users/:userId/friends/requests/:otherUserId", func(c echo.Context) error {
+		userID, otherUserID, err := parseFriendPair(c)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		deleted, err := queries.DeleteFollowRequest(c.Request().Context(), db.DeleteFollowRequestParams{
+			FollowerID: userID,
+			FolloweeID: otherUserID,
+		})
+		if err == nil && deleted == 0 {
+			err = errFollowRequestNotFound
+		}
+		if err != nil {
+			return friendshipErrorResponse(c, err, "cancel follow request", "failed to cancel follow request")
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	e.DELETE("/api/users/:userId/friends/following/:otherUserId", func(c echo.Context) error {
+		userID, otherUserID, err := parseFriendPair(c)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		deleted, err := queries.DeleteFollow(c.Request().Context(), db.DeleteFollowParams{
+			FollowerID: userID,
+			FolloweeID: otherUserID,
+		})
+		if err == nil && deleted == 0 {
+			err = errFollowNotFound
+		}
+		if err != nil {
+			return friendshipErrorResponse(c, err, "unfollow", "failed to unfollow user")
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	e.DELETE("/api/users/:userId/friends/followers/:otherUserId", func(c echo.Context) error {
+		userID, otherUserID, err := parseFriendPair(c)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		deleted, err := queries.DeleteFollow(c.Request().Context(), db.DeleteFollowParams{
+			FollowerID: otherUserID,
+			FolloweeID: userID,
+		})
+		if err == nil && deleted == 0 {
+			err = errFollowNotFound
+		}
+		if err != nil {
+			return friendshipErrorResponse(c, err, "remove follower", "failed to remove follower")
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	e.GET("/api/users/:userId/friends/:otherUserId", func(c echo.Context) error {
+		userID, otherUserID, err := parseFriendPair(c)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		relationship, err := relationshipBetween(c.Request().Context(), queries, userID, otherUserID, viewerID(c))
+		if err != nil {
+			return friendshipErrorResponse(c, err, "get relationship", "failed to get relationship")
+		}
+
+		return c.JSON(http.StatusOK, relationship)
+	})
+}
+
+// listFriendUsersHandler serves one of a user's friend lists after checking
+// that the user exists.
+func listFriendUsersHandler(queries *db.Queries, operation string, list func(ctx context.Context, userID int64) ([]FriendUserResponse, error)) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		userExists, err := queries.UserExists(c.Request().Context(), userID)
+		if err != nil {
+			log.Printf("user exists error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to verify user",
+			})
+		}
+		if !userExists {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "user not found",
+			})
+		}
+
+		response, err := list(c.Request().Context(), userID)
+		if err != nil {
+			log.Printf("%s error: %v", operation, err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to " + operation,
+			})
+		}
+
+		return c.JSON(http.StatusOK, response)
+	}
+}
+
+// parseFriendPair reads the userId and otherUserId path parameters.
+func parseFriendPair(c echo.Context) (int64, int64, error) {
+	userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+	if err != nil {
+		return 0, 0, errors.New("invalid user id")
+	}
+
+	otherUserID, err := strconv.ParseInt(c.Param("otherUserId"), 10, 64)
+	if err != nil {
+		return 0, 0, errors.New("invalid other user id")
+	}
+	return userID, otherUserID, nil
+}
+
+func friendshipErrorResponse(c echo.Context, err error, operation, message string) error {
+	switch {
+	case errors.Is(err, errUserNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "user not found",
+		})
+	case errors.Is(err, errFollowRequestNotFound), errors.Is(err, errFollowNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": err.Error(),
+		})
+	case errors.Is(err, errFollowSelf):
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	case errors.Is(err, errFollowRequestExists):
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	log.Printf("%s error: %v", operation, err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{
+		"error": message,
+	})
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/labstack/echo/v4"
+)
+
+func registerLogExportRoutes(e *echo.Echo, queries *db.Queries, pool *pgxpool.Pool) {
+	e.GET("/api/users/:userId/log/export", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		format := c.QueryParam("format")
+		if format == "" {
+			format = "csv"
+		}
+		formatInfo, ok := movieLogExportFormats[format]
+		if !ok {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "format must be letterboxd, csv or json",
+			})
+		}
+
+		access, err := requestLogAccess(c, queries, userID)
+		if err != nil {
+			return logAccessErrorResponse(c, err)
+		}
+
+		// Every page is read from one repeatable-read snapshot, so an edit
+		// made mid-export cannot duplicate or drop entries.
+		ctx := c.Request().Context()
+		tx, err := pool.BeginTx(ctx, pgx.TxOptions{
+			IsoLevel:   pgx.RepeatableRead,
+			AccessMode: pgx.ReadOnly,
+		})
+		if err != nil {
+			log.Printf("begin export transaction error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to export movie log",
+			})
+		}
+		defer tx.Rollback(ctx)
+		qtx := queries.WithTx(tx)
+
+		bandSizes, err := loadRankBandSizes(ctx, qtx, userID)
+		if err != nil {
+			log.Printf("load rank bands error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to export movie log",
+			})
+		}
+
+		response := c.Response()
+		response.Header().Set(echo.HeaderContentType, formatInfo[0])
+		response.Header().Set(echo.HeaderContentDisposition,
+			fmt.Sprintf(`attachment; filename="moviestack-log-%d-%s.%s"`, userID, format, formatInfo[1]))
+		response.WriteHeader(http.StatusOK)
+
+		// Once streaming has started the status is already sent, so a failure
+		// can only be logged and the response cut short.
+		exporter := newMovieLogExporter(format, response)
+		if err := streamMovieLogExport(ctx, qtx, userID, access, bandSizes, exporter, response.Flush); err != nil {
+			log.Printf("export movie log error: %v", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,417 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/labstack/echo/v4"
+)
+
+func registerLogImportRoutes(e *echo.Echo, queries *db.Queries, pool *pgxpool.Pool) {
+	e.POST("/api/users/:userId/imports/letterboxd", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "file is required",
+			})
+		}
+		if fileHeader.Size > maxLetterboxdExportSize {
+			return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{
+				"error": "export file is too large",
+			})
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			log.Printf("open letterboxd upload error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to read export file",
+			})
+		}
+		defer file.Close()
+
+		data, err := io.ReadAll(io.LimitReader(file, maxLetterboxdExportSize))
+		if err != nil {
+			log.Printf("read letterboxd upload error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to read export file",
+			})
+		}
+
+		films, err := parseLetterboxdExport(data)
+		if err != nil {
+			if errors.Is(err, errLetterboxdExportEmpty) || errors.Is(err, errLetterboxdCSVTooLarge) {
+				return c.JSON(http.StatusBadRequest, map[string]string{
+					"error": err.Error(),
+				})
+			}
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "file is not a readable Letterboxd export",
+			})
+		}
+
+		ctx := c.Request().Context()
+		var logImport db.LogImport
+		err = withUserTx(ctx, pool, queries, userID, func(qtx *db.Queries) error {
+			logImport, err = runLogImport(ctx, qtx, auditSourceFrom(c), userID, "letterboxd", films)
+			return err
+		})
+		if err != nil {
+			if errors.Is(err, errUserNotFound) {
+				return c.JSON(http.StatusNotFound, map[string]string{
+					"error": "user not found",
+				})
+			}
+			log.Printf("letterboxd import error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to import Letterboxd export",
+			})
+		}
+
+		return c.JSON(http.StatusCreated, toLogImportResponse(logImport))
+	})
+
+	e.POST("/api/users/:userId/imports/imdb", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		seedRanking := false
+		if raw := c.FormValue("seed_ranking"); raw != "" {
+			seedRanking, err = strconv.ParseBool(raw)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{
+					"error": "seed_ranking must be true or false",
+				})
+			}
+		}
+
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "file is required",
+			})
+		}
+		if fileHeader.Size > maxIMDbExportSize {
+			return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{
+				"error": "export file is too large",
+			})
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			log.Printf("open imdb upload error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to read export file",
+			})
+		}
+		defer file.Close()
+
+		data, err := io.ReadAll(io.LimitReader(file, maxIMDbExportSize))
+		if err != nil {
+			log.Printf("read imdb upload error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to read export file",
+			})
+		}
+
+		films, err := parseIMDbRatings(data)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		source := auditSourceFrom(c)
+		logImport, err := startIMDbImport(c.Request().Context(), pool, queries, source, userID, films)
+		if err != nil {
+			if errors.Is(err, errUserNotFound) {
+				return c.JSON(http.StatusNotFound, map[string]string{
+					"error": "user not found",
+				})
+			}
+			if errors.Is(err, errLogImportRunning) {
+				return c.JSON(http.StatusConflict, map[string]string{
+					"error": err.Error(),
+				})
+			}
+			log.Printf("start imdb import error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to start IMDb import",
+			})
+		}
+
+		go runIMDbImportInBackground(pool, queries, source, logImport, films, seedRanking)
+
+		return c.JSON(http.StatusAccepted, toLogImportResponse(logImport))
+	})
+
+	e.GET("/api/users/:userId/imports/:importId", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		importID, err := strconv.ParseInt(c.Param("importId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid import id",
+			})
+		}
+
+		logImport, err := queries.GetLogImport(c.Request().Context(), db.GetLogImportParams{
+			ID:     importID,
+			UserID: userID,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return c.JSON(http.StatusNotFound, map[string]string{
+					"error": errLogImportNotFound.Error(),
+				})
+			}
+			log.Printf("get import error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to get import",
+			})
+		}
+
+		return c.JSON(http.StatusOK, toLogImportResponse(logImport))
+	})
+
+	e.GET("/api/users/:userId/imports/reviews", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		status := c.QueryParam("status")
+		switch status {
+		case "":
+			status = "pending"
+		case "pending", "confirmed", "dismissed":
+		default:
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "status must be pending, confirmed or dismissed",
+			})
+		}
+
+		userExists, err := queries.UserExists(c.Request().Context(), userID)
+		if err != nil {
+			log.Printf("user exists error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to verify user",
+			})
+		}
+		if !userExists {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "user not found",
+			})
+		}
+
+		reviews, err := queries.ListLogImportReviews(c.Request().Context(), db.ListLogImportReviewsParams{
+			UserID: userID,
+			Status: status,
+		})
+		if err != nil {
+			log.Printf("list import reviews error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to list import reviews",
+			})
+		}
+
+		titles, err := importCandidateTitles(c.Request().Context(), queries, reviews)
+		if err != nil {
+			log.Printf("list import candidates error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to list import reviews",
+			})
+		}
+
+		response := make([]LogImportReviewResponse, len(reviews))
+		for i, review := range reviews {
+			response[i] = toLogImportReviewResponse(review, titles)
+		}
+
+		return c.JSON(http.StatusOK, response)
+	})
+
+	e.POST("/api/users/:userId/imports/reviews/:reviewId/confirm", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		reviewID, err := strconv.ParseInt(c.Param("reviewId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid review id",
+			})
+		}
+
+		var req ConfirmLogImportReviewRequest
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid request body",
+			})
+		}
+		if req.MovieID <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "movie_id is required",
+			})
+		}
+
+		movieExists, err := queries.MovieExists(c.Request().Context(), req.MovieID)
+		if err != nil {
+			log.Printf("movie exists error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to verify movie",
+			})
+		}
+		if !movieExists {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "movie not found",
+			})
+		}
+
+		ctx := c.Request().Context()
+		var review db.LogImportReview
+		err = withUserTx(ctx, pool, queries, userID, func(qtx *db.Queries) error {
+			review, err = pendingLogImportReview(ctx, qtx, userID, reviewID)
+			if err != nil {
+				return err
+			}
+
+			logID, created, err := importFilmToLog(ctx, qtx, userID, req.MovieID, importedFilmFromReview(review))
+			if err != nil {
+				return err
+			}
+			if !created {
+				return errLogEntryExists
+			}
+
+			review, err = qtx.ResolveLogImportReview(ctx, db.ResolveLogImportReviewParams{
+				Status:  "confirmed",
+				MovieID: pgtype.Int4{Int32: req.MovieID, Valid: true},
+				LogID:   pgtype.Int8{Int64: logID, Valid: true},
+				ID:      reviewID,
+				UserID:  userID,
+			})
+			return err
+		})
+		if err != nil {
+			return logImportReviewErrorResponse(c, err, "confirm import review", "failed to confirm import review")
+		}
+
+		titles, err := importCandidateTitles(ctx, queries, []db.LogImportReview{review})
+		if err != nil {
+			log.Printf("list import candidates error: %v", err)
+			titles = map[int32]string{}
+		}
+		return c.JSON(http.StatusOK, toLogImportReviewResponse(review, titles))
+	})
+
+	e.POST("/api/users/:userId/imports/reviews/:reviewId/dismiss", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		reviewID, err := strconv.ParseInt(c.Param("reviewId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid review id",
+			})
+		}
+
+		ctx := c.Request().Context()
+		var review db.LogImportReview
+		err = withUserTx(ctx, pool, queries, userID, func(qtx *db.Queries) error {
+			if _, err := pendingLogImportReview(ctx, qtx, userID, reviewID); err != nil {
+				return err
+			}
+
+			review, err = qtx.ResolveLogImportReview(ctx, db.ResolveLogImportReviewParams{
+				Status: "dismissed",
+				ID:     reviewID,
+				UserID: userID,
+			})
+			return err
+		})
+		if err != nil {
+			return logImportReviewErrorResponse(c, err, "dismiss import review", "failed to dismiss import review")
+		}
+
+		titles, err := importCandidateTitles(ctx, queries, []db.LogImportReview{review})
+		if err != nil {
+			log.Printf("list import candidates error: %v", err)
+			titles = map[int32]string{}
+		}
+		return c.JSON(http.StatusOK, toLogImportReviewResponse(review, titles))
+	})
+}
+
+func pendingLogImportReview(ctx context.Context, qtx *db.Queries, userID, reviewID int64) (db.LogImportReview, error) {
+	review, err := qtx.GetLogImportReview(ctx, db.GetLogImportReviewParams{
+		ID:     reviewID,
+		UserID: userID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.LogImportReview{}, errImportReviewNotFound
+		}
+		return db.LogImportReview{}, err
+	}
+	if review.Status != "pending" {
+		return db.LogImportReview{}, errImportReviewResolved
+	}
+	return review, nil
+}
+
+func logImportReviewErrorResponse(c echo.Context, err error, operation, message string) error {
+	switch {
+	case errors.Is(err, errUserNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "user not found",
+		})
+	case errors.Is(err, errImportReviewNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": err.Error(),
+		})
+	case errors.Is(err, errImportReviewResolved), errors.Is(err, errLogEntryExists):
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	log.Printf("%s error: %v", operation, err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{
+		"error": message,
+	})
+}
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"errors"
 	"log"
+	"math"
+	"math/big"
 	"net/http"
 	"strconv"
 	"strings"
@@ -9,11 +12,18 @@ import (
 
 	db "github.com/seanlee/moviestack/db/sqlc"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/labstack/echo/v4"
 )
 
-func registerMovieLogRoutes(e *echo.Echo, queries *db.Queries) {
+var (
+	errInvalidRating  = errors.New("rating must be between 0.5 and 5.0 in half-star steps")
+	errLogEntryExists = errors.New("movie is already in the log")
+)
+
+func registerMovieLogRoutes(e *echo.Echo, queries *db.Queries, pool *pgxpool.Pool) {
 	e.GET("/api/users/:userId/log", func(c echo.Context) error {
 		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
 		if err != nil {
@@ -22,20 +32,21 @@ func registerMovieLogRoutes(e *echo.Echo, queries *db.Queries) {
 			})
 		}
 
-		userExists, err := queries.UserExists(c.Request().Context(), userID)
+		params, limit, err := parseMovieLogListParams(c, userID)
 		if err != nil {
-			log.Printf("user exists error: %v", err)
-			return c.JSON(http.StatusInternalServerError, map[string]string{
-				"error": "failed to verify user",
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
 			})
 		}
-		if !userExists {
-			return c.JSON(http.StatusNotFound, map[string]string{
-				"error": "user not found",
-			})
+
+		access, err := resolveLogAccess(c.Request().Context(), queries, userID, params.ViewerID)
+		if err != nil {
+			return logAccessErrorResponse(c, err)
 		}
+		params.Visibilities = access.visibilities()
+		params.ShowNotes = access.showNotes()
 
-		results, err := queries.ListMovieLogByUser(c.Request().Context(), userID)
+		results, err := listMovieLogPage(c.Request().Context(), queries, params)
 		if err != nil {
 			log.Printf("list movie log error: %v", err)
 			return c.JSON(http.StatusInternalServerError, map[string]string{
@@ -43,9 +54,23 @@ func registerMovieLogRoutes(e *echo.Echo, queries *db.Queries) {
 			})
 		}
 
-		response := make([]MovieLogResponse, len(results))
+		bandSizes, err := loadRankBandSizes(c.Request().Context(), queries, userID)
+		if err != nil {
+			log.Printf("load rank bands error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to list movie log",
+			})
+		}
+
+		response := MovieLogPageResponse{}
+		if len(results) > limit {
+			results = results[:limit]
+			nextCursor := encodeMovieLogCursor(params, results[limit-1])
+			response.NextCursor = &nextCursor
+		}
+		response.Entries = make([]MovieLogResponse, len(results))
 		for i, item := range results {
-			response[i] = toMovieLogResponse(item)
+			response.Entries[i] = toMovieLogResponse(item, bandSizes)
 		}
 
 		return c.JSON(http.StatusOK, response)
@@ -72,7 +97,7 @@ func registerMovieLogRoutes(e *echo.Echo, queries *db.Queries) {
 			})
 		}
 
-		var req UpsertMovieLogRequest
+		var req CreateMovieLogRequest
 		if err := c.Bind(&req); err != nil {
 			return c.JSON(http.StatusBadRequest, map[string]string{
 				"error": "invalid request body",
@@ -97,45 +122,299 @@ func registerMovieLogRoutes(e *echo.Echo, queries *db.Queries) {
 			})
 		}
 
-		watchedOn := pgtype.Date{Time: time.Now().UTC(), Valid: true}
-		if req.WatchedOn != nil && strings.TrimSpace(*req.WatchedOn) != "" {
-			parsedDate, err := time.Parse("2006-01-02", strings.TrimSpace(*req.WatchedOn))
+		watchedOn, err := parseWatchedOn(req.WatchedOn)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "watched_on must be in YYYY-MM-DD format",
+			})
+		}
+		note := trimmedText(req.Note)
+
+		rating, err := parseRating(req.Rating)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		newTags, err := normalizeTagNames(req.Tags)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		visibility, err := parseVisibility(req.Visibility)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		ctx := c.Request().Context()
+		var entry db.MovieLog
+		var tags []string
+		err = withUserTx(ctx, pool, queries, userID, func(qtx *db.Queries) error {
+			entry, err = qtx.CreateMovieLogEntry(ctx, db.CreateMovieLogEntryParams{
+				UserID:     userID,
+				MovieID:    req.MovieID,
+				WatchedOn:  watchedOn,
+				Note:       note,
+				Rating:     rating,
+				Visibility: visibility,
+			})
 			if err != nil {
-				return c.JSON(http.StatusBadRequest, map[string]string{
-					"error": "watched_on must be in YYYY-MM-DD format",
+				if errors.Is(err, pgx.ErrNoRows) {
+					return errLogEntryExists
+				}
+				return err
+			}
+
+			// A new entry starts with its first viewing. Rewatches are added
+			// through the viewings routes, and watched_on always mirrors the
+			// most recent viewing.
+			if err := qtx.AddFirstMovieLogViewing(ctx, db.AddFirstMovieLogViewingParams{
+				LogID:     entry.ID,
+				WatchedOn: watchedOn,
+				Note:      note,
+			}); err != nil {
+				return err
+			}
+
+			if err := addMovieLogTags(ctx, qtx, userID, entry.ID, newTags); err != nil {
+				return err
+			}
+
+			if err := recordActivity(ctx, qtx, db.RecordActivityEventParams{
+				UserID:    userID,
+				LogID:     entry.ID,
+				Kind:      "logged",
+				Note:      entry.Note,
+				Rating:    entry.Rating,
+				WatchedOn: entry.WatchedOn,
+			}); err != nil {
+				return err
+			}
+
+			tags, err = qtx.ListMovieLogTagNames(ctx, entry.ID)
+			return err
+		})
+		if err != nil {
+			if errors.Is(err, errLogEntryExists) {
+				return c.JSON(http.StatusConflict, map[string]string{
+					"error": err.Error(),
 				})
 			}
-			watchedOn = pgtype.Date{Time: parsedDate, Valid: true}
+			log.Printf("create movie log error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to save movie log entry",
+			})
+		}
+
+		c.Response().Header().Set("ETag", movieLogETag(entry.Version))
+		return c.JSON(http.StatusCreated, movieLogEntryPayload(entry, tags))
+	})
+
+	e.GET("/api/users/:userId/log/:logId", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		logID, err := strconv.ParseInt(c.Param("logId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid log id",
+			})
 		}
 
-		note := pgtype.Text{}
-		if req.Note != nil && strings.TrimSpace(*req.Note) != "" {
-			note = pgtype.Text{String: strings.TrimSpace(*req.Note), Valid: true}
+		ctx := c.Request().Context()
+		access, err := requestLogAccess(c, queries, userID)
+		if err != nil {
+			return logAccessErrorResponse(c, err)
+		}
+		if err := checkLogEntryVisible(ctx, queries, access, userID, logID); err != nil {
+			return logEntryVisibleErrorResponse(c, err)
 		}
 
-		entry, err := queries.UpsertMovieLogEntry(c.Request().Context(), db.UpsertMovieLogEntryParams{
+		results, err := queries.ListMovieLogByIDs(ctx, db.ListMovieLogByIDsParams{
+			ShowNotes: access.showNotes(),
+			ViewerID:  viewerID(c),
 			UserID:    userID,
-			MovieID:   req.MovieID,
-			WatchedOn: watchedOn,
-			Note:      note,
+			LogIds:    []int64{logID},
 		})
 		if err != nil {
-			log.Printf("upsert movie log error: %v", err)
+			log.Printf("get movie log error: %v", err)
 			return c.JSON(http.StatusInternalServerError, map[string]string{
-				"error": "failed to save movie log entry",
+				"error": "failed to load movie log entry",
+			})
+		}
+		if len(results) == 0 {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": errLogEntryNotFound.Error(),
+			})
+		}
+
+		bandSizes, err := loadRankBandSizes(ctx, queries, userID)
+		if err != nil {
+			log.Printf("load rank bands error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to load movie log entry",
+			})
+		}
+
+		c.Response().Header().Set("ETag", movieLogETag(results[0].Version))
+		return c.JSON(http.StatusOK, toMovieLogResponse(results[0], bandSizes))
+	})
+
+	e.PATCH("/api/users/:userId/log/:logId", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		logID, err := strconv.ParseInt(c.Param("logId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid log id",
+			})
+		}
+
+		var req PatchMovieLogRequest
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid request body",
+			})
+		}
+
+		// watched_on mirrors the latest viewing, so it can be moved but never
+		// cleared.
+		var watchedOn pgtype.Date
+		if req.WatchedOn.Set {
+			if req.WatchedOn.Value == nil || strings.TrimSpace(*req.WatchedOn.Value) == "" {
+				return c.JSON(http.StatusBadRequest, map[string]string{
+					"error": "watched_on cannot be cleared",
+				})
+			}
+			watchedOn, err = parseWatchedOn(req.WatchedOn.Value)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{
+					"error": "watched_on must be in YYYY-MM-DD format",
+				})
+			}
+		}
+
+		rating, err := parseRating(req.Rating.Value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
 			})
 		}
 
-		return c.JSON(http.StatusOK, map[string]any{
-			"log_id":        entry.ID,
-			"user_id":       entry.UserID,
-			"movie_id":      entry.MovieID,
-			"watched_on":    dateISO(entry.WatchedOn),
-			"note":          textPtr(entry.Note),
-			"rank_position": int4Ptr(entry.RankPosition),
-			"created_at":    timestamptzRFC3339(entry.CreatedAt),
-			"updated_at":    timestamptzRFC3339(entry.UpdatedAt),
+		visibility, err := parseVisibility(req.Visibility.Value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		var newTags []string
+		if req.Tags.Set && req.Tags.Value != nil {
+			newTags, err = normalizeTagNames(*req.Tags.Value)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{
+					"error": err.Error(),
+				})
+			}
+		}
+
+		ctx := c.Request().Context()
+		ifMatch := c.Request().Header.Get("If-Match")
+		var entry db.MovieLog
+		var tags []string
+		err = withUserTx(ctx, pool, queries, userID, func(qtx *db.Queries) error {
+			if err := requireMovieLogMatch(ctx, qtx, userID, logID, ifMatch); err != nil {
+				return err
+			}
+
+			entry, err = qtx.PatchMovieLogEntry(ctx, db.PatchMovieLogEntryParams{
+				SetNote:       req.Note.Set,
+				Note:          trimmedText(req.Note.Value),
+				SetRating:     req.Rating.Set,
+				Rating:        rating,
+				SetVisibility: req.Visibility.Set,
+				Visibility:    visibility,
+				ID:            logID,
+				UserID:        userID,
+			})
+			if err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return errLogEntryNotFound
+				}
+				return err
+			}
+
+			if req.WatchedOn.Set {
+				if err := qtx.SetLatestMovieLogViewingDate(ctx, db.SetLatestMovieLogViewingDateParams{
+					WatchedOn: watchedOn,
+					LogID:     logID,
+				}); err != nil {
+					return err
+				}
+
+				// Syncing bumps the version again, so the ETag has to come
+				// from here rather than from the patch above.
+				synced, err := qtx.SyncMovieLogWatchedOn(ctx, logID)
+				if err != nil {
+					return err
+				}
+				entry.WatchedOn = synced.WatchedOn
+				entry.Version = synced.Version
+			}
+
+			if req.Tags.Set {
+				if err := replaceMovieLogTags(ctx, qtx, userID, logID, newTags); err != nil {
+					return err
+				}
+			}
+
+			if req.Note.Set && entry.Note.Valid {
+				if err := recordActivity(ctx, qtx, db.RecordActivityEventParams{
+					UserID: userID,
+					LogID:  logID,
+					Kind:   "noted",
+					Note:   entry.Note,
+				}); err != nil {
+					return err
+				}
+			}
+
+			tags, err = qtx.ListMovieLogTagNames(ctx, logID)
+			return err
 		})
+		if err != nil {
+			if errors.Is(err, errUserNotFound) || errors.Is(err, errLogEntryNotFound) {
+				return c.JSON(http.StatusNotFound, map[string]string{
+					"error": "movie log entry not found",
+				})
+			}
+			if errors.Is(err, errPreconditionFailed) {
+				return c.JSON(http.StatusPreconditionFailed, map[string]string{
+					"error": err.Error(),
+				})
+			}
+			log.Printf("patch movie log error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to update movie log entry",
+			})
+		}
+
+		c.Response().Header().Set("ETag", movieLogETag(entry.Version))
+		return c.JSON(http.StatusOK, movieLogEntryPayload(entry, tags))
 	})
 
 	e.DELETE("/api/users/:userId/log/:logId", func(c echo.Context) error {
@@ -153,23 +432,354 @@ func registerMovieLogRoutes(e *echo.Echo, queries *db.Queries) {
 			})
 		}
 
-		rowsAffected, err := queries.DeleteMovieLogEntry(c.Request().Context(), db.DeleteMovieLogEntryParams{
-			ID:     logID,
-			UserID: userID,
+		ctx := c.Request().Context()
+		ifMatch := c.Request().Header.Get("If-Match")
+		err = withUserTx(ctx, pool, queries, userID, func(qtx *db.Queries) error {
+			if err := requireMovieLogMatch(ctx, qtx, userID, logID, ifMatch); err != nil {
+				return err
+			}
+
+			entry, err := qtx.GetMovieLogRankEntry(ctx, db.GetMovieLogRankEntryParams{
+				ID:     logID,
+				UserID: userID,
+			})
+			if err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return errLogEntryNotFound
+				}
+				return err
+			}
+
+			if entry.RankPosition.Valid {
+				if err := removeFromRank(ctx, qtx, userID, logID, entry.RankPosition.Int32); err != nil {
+					return err
+				}
+			}
+
+			deleted, err := qtx.DeleteMovieLogEntry(ctx, db.DeleteMovieLogEntryParams{
+				ID:     logID,
+				UserID: userID,
+			})
+			if err != nil {
+				return err
+			}
+			if err := recordAudit(ctx, qtx, auditSourceFrom(c), auditEvent{
+				Action:     auditLogDelete,
+				TargetType: "movie_log",
+				TargetID:   auditTarget(logID),
+				Before:     deleted,
+			}); err != nil {
+				return err
+			}
+
+			return qtx.DeleteUnusedTags(ctx, userID)
 		})
 		if err != nil {
+			if errors.Is(err, errUserNotFound) || errors.Is(err, errLogEntryNotFound) {
+				return c.JSON(http.StatusNotFound, map[string]string{
+					"error": "movie log entry not found",
+				})
+			}
+			if errors.Is(err, errPreconditionFailed) {
+				return c.JSON(http.StatusPreconditionFailed, map[string]string{
+					"error": err.Error(),
+				})
+			}
 			log.Printf("delete movie log error: %v", err)
 			return c.JSON(http.StatusInternalServerError, map[string]string{
 				"error": "failed to delete movie log entry",
 			})
 		}
 
-		if rowsAffected == 0 {
-			return c.JSON(http.StatusNotFound, map[string]string{
-				"error": "movie log entry not found",
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	e.GET("/api/users/:userId/log/rank", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
 			})
 		}
 
-		return c.NoContent(http.StatusNoContent)
+		access, err := requestLogAccess(c, queries, userID)
+		if err != nil {
+			return logAccessErrorResponse(c, err)
+		}
+
+		entries, etag, err := listVisibleRankEntries(c.Request().Context(), queries, userID, access)
+		if err != nil {
+			log.Printf("list ranking error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to list ranking",
+			})
+		}
+
+		if etag != "" {
+			c.Response().Header().Set("ETag", etag)
+		}
+		return c.JSON(http.StatusOK, entries)
+	})
+
+	e.PUT("/api/users/:userId/log/rank", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		var req ReplaceRankOrderRequest
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid request body",
+			})
+		}
+
+		seen := make(map[int64]bool, len(req.LogIDs))
+		for _, logID := range req.LogIDs {
+			if logID <= 0 || seen[logID] {
+				return c.JSON(http.StatusBadRequest, map[string]string{
+					"error": "log_ids must be distinct positive ids",
+				})
+			}
+			seen[logID] = true
+		}
+
+		ctx := c.Request().Context()
+		ifMatch := c.Request().Header.Get("If-Match")
+		var entries []RankEntryResponse
+		var etag string
+		err = withUserTx(ctx, pool, queries, userID, func(qtx *db.Queries) error {
+			if err := requireRankingMatch(ctx, qtx, userID, ifMatch); err != nil {
+				return err
+			}
+			if err := replaceRankOrder(ctx, qtx, userID, req.LogIDs); err != nil {
+				return err
+			}
+			entries, etag, err = listRankEntries(ctx, qtx, userID)
+			return err
+		})
+		if err != nil {
+			return rankingErrorResponse(c, err, "replace ranking", "failed to replace ranking")
+		}
+
+		c.Response().Header().Set("ETag", etag)
+		return c.JSON(http.StatusOK, entries)
 	})
+
+	e.PUT("/api/users/:userId/log/:logId/rank", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		logID, err := strconv.ParseInt(c.Param("logId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid log id",
+			})
+		}
+
+		var req MoveRankRequest
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid request body",
+			})
+		}
+		if req.RankPosition <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "rank_position must be greater than zero",
+			})
+		}
+		sentiment := ""
+		if req.Sentiment != nil {
+			sentiment = strings.ToLower(strings.TrimSpace(*req.Sentiment))
+			if sentimentBand(sentiment) < 0 {
+				return c.JSON(http.StatusBadRequest, map[string]string{
+					"error": "sentiment must be liked, fine or disliked",
+				})
+			}
+		}
+
+		ctx := c.Request().Context()
+		ifMatch := c.Request().Header.Get("If-Match")
+		var entries []RankEntryResponse
+		var etag string
+		err = withUserTx(ctx, pool, queries, userID, func(qtx *db.Queries) error {
+			if err := requireRankingMatch(ctx, qtx, userID, ifMatch); err != nil {
+				return err
+			}
+			position, err := moveToRank(ctx, qtx, userID, logID, sentiment, req.RankPosition)
+			if err != nil {
+				return err
+			}
+			if err := recordRankActivity(ctx, qtx, userID, logID, position); err != nil {
+				return err
+			}
+			entries, etag, err = listRankEntries(ctx, qtx, userID)
+			return err
+		})
+		if err != nil {
+			return rankingErrorResponse(c, err, "move rank", "failed to move log entry")
+		}
+
+		c.Response().Header().Set("ETag", etag)
+		return c.JSON(http.StatusOK, entries)
+	})
+
+	e.POST("/api/users/:userId/log/:logId/rank/swap", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		logID, err := strconv.ParseInt(c.Param("logId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid log id",
+			})
+		}
+
+		var req SwapRankRequest
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid request body",
+			})
+		}
+		if req.OtherLogID <= 0 || req.OtherLogID == logID {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "other_log_id must name a different log entry",
+			})
+		}
+
+		ctx := c.Request().Context()
+		ifMatch := c.Request().Header.Get("If-Match")
+		var entries []RankEntryResponse
+		var etag string
+		err = withUserTx(ctx, pool, queries, userID, func(qtx *db.Queries) error {
+			if err := requireRankingMatch(ctx, qtx, userID, ifMatch); err != nil {
+				return err
+			}
+			if err := swapRanks(ctx, qtx, userID, logID, req.OtherLogID); err != nil {
+				return err
+			}
+			entries, etag, err = listRankEntries(ctx, qtx, userID)
+			return err
+		})
+		if err != nil {
+			return rankingErrorResponse(c, err, "swap ranks", "failed to swap log entries")
+		}
+
+		c.Response().Header().Set("ETag", etag)
+		return c.JSON(http.StatusOK, entries)
+	})
+
+	e.DELETE("/api/users/:userId/log/:logId/rank", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		logID, err := strconv.ParseInt(c.Param("logId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid log id",
+			})
+		}
+
+		ctx := c.Request().Context()
+		ifMatch := c.Request().Header.Get("If-Match")
+		var entries []RankEntryResponse
+		var etag string
+		err = withUserTx(ctx, pool, queries, userID, func(qtx *db.Queries) error {
+			if err := requireRankingMatch(ctx, qtx, userID, ifMatch); err != nil {
+				return err
+			}
+			entry, err := qtx.GetMovieLogRankEntry(ctx, db.GetMovieLogRankEntryParams{
+				ID:     logID,
+				UserID: userID,
+			})
+			if err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return errLogEntryNotFound
+				}
+				return err
+			}
+			if !entry.RankPosition.Valid {
+				return errLogEntryNotRanked
+			}
+
+			if err := removeFromRank(ctx, qtx, userID, logID, entry.RankPosition.Int32); err != nil {
+				return err
+			}
+			entries, etag, err = listRankEntries(ctx, qtx, userID)
+			return err
+		})
+		if err != nil {
+			return rankingErrorResponse(c, err, "unrank log entry", "failed to remove log entry from ranking")
+		}
+
+		c.Response().Header().Set("ETag", etag)
+		return c.JSON(http.StatusOK, entries)
+	})
+}
+
+func movieLogEntryPayload(entry db.MovieLog, tags []string) map[string]any {
+	return map[string]any{
+		"log_id":        entry.ID,
+		"user_id":       entry.UserID,
+		"movie_id":      entry.MovieID,
+		"watched_on":    dateISO(entry.WatchedOn),
+		"note":          textPtr(entry.Note),
+		"rank_position": int4Ptr(entry.RankPosition),
+		"sentiment":     textPtr(entry.Sentiment),
+		"rating":        numericFloatPtr(entry.Rating),
+		"tags":          tagNames(tags),
+		"visibility":    textPtr(entry.Visibility),
+		"version":       entry.Version,
+		"created_at":    timestamptzRFC3339(entry.CreatedAt),
+		"updated_at":    timestamptzRFC3339(entry.UpdatedAt),
+	}
+}
+
+// parseWatchedOn reads an optional YYYY-MM-DD date, defaulting to today (UTC).
+func parseWatchedOn(raw *string) (pgtype.Date, error) {
+	if raw == nil || strings.TrimSpace(*raw) == "" {
+		return pgtype.Date{Time: time.Now().UTC(), Valid: true}, nil
+	}
+
+	parsedDate, err := time.Parse("2006-01-02", strings.TrimSpace(*raw))
+	if err != nil {
+		return pgtype.Date{}, err
+	}
+	return pgtype.Date{Time: parsedDate, Valid: true}, nil
+}
+
+// trimmedText turns an optional string into a nullable column value, treating
+// blank input as NULL.
+func trimmedText(raw *string) pgtype.Text {
+	if raw == nil || strings.TrimSpace(*raw) == "" {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: strings.TrimSpace(*raw), Valid: true}
+}
+
+// parseRating validates an optional half-star rating between 0.5 and 5.0.
+func parseRating(raw *float64) (pgtype.Numeric, error) {
+	if raw == nil {
+		return pgtype.Numeric{}, nil
+	}
+
+	halfStars := *raw * 2
+	if halfStars < 1 || halfStars > 10 || halfStars != math.Trunc(halfStars) {
+		return pgtype.Numeric{}, errInvalidRating
+	}
+	return pgtype.Numeric{Int: big.NewInt(int64(halfStars) * 5), Exp: -1, Valid: true}, nil
 }
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	errViewingNotFound = errors.New("viewing not found")
+	errLastViewing     = errors.New("log entry must keep at least one viewing")
+)
+
+func registerMovieLogViewingRoutes(e *echo.Echo, queries *db.Queries, pool *pgxpool.Pool) {
+	e.GET("/api/users/:userId/log/:logId/viewings", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		logID, err := strconv.ParseInt(c.Param("logId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid log id",
+			})
+		}
+
+		access, err := requestLogAccess(c, queries, userID)
+		if err != nil {
+			return logAccessErrorResponse(c, err)
+		}
+		if err := checkLogEntryVisible(c.Request().Context(), queries, access, userID, logID); err != nil {
+			return logEntryVisibleErrorResponse(c, err)
+		}
+
+		results, err := queries.ListMovieLogViewings(c.Request().Context(), logID)
+		if err != nil {
+			log.Printf("list viewings error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to list viewings",
+			})
+		}
+
+		response := make([]MovieLogViewingResponse, len(results))
+		for i, viewing := range results {
+			if !access.showNotes() {
+				viewing.Note = pgtype.Text{}
+			}
+			response[i] = toMovieLogViewingResponse(viewing)
+		}
+
+		return c.JSON(http.StatusOK, response)
+	})
+
+	e.POST("/api/users/:userId/log/:logId/viewings", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		logID, err := strconv.ParseInt(c.Param("logId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid log id",
+			})
+		}
+
+		var req CreateMovieLogViewingRequest
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid request body",
+			})
+		}
+
+		watchedOn, err := parseWatchedOn(req.WatchedOn)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "watched_on must be in YYYY-MM-DD format",
+			})
+		}
+
+		ctx := c.Request().Context()
+		ifMatch := c.Request().Header.Get("If-Match")
+		var viewing db.MovieLogViewing
+		var version int64
+		err = withUserTx(ctx, pool, queries, userID, func(qtx *db.Queries) error {
+			if err := requireMovieLogMatch(ctx, qtx, userID, logID, ifMatch); err != nil {
+				return err
+			}
+
+			viewing, err = qtx.CreateMovieLogViewing(ctx, db.CreateMovieLogViewingParams{
+				LogID:     logID,
+				WatchedOn: watchedOn,
+				Note:      trimmedText(req.Note),
+			})
+			if err != nil {
+				return err
+			}
+
+			synced, err := qtx.SyncMovieLogWatchedOn(ctx, logID)
+			if err != nil {
+				return err
+			}
+			version = synced.Version
+
+			return recordActivity(ctx, qtx, db.RecordActivityEventParams{
+				UserID:    userID,
+				LogID:     logID,
+				Kind:      "rewatched",
+				Note:      viewing.Note,
+				WatchedOn: viewing.WatchedOn,
+			})
+		})
+		if err != nil {
+			if errors.Is(err, errUserNotFound) || errors.Is(err, errLogEntryNotFound) {
+				return c.JSON(http.StatusNotFound, map[string]string{
+					"error": "movie log entry not found",
+				})
+			}
+			if errors.Is(err, errPreconditionFailed) {
+				return c.JSON(http.StatusPreconditionFailed, map[string]string{
+					"error": err.Error(),
+				})
+			}
+			log.Printf("create viewing error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to save viewing",
+			})
+		}
+
+		c.Response().Header().Set("ETag", movieLogETag(version))
+		return c.JSON(http.StatusCreated, toMovieLogViewingResponse(viewing))
+	})
+
+	e.DELETE("/api/users/:userId/log/:logId/viewings/:viewingId", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		logID, err := strconv.ParseInt(c.Param("logId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid log id",
+			})
+		}
+
+		viewingID, err := strconv.ParseInt(c.Param("viewingId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid viewing id",
+			})
+		}
+
+		ctx := c.Request().Context()
+		ifMatch := c.Request().Header.Get("If-Match")
+		var version int64
+		err = withUserTx(ctx, pool, queries, userID, func(qtx *db.Queries) error {
+			if err := requireMovieLogMatch(ctx, qtx, userID, logID, ifMatch); err != nil {
+				return err
+			}
+
+			viewingCount, err := qtx.CountMovieLogViewings(ctx, logID)
+			if err != nil {
+				return err
+			}
+
+			rowsAffected, err := qtx.DeleteMovieLogViewing(ctx, db.DeleteMovieLogViewingParams{
+				ID:    viewingID,
+				LogID: logID,
+			})
+			if err != nil {
+				return err
+			}
+			if rowsAffected == 0 {
+				return errViewingNotFound
+			}
+			if viewingCount <= 1 {
+				return errLastViewing
+			}
+
+			synced, err := qtx.SyncMovieLogWatchedOn(ctx, logID)
+			if err != nil {
+				return err
+			}
+			version = synced.Version
+			return nil
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, errUserNotFound), errors.Is(err, errLogEntryNotFound):
+				return c.JSON(http.StatusNotFound, map[string]string{
+					"error": "movie log entry not found",
+				})
+			case errors.Is(err, errViewingNotFound):
+				return c.JSON(http.StatusNotFound, map[string]string{
+					"error": "viewing not found",
+				})
+			case errors.Is(err, errLastViewing):
+				return c.JSON(http.StatusConflict, map[string]string{
+					"error": "cannot delete the only viewing; delete the log entry instead",
+				})
+			case errors.Is(err, errPreconditionFailed):
+				return c.JSON(http.StatusPreconditionFailed, map[string]string{
+					"error": err.Error(),
+				})
+			}
+			log.Printf("delete viewing error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to delete viewing",
+			})
+		}
+
+		c.Response().Header().Set("ETag", movieLogETag(version))
+		return c.NoContent(http.StatusNoContent)
+	})
+}
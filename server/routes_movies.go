@@ -13,7 +13,7 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func registerMovieRoutes(e *echo.Echo, queries *db.Queries, pool *pgxpool.Pool, importState *movieImportJobState, dataDir string) {
+func registerMovieRoutes(e *echo.Echo, queries *db.Queries, pool *pgxpool.Pool, importState, imdbIDImportState *movieImportJobState, dataDir string) {
 	e.GET("/api/movies/search", func(c echo.Context) error {
 		q := c.QueryParam("q")
 		if q == "" {
@@ -74,8 +74,22 @@ func registerMovieRoutes(e *echo.Echo, queries *db.Queries, pool *pgxpool.Pool,
 			})
 		}
 		log.Printf("movie import started: source_file=%s", sourceFile)
+		source := auditSourceFrom(c)
+		recordAuditOrLog(queries, source, auditEvent{
+			Action:     auditImportStart,
+			TargetType: "movie_import",
+			After:      importState.snapshot(),
+		})
 
 		go func() {
+			defer func() {
+				recordAuditOrLog(queries, source, auditEvent{
+					Action:     auditImportFinish,
+					TargetType: "movie_import",
+					After:      importState.snapshot(),
+				})
+			}()
+
 			if err := runMovieIDsImport(context.Background(), pool, sourceFile, importState); err != nil {
 				snapshot := importState.snapshot()
 				importState.finishFailure(snapshot.ProcessedRows, snapshot.UpsertedRows, err.Error())
@@ -110,4 +124,80 @@ func registerMovieRoutes(e *echo.Echo, queries *db.Queries, pool *pgxpool.Pool,
 	e.GET("/api/admin/movies/import/status", func(c echo.Context) error {
 		return c.JSON(http.StatusOK, importState.snapshot())
 	})
+
+	e.POST("/api/admin/movies/imdb-ids/import", func(c echo.Context) error {
+		if imdbIDImportState.isRunning() {
+			return c.JSON(http.StatusConflict, map[string]string{
+				"error": "imdb id import is already running",
+			})
+		}
+
+		sourceFile, err := findLatestIMDbIDsCSV(dataDir)
+		if err != nil {
+			if errors.Is(err, errNoIMDbIDFiles) {
+				return c.JSON(http.StatusNotFound, map[string]string{
+					"error": "no imdb_ids .csv files found in data directory",
+				})
+			}
+			log.Printf("find latest imdb ids file error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to locate latest imdb id data file",
+			})
+		}
+
+		if !imdbIDImportState.startIfIdle(sourceFile) {
+			return c.JSON(http.StatusConflict, map[string]string{
+				"error": "imdb id import is already running",
+			})
+		}
+		log.Printf("imdb id import started: source_file=%s", sourceFile)
+		source := auditSourceFrom(c)
+		recordAuditOrLog(queries, source, auditEvent{
+			Action:     auditImportStart,
+			TargetType: "imdb_id_import",
+			After:      imdbIDImportState.snapshot(),
+		})
+
+		go func() {
+			defer func() {
+				recordAuditOrLog(queries, source, auditEvent{
+					Action:     auditImportFinish,
+					TargetType: "imdb_id_import",
+					After:      imdbIDImportState.snapshot(),
+				})
+			}()
+
+			if err := runIMDbIDsImport(context.Background(), pool, sourceFile, imdbIDImportState); err != nil {
+				snapshot := imdbIDImportState.snapshot()
+				imdbIDImportState.finishFailure(snapshot.ProcessedRows, snapshot.UpsertedRows, err.Error())
+				log.Printf(
+					"imdb id import failed: source_file=%s processed_rows=%d err=%v",
+					sourceFile,
+					snapshot.ProcessedRows,
+					err,
+				)
+				return
+			}
+
+			snapshot := imdbIDImportState.snapshot()
+			imdbIDImportState.finishSuccess(snapshot.ProcessedRows, snapshot.UpsertedRows)
+			log.Printf(
+				"imdb id import succeeded: source_file=%s processed_rows=%d upserted_rows=%d",
+				sourceFile,
+				snapshot.ProcessedRows,
+				snapshot.UpsertedRows,
+			)
+		}()
+
+		status := imdbIDImportState.snapshot()
+		return c.JSON(http.StatusAccepted, map[string]any{
+			"status":      status.Status,
+			"started_at":  status.StartedAt,
+			"source_file": status.SourceFile,
+		})
+	})
+
+	e.GET("/api/admin/movies/imdb-ids/import/status", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, imdbIDImportState.snapshot())
+	})
 }
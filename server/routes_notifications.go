@@ -0,0 +1,256 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/labstack/echo/v4"
+)
+
+func registerNotificationRoutes(e *echo.Echo, queries *db.Queries, pool *pgxpool.Pool) {
+	e.GET("/api/users/:userId/notifications", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		params, limit, err := parseNotificationParams(c, userID)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		userExists, err := queries.UserExists(c.Request().Context(), userID)
+		if err != nil {
+			log.Printf("user exists error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to verify user",
+			})
+		}
+		if !userExists {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "user not found",
+			})
+		}
+
+		rows, err := queries.ListNotifications(c.Request().Context(), params)
+		if err != nil {
+			log.Printf("list notifications error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to list notifications",
+			})
+		}
+
+		response := NotificationPageResponse{}
+		if len(rows) > limit {
+			rows = rows[:limit]
+			nextCursor := encodeTimelineCursor(rows[limit-1].CreatedAt, rows[limit-1].ID)
+			response.NextCursor = &nextCursor
+		}
+		response.Notifications = make([]NotificationResponse, len(rows))
+		for i, row := range rows {
+			response.Notifications[i] = toNotificationResponse(row)
+		}
+
+		return c.JSON(http.StatusOK, response)
+	})
+
+	e.GET("/api/users/:userId/notifications/unread-count", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		userExists, err := queries.UserExists(c.Request().Context(), userID)
+		if err != nil {
+			log.Printf("user exists error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to verify user",
+			})
+		}
+		if !userExists {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "user not found",
+			})
+		}
+
+		count, err := queries.CountUnreadNotifications(c.Request().Context(), userID)
+		if err != nil {
+			log.Printf("count unread notifications error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to count unread notifications",
+			})
+		}
+
+		return c.JSON(http.StatusOK, UnreadNotificationCountResponse{UnreadCount: count})
+	})
+
+	e.POST("/api/users/:userId/notifications/:notificationId/read", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		notificationID, err := strconv.ParseInt(c.Param("notificationId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid notification id",
+			})
+		}
+
+		marked, err := queries.MarkNotificationRead(c.Request().Context(), db.MarkNotificationReadParams{
+			ID:     notificationID,
+			UserID: userID,
+		})
+		if err != nil {
+			log.Printf("mark notification read error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to mark notification read",
+			})
+		}
+		if marked == 0 {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": errNotificationNotFound.Error(),
+			})
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	e.POST("/api/users/:userId/notifications/read-all", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		userExists, err := queries.UserExists(c.Request().Context(), userID)
+		if err != nil {
+			log.Printf("user exists error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to verify user",
+			})
+		}
+		if !userExists {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "user not found",
+			})
+		}
+
+		marked, err := queries.MarkAllNotificationsRead(c.Request().Context(), userID)
+		if err != nil {
+			log.Printf("mark all notifications read error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to mark notifications read",
+			})
+		}
+
+		return c.JSON(http.StatusOK, MarkNotificationsReadResponse{MarkedCount: marked})
+	})
+
+	e.GET("/api/users/:userId/notifications/preferences", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		userExists, err := queries.UserExists(c.Request().Context(), userID)
+		if err != nil {
+			log.Printf("user exists error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to verify user",
+			})
+		}
+		if !userExists {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "user not found",
+			})
+		}
+
+		preferences, err := notificationPreferences(c.Request().Context(), queries, userID)
+		if err != nil {
+			log.Printf("get notification preferences error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to get notification preferences",
+			})
+		}
+
+		return c.JSON(http.StatusOK, NotificationPreferencesResponse{Preferences: preferences})
+	})
+
+	// Preferences left out of the request keep their current setting.
+	e.PUT("/api/users/:userId/notifications/preferences", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		var req UpdateNotificationPreferencesRequest
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid request body",
+			})
+		}
+
+		current, err := notificationPreferences(c.Request().Context(), queries, userID)
+		if err != nil {
+			log.Printf("get notification preferences error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to update notification preferences",
+			})
+		}
+		for kind := range req.Preferences {
+			if _, ok := current[kind]; !ok {
+				return c.JSON(http.StatusBadRequest, map[string]string{
+					"error": fmt.Sprintf("unknown notification type %q", kind),
+				})
+			}
+		}
+
+		ctx := c.Request().Context()
+		err = withUserTx(ctx, pool, queries, userID, func(qtx *db.Queries) error {
+			for kind, enabled := range req.Preferences {
+				if err := qtx.SetNotificationPreference(ctx, db.SetNotificationPreferenceParams{
+					UserID:  userID,
+					Kind:    kind,
+					Enabled: enabled,
+				}); err != nil {
+					return err
+				}
+				current[kind] = enabled
+			}
+			return nil
+		})
+		if err != nil {
+			if errors.Is(err, errUserNotFound) {
+				return c.JSON(http.StatusNotFound, map[string]string{
+					"error": "user not found",
+				})
+			}
+			log.Printf("update notification preferences error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to update notification preferences",
+			})
+		}
+
+		return c.JSON(http.StatusOK, NotificationPreferencesResponse{Preferences: current})
+	})
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/labstack/echo/v4"
+)
+
+// registerOIDCRoutes serves single sign-on through the configured OpenID
+// Connect provider. auth is nil when none is configured.
+func registerOIDCRoutes(e *echo.Echo, queries *db.Queries, pool *pgxpool.Pool, auth *oidcAuth, secureCookies bool) {
+	e.GET("/api/auth/oidc", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, OIDCStatusResponse{
+			Enabled: auth != nil,
+		})
+	})
+
+	e.GET("/api/auth/oidc/login", func(c echo.Context) error {
+		if auth == nil {
+			return oidcDisabledResponse(c)
+		}
+
+		url, login, err := auth.startLogin()
+		if err != nil {
+			log.Printf("start oidc login error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to start sign-in",
+			})
+		}
+
+		c.SetCookie(oidcLoginCookie(login, secureCookies))
+		return c.Redirect(http.StatusFound, url)
+	})
+
+	e.GET("/api/auth/oidc/callback", func(c echo.Context) error {
+		if auth == nil {
+			return oidcDisabledResponse(c)
+		}
+
+		login := readOIDCLoginCookie(c)
+		c.SetCookie(clearOIDCLoginCookie(secureCookies))
+
+		if providerError := c.QueryParam("error"); providerError != "" {
+			message := c.QueryParam("error_description")
+			if message == "" {
+				message = providerError
+			}
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "sign-in was refused: " + message,
+			})
+		}
+
+		ctx := c.Request().Context()
+		identity, err := auth.finishLogin(ctx, login, c.QueryParam("state"), c.QueryParam("code"))
+		if err != nil {
+			if errors.Is(err, errOIDCStateMismatch) {
+				return c.JSON(http.StatusBadRequest, map[string]string{
+					"error": err.Error(),
+				})
+			}
+			log.Printf("finish oidc login error: %v", err)
+			return c.JSON(http.StatusBadGateway, map[string]string{
+				"error": "failed to verify sign-in with the identity provider",
+			})
+		}
+
+		userID, err := provisionOIDCUser(ctx, pool, queries, auditSourceFrom(c), auth.issuer, identity, viewerID(c))
+		if err != nil {
+			if errors.Is(err, errOIDCIdentityTaken) || errors.Is(err, errOIDCUsernameTaken) {
+				return c.JSON(http.StatusConflict, map[string]string{
+					"error": err.Error(),
+				})
+			}
+			log.Printf("provision oidc user error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to sign in",
+			})
+		}
+
+		if err := startSession(c, queries, userID, secureCookies); err != nil {
+			return authErrorResponse(c, err, "start session", "failed to sign in")
+		}
+		return c.Redirect(http.StatusFound, auth.postLoginURL)
+	})
+}
+
+func oidcDisabledResponse(c echo.Context) error {
+	return c.JSON(http.StatusNotFound, map[string]string{
+		"error": "single sign-on is not configured",
+	})
+}
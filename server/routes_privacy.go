@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+)
+
+func registerPrivacyRoutes(e *echo.Echo, queries *db.Queries) {
+	e.GET("/api/users/:userId/privacy", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		privacy, err := queries.GetUserPrivacy(c.Request().Context(), userID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return c.JSON(http.StatusNotFound, map[string]string{
+					"error": "user not found",
+				})
+			}
+			log.Printf("get user privacy error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to get privacy settings",
+			})
+		}
+
+		return c.JSON(http.StatusOK, PrivacySettingsResponse{
+			LogVisibility: privacy.LogVisibility,
+			HideNotes:     privacy.HideNotes,
+		})
+	})
+
+	// Settings left out of the request keep their current value.
+	e.PATCH("/api/users/:userId/privacy", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		var req UpdatePrivacySettingsRequest
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid request body",
+			})
+		}
+
+		visibility, err := parseVisibility(req.LogVisibility)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "log_visibility must be public, friends or private",
+			})
+		}
+
+		var hideNotes pgtype.Bool
+		if req.HideNotes != nil {
+			hideNotes = pgtype.Bool{Bool: *req.HideNotes, Valid: true}
+		}
+
+		privacy, err := queries.UpdateUserPrivacy(c.Request().Context(), db.UpdateUserPrivacyParams{
+			LogVisibility: visibility,
+			HideNotes:     hideNotes,
+			ID:            userID,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return c.JSON(http.StatusNotFound, map[string]string{
+					"error": "user not found",
+				})
+			}
+			log.Printf("update user privacy error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to update privacy settings",
+			})
+		}
+
+		return c.JSON(http.StatusOK, PrivacySettingsResponse{
+			LogVisibility: privacy.LogVisibility,
+			HideNotes:     privacy.HideNotes,
+		})
+	})
+}
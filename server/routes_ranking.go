@@ -0,0 +1,324 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/labstack/echo/v4"
+)
+
+func registerRankingRoutes(e *echo.Echo, queries *db.Queries, pool *pgxpool.Pool) {
+	e.GET("/api/users/:userId/rank", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		userExists, err := queries.UserExists(c.Request().Context(), userID)
+		if err != nil {
+			log.Printf("user exists error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to verify user",
+			})
+		}
+		if !userExists {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "user not found",
+			})
+		}
+
+		sessions, err := queries.ListRankSessionsByUser(c.Request().Context(), userID)
+		if err != nil {
+			log.Printf("list rank sessions error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to list rank sessions",
+			})
+		}
+
+		response := make([]RankSessionResponse, len(sessions))
+		for i, session := range sessions {
+			response[i], err = loadRankSessionResponse(c.Request().Context(), queries, session)
+			if err != nil {
+				log.Printf("load rank session error: %v", err)
+				return c.JSON(http.StatusInternalServerError, map[string]string{
+					"error": "failed to list rank sessions",
+				})
+			}
+		}
+
+		return c.JSON(http.StatusOK, response)
+	})
+
+	e.POST("/api/users/:userId/rank", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		var req StartRankSessionRequest
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid request body",
+			})
+		}
+		if req.LogID <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "log_id is required",
+			})
+		}
+		sentiment := strings.ToLower(strings.TrimSpace(req.Sentiment))
+		if sentimentBand(sentiment) < 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "sentiment must be liked, fine or disliked",
+			})
+		}
+
+		ctx := c.Request().Context()
+		var response RankSessionResponse
+		err = withUserTx(ctx, pool, queries, userID, func(qtx *db.Queries) error {
+			entry, err := qtx.GetMovieLogRankEntry(ctx, db.GetMovieLogRankEntryParams{
+				ID:     req.LogID,
+				UserID: userID,
+			})
+			if err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return errLogEntryNotFound
+				}
+				return err
+			}
+
+			// The search starts out spanning the whole band. A ranked entry
+			// keeps its place until the search ends, so abandoning the session
+			// leaves the ranking as it was.
+			response, err = advanceRankSession(ctx, qtx, userID, entry.LogID, entry.RankPosition, sentiment, 1, math.MaxInt32)
+			return err
+		})
+		if err != nil {
+			return rankingErrorResponse(c, err, "start rank session", "failed to start rank session")
+		}
+
+		return c.JSON(http.StatusCreated, response)
+	})
+
+	e.GET("/api/users/:userId/rank/:sessionId", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		sessionID, err := strconv.ParseInt(c.Param("sessionId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid session id",
+			})
+		}
+
+		session, err := queries.GetRankSession(c.Request().Context(), db.GetRankSessionParams{
+			ID:     sessionID,
+			UserID: userID,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return c.JSON(http.StatusNotFound, map[string]string{
+					"error": "rank session not found",
+				})
+			}
+			log.Printf("get rank session error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to get rank session",
+			})
+		}
+
+		response, err := loadRankSessionResponse(c.Request().Context(), queries, session)
+		if err != nil {
+			log.Printf("load rank session error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to get rank session",
+			})
+		}
+
+		return c.JSON(http.StatusOK, response)
+	})
+
+	e.POST("/api/users/:userId/rank/:sessionId/answer", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		sessionID, err := strconv.ParseInt(c.Param("sessionId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid session id",
+			})
+		}
+
+		var req RankSessionAnswerRequest
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid request body",
+			})
+		}
+		answer := strings.ToLower(strings.TrimSpace(req.Answer))
+		if answer != "better" && answer != "worse" {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "answer must be \"better\" or \"worse\"",
+			})
+		}
+
+		ctx := c.Request().Context()
+		var response RankSessionResponse
+		staleComparison := false
+		err = withUserTx(ctx, pool, queries, userID, func(qtx *db.Queries) error {
+			session, err := qtx.GetRankSession(ctx, db.GetRankSessionParams{
+				ID:     sessionID,
+				UserID: userID,
+			})
+			if err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return errRankSessionNotFound
+				}
+				return err
+			}
+
+			entry, err := qtx.GetMovieLogRankEntry(ctx, db.GetMovieLogRankEntryParams{
+				ID:     session.LogID,
+				UserID: userID,
+			})
+			if err != nil {
+				return err
+			}
+			// The band chosen when the session started, which an unranked
+			// entry does not have yet and a re-ranked one may be leaving.
+			sentiment := session.Sentiment
+
+			current, err := loadRankSessionResponse(ctx, qtx, session)
+			if err != nil {
+				return err
+			}
+
+			// The compared entry was moved out of the ranking after it was
+			// shown, so the answer cannot be applied. Pick a fresh comparison
+			// from the same range instead.
+			if current.Comparison == nil {
+				staleComparison = true
+				response, err = advanceRankSession(ctx, qtx, userID, session.LogID, entry.RankPosition, sentiment, session.LowPosition, session.HighPosition)
+				return err
+			}
+
+			low, high := session.LowPosition, session.HighPosition
+			comparedPosition := rankSearchPosition(entry.RankPosition, *current.Comparison.RankPosition)
+			if answer == "better" {
+				high = comparedPosition - 1
+			} else {
+				low = comparedPosition + 1
+			}
+
+			response, err = advanceRankSession(ctx, qtx, userID, session.LogID, entry.RankPosition, sentiment, low, high)
+			return err
+		})
+		if err != nil {
+			return rankingErrorResponse(c, err, "answer rank session", "failed to record answer")
+		}
+
+		if staleComparison {
+			return c.JSON(http.StatusConflict, map[string]any{
+				"error":   "comparison movie is no longer ranked; answer the new comparison",
+				"session": response,
+			})
+		}
+
+		return c.JSON(http.StatusOK, response)
+	})
+
+	e.DELETE("/api/users/:userId/rank/:sessionId", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		sessionID, err := strconv.ParseInt(c.Param("sessionId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid session id",
+			})
+		}
+
+		rowsAffected, err := queries.DeleteRankSession(c.Request().Context(), db.DeleteRankSessionParams{
+			ID:     sessionID,
+			UserID: userID,
+		})
+		if err != nil {
+			log.Printf("delete rank session error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to delete rank session",
+			})
+		}
+
+		if rowsAffected == 0 {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "rank session not found",
+			})
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	})
+}
+
+func rankingErrorResponse(c echo.Context, err error, operation, message string) error {
+	switch {
+	case errors.Is(err, errUserNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "user not found",
+		})
+	case errors.Is(err, errLogEntryNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "movie log entry not found",
+		})
+	case errors.Is(err, errRankSessionNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "rank session not found",
+		})
+	case errors.Is(err, errLogEntryNotRanked):
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": "movie log entry is not ranked",
+		})
+	case errors.Is(err, errRankBandMismatch):
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": "log entries are in different sentiment bands",
+		})
+	case errors.Is(err, errPreconditionFailed):
+		return c.JSON(http.StatusPreconditionFailed, map[string]string{
+			"error": err.Error(),
+		})
+	case errors.Is(err, errSentimentRequired),
+		errors.Is(err, errRankOutsideBand),
+		errors.Is(err, errRankBandOrder):
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	log.Printf("%s error: %v", operation, err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{
+		"error": message,
+	})
+}
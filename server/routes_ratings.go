@@ -0,0 +1,137 @@
+package main
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/labstack/echo/v4"
+)
+
+// defaultDisagreementThreshold is how far, in stars, a rating has to sit from
+// the rating implied by the entry's rank before the report flags it.
+const defaultDisagreementThreshold = 1.0
+
+func registerRatingRoutes(e *echo.Echo, queries *db.Queries) {
+	e.GET("/api/users/:userId/ratings/histogram", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		access, err := requestLogAccess(c, queries, userID)
+		if err != nil {
+			return logAccessErrorResponse(c, err)
+		}
+
+		results, err := queries.RatingHistogramByUser(c.Request().Context(), db.RatingHistogramByUserParams{
+			UserID:       userID,
+			Visibilities: access.visibilities(),
+		})
+		if err != nil {
+			log.Printf("rating histogram error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to build rating histogram",
+			})
+		}
+
+		// Every half-star step is reported, including the empty ones.
+		buckets := make([]RatingBucketResponse, 10)
+		for i := range buckets {
+			buckets[i].Rating = float64(i+1) / 2
+		}
+
+		response := RatingHistogramResponse{UserID: userID, Buckets: buckets}
+		var ratingSum float64
+		for _, row := range results {
+			rating := numericFloatPtr(row.Rating)
+			if rating == nil {
+				continue
+			}
+			buckets[int(*rating*2)-1].Count = row.RatingCount
+			response.RatedCount += row.RatingCount
+			ratingSum += *rating * float64(row.RatingCount)
+		}
+		if response.RatedCount > 0 {
+			average := math.Round(ratingSum/float64(response.RatedCount)*100) / 100
+			response.AverageRating = &average
+		}
+
+		return c.JSON(http.StatusOK, response)
+	})
+
+	e.GET("/api/users/:userId/ratings/disagreements", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		threshold := defaultDisagreementThreshold
+		if raw := c.QueryParam("threshold"); raw != "" {
+			threshold, err = strconv.ParseFloat(raw, 64)
+			if err != nil || threshold < 0 || threshold > 5 {
+				return c.JSON(http.StatusBadRequest, map[string]string{
+					"error": "threshold must be a number of stars between 0 and 5",
+				})
+			}
+		}
+
+		access, err := requestLogAccess(c, queries, userID)
+		if err != nil {
+			return logAccessErrorResponse(c, err)
+		}
+
+		results, err := queries.ListRankedMovieLogByUser(c.Request().Context(), userID)
+		if err != nil {
+			log.Printf("list ranked movie log error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to list ranked movie log",
+			})
+		}
+
+		// The rank-derived 0-10 score halves into the rating the entry's place
+		// in the ranking implies; entries whose stars stray from it are flagged.
+		response := []RatingDisagreementResponse{}
+		for _, item := range results {
+			if !access.canSee(item.Visibility) {
+				continue
+			}
+			rating := numericFloatPtr(item.Rating)
+			entry := toRankEntryResponse(item)
+			if rating == nil || entry.Score == nil {
+				continue
+			}
+
+			impliedRating := *entry.Score / 2
+			difference := math.Round((*rating-impliedRating)*10) / 10
+			if math.Abs(difference) < threshold {
+				continue
+			}
+
+			response = append(response, RatingDisagreementResponse{
+				LogID:         entry.LogID,
+				MovieID:       entry.MovieID,
+				OriginalTitle: entry.OriginalTitle,
+				Rating:        *rating,
+				RankPosition:  *entry.RankPosition,
+				Score:         *entry.Score,
+				RankRating:    math.Round(impliedRating*2) / 2,
+				Difference:    difference,
+			})
+		}
+
+		sort.SliceStable(response, func(i, j int) bool {
+			return math.Abs(response[i].Difference) > math.Abs(response[j].Difference)
+		})
+
+		return c.JSON(http.StatusOK, response)
+	})
+}
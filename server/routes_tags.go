@@ -0,0 +1,217 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/labstack/echo/v4"
+)
+
+func registerTagRoutes(e *echo.Echo, queries *db.Queries, pool *pgxpool.Pool) {
+	e.GET("/api/users/:userId/tags", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		access, err := requestLogAccess(c, queries, userID)
+		if err != nil {
+			return logAccessErrorResponse(c, err)
+		}
+
+		results, err := queries.ListTagsByUser(c.Request().Context(), db.ListTagsByUserParams{
+			UserID:        userID,
+			Visibilities:  access.visibilities(),
+			IncludeUnused: access.owner,
+		})
+		if err != nil {
+			log.Printf("list tags error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to list tags",
+			})
+		}
+
+		response := make([]TagResponse, len(results))
+		for i, tag := range results {
+			response[i] = TagResponse{
+				TagID:    tag.ID,
+				Name:     tag.Name,
+				LogCount: tag.LogCount,
+			}
+		}
+
+		return c.JSON(http.StatusOK, response)
+	})
+
+	e.POST("/api/users/:userId/log/:logId/tags", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		logID, err := strconv.ParseInt(c.Param("logId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid log id",
+			})
+		}
+
+		var req MovieLogTagsRequest
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid request body",
+			})
+		}
+		if len(req.Tags) == 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "tags is required",
+			})
+		}
+
+		names, err := normalizeTagNames(req.Tags)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		ctx := c.Request().Context()
+		var tags []string
+		err = withUserTx(ctx, pool, queries, userID, func(qtx *db.Queries) error {
+			entryExists, err := qtx.MovieLogEntryExists(ctx, db.MovieLogEntryExistsParams{
+				ID:     logID,
+				UserID: userID,
+			})
+			if err != nil {
+				return err
+			}
+			if !entryExists {
+				return errLogEntryNotFound
+			}
+
+			if err := addMovieLogTags(ctx, qtx, userID, logID, names); err != nil {
+				return err
+			}
+
+			if err := qtx.TouchMovieLogEntry(ctx, db.TouchMovieLogEntryParams{
+				ID:     logID,
+				UserID: userID,
+			}); err != nil {
+				return err
+			}
+
+			tags, err = qtx.ListMovieLogTagNames(ctx, logID)
+			return err
+		})
+		if err != nil {
+			if errors.Is(err, errUserNotFound) || errors.Is(err, errLogEntryNotFound) {
+				return c.JSON(http.StatusNotFound, map[string]string{
+					"error": "movie log entry not found",
+				})
+			}
+			log.Printf("add tags error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to add tags",
+			})
+		}
+
+		return c.JSON(http.StatusOK, MovieLogTagsResponse{
+			LogID: logID,
+			Tags:  tagNames(tags),
+		})
+	})
+
+	e.DELETE("/api/users/:userId/log/:logId/tags/:tag", func(c echo.Context) error {
+		userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid user id",
+			})
+		}
+
+		logID, err := strconv.ParseInt(c.Param("logId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid log id",
+			})
+		}
+
+		name, err := normalizeTagName(c.Param("tag"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		ctx := c.Request().Context()
+		var tags []string
+		err = withUserTx(ctx, pool, queries, userID, func(qtx *db.Queries) error {
+			entryExists, err := qtx.MovieLogEntryExists(ctx, db.MovieLogEntryExistsParams{
+				ID:     logID,
+				UserID: userID,
+			})
+			if err != nil {
+				return err
+			}
+			if !entryExists {
+				return errLogEntryNotFound
+			}
+
+			removed, err := qtx.RemoveMovieLogTag(ctx, db.RemoveMovieLogTagParams{
+				LogID:  logID,
+				UserID: userID,
+				Name:   name,
+			})
+			if err != nil {
+				return err
+			}
+			if removed == 0 {
+				return errTagNotFound
+			}
+
+			if err := qtx.DeleteUnusedTags(ctx, userID); err != nil {
+				return err
+			}
+
+			if err := qtx.TouchMovieLogEntry(ctx, db.TouchMovieLogEntryParams{
+				ID:     logID,
+				UserID: userID,
+			}); err != nil {
+				return err
+			}
+
+			tags, err = qtx.ListMovieLogTagNames(ctx, logID)
+			return err
+		})
+		if err != nil {
+			if errors.Is(err, errUserNotFound) || errors.Is(err, errLogEntryNotFound) {
+				return c.JSON(http.StatusNotFound, map[string]string{
+					"error": "movie log entry not found",
+				})
+			}
+			if errors.Is(err, errTagNotFound) {
+				return c.JSON(http.StatusNotFound, map[string]string{
+					"error": err.Error(),
+				})
+			}
+			log.Printf("remove tag error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to remove tag",
+			})
+		}
+
+		return c.JSON(http.StatusOK, MovieLogTagsResponse{
+			LogID: logID,
+			Tags:  tagNames(tags),
+		})
+	})
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/labstack/echo/v4"
+)
+
+// registerAPITokenRoutes manages personal access tokens. The account owner
+// guard keeps these to the signed-in user, and tokens cannot call them.
+func registerAPITokenRoutes(e *echo.Echo, queries *db.Queries) {
+	e.GET("/api/users/:userId/tokens", func(c echo.Context) error {
+		user, _ := sessionUser(c)
+		tokens, err := queries.ListAPITokens(c.Request().Context(), user.ID)
+		if err != nil {
+			log.Printf("list api tokens error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to list tokens",
+			})
+		}
+
+		response := make([]APITokenResponse, len(tokens))
+		for i, token := range tokens {
+			response[i] = toAPITokenResponse(token)
+		}
+		return c.JSON(http.StatusOK, response)
+	})
+
+	e.POST("/api/users/:userId/tokens", func(c echo.Context) error {
+		var req CreateAPITokenRequest
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid request body",
+			})
+		}
+
+		user, _ := sessionUser(c)
+		created, token, err := createAPIToken(c.Request().Context(), queries, user, req)
+		if err != nil {
+			switch {
+			case errors.Is(err, errInvalidTokenName), errors.Is(err, errInvalidTokenScopes), errors.Is(err, errInvalidTokenExpiry):
+				return c.JSON(http.StatusBadRequest, map[string]string{
+					"error": err.Error(),
+				})
+			case errors.Is(err, errTokenScopeForbidden):
+				return c.JSON(http.StatusForbidden, map[string]string{
+					"error": err.Error(),
+				})
+			}
+
+			log.Printf("create api token error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to create token",
+			})
+		}
+
+		return c.JSON(http.StatusCreated, CreatedAPITokenResponse{
+			APITokenResponse: toAPITokenResponse(created),
+			Token:            token,
+		})
+	})
+
+	e.DELETE("/api/users/:userId/tokens/:tokenId", func(c echo.Context) error {
+		tokenID, err := strconv.ParseInt(c.Param("tokenId"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "invalid token id",
+			})
+		}
+
+		user, _ := sessionUser(c)
+		deleted, err := queries.DeleteAPIToken(c.Request().Context(), db.DeleteAPITokenParams{
+			ID:     tokenID,
+			UserID: user.ID,
+		})
+		if err != nil {
+			log.Printf("revoke api token error: %v", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "failed to revoke token",
+			})
+		}
+		if deleted == 0 {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": errAPITokenNotFound.Error(),
+			})
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	})
+}
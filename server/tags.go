@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+)
+
+const maxTagLength = 50
+
+var (
+	errTagInvalid  = fmt.Errorf("tags must be 1-%d characters of letters, numbers, spaces or dashes", maxTagLength)
+	errTagNotFound = errors.New("tag not found on log entry")
+)
+
+// normalizeTagName folds a free-form tag to its stored form: lowercase, with
+// runs of spaces, dashes and underscores collapsed to a single dash, so
+// "With Family" and "with-family" are the same tag.
+func normalizeTagName(raw string) (string, error) {
+	var b strings.Builder
+	pendingDash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(raw)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if pendingDash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingDash = false
+			b.WriteRune(r)
+		case unicode.IsSpace(r) || r == '-' || r == '_':
+			pendingDash = true
+		default:
+			return "", errTagInvalid
+		}
+	}
+
+	name := b.String()
+	if name == "" || len([]rune(name)) > maxTagLength {
+		return "", errTagInvalid
+	}
+	return name, nil
+}
+
+// normalizeTagNames normalizes and de-duplicates tags, keeping first-seen
+// order. It always returns a non-nil slice so it can be passed straight to a
+// text[] query parameter.
+func normalizeTagNames(raw []string) ([]string, error) {
+	names := make([]string, 0, len(raw))
+	seen := make(map[string]bool, len(raw))
+	for _, tag := range raw {
+		name, err := normalizeTagName(tag)
+		if err != nil {
+			return nil, err
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// addMovieLogTags attaches the named tags to a log entry, creating any tags
+// the user has not used before. Names must already be normalized.
+func addMovieLogTags(ctx context.Context, qtx *db.Queries, userID, logID int64, names []string) error {
+	for _, name := range names {
+		tag, err := qtx.UpsertTag(ctx, db.UpsertTagParams{
+			UserID: userID,
+			Name:   name,
+		})
+		if err != nil {
+			return fmt.Errorf("upsert tag %q: %w", name, err)
+		}
+
+		if err := qtx.AddMovieLogTag(ctx, db.AddMovieLogTagParams{
+			LogID: logID,
+			TagID: tag.ID,
+		}); err != nil {
+			return fmt.Errorf("add tag %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// replaceMovieLogTags makes names the log entry's complete tag set and drops
+// any of the user's tags that no longer label an entry.
+func replaceMovieLogTags(ctx context.Context, qtx *db.Queries, userID, logID int64, names []string) error {
+	if err := qtx.ClearMovieLogTags(ctx, logID); err != nil {
+		return fmt.Errorf("clear tags: %w", err)
+	}
+	if err := addMovieLogTags(ctx, qtx, userID, logID, names); err != nil {
+		return err
+	}
+	if err := qtx.DeleteUnusedTags(ctx, userID); err != nil {
+		return fmt.Errorf("delete unused tags: %w", err)
+	}
+	return nil
+}
+
+// tagNames keeps an entry's tags serializing as [] rather than null.
+func tagNames(names []string) []string {
+	if names == nil {
+		return []string{}
+	}
+	return names
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v4"
+)
+
+// apiTokenPrefix marks personal access tokens so they are easy to spot in
+// scripts and secret scanners.
+const apiTokenPrefix = "ms_"
+
+const maxAPITokenNameLength = 100
+
+// apiTokenScopes are the permissions a personal access token can carry.
+var apiTokenScopes = []string{"log:read", "log:write", "admin:import"}
+
+// logScopePaths are the routes under a user that log:read and log:write
+// cover.
+var logScopePaths = []string{
+	"/api/users/:userId/log",
+	"/api/users/:userId/imports",
+	"/api/users/:userId/rank",
+	"/api/users/:userId/ratings",
+	"/api/users/:userId/tags",
+	"/api/users/:userId/archive",
+}
+
+// importScopePaths are the catalog import routes admin:import covers.
+var importScopePaths = []string{
+	"/api/admin/movies/import",
+	"/api/admin/movies/imdb-ids/import",
+}
+
+var (
+	errInvalidTokenName    = fmt.Errorf("name is required and must be at most %d characters", maxAPITokenNameLength)
+	errInvalidTokenScopes  = errors.New("scopes must be one or more of log:read, log:write and admin:import")
+	errInvalidTokenExpiry  = errors.New("expires_at must be a future RFC3339 timestamp")
+	errTokenScopeForbidden = errors.New("only admins can create tokens with the admin:import scope")
+	errAPITokenNotFound    = errors.New("token not found")
+)
+
+// tokenScopeFor says which scope an API token needs to call a route. Routes
+// that need no scope report an empty scope; ok is false for routes tokens
+// cannot call at all, such as account settings and token management.
+func tokenScopeFor(path, method string) (scope string, ok bool) {
+	switch {
+	case path == "/api/movies/search":
+		return "", true
+	case matchesPathPrefix(path, sharedUserPaths):
+		return "", false
+	case matchesPathPrefix(path, importScopePaths):
+		return "admin:import", true
+	case matchesPathPrefix(path, logScopePaths):
+		if method == http.MethodGet || method == http.MethodHead {
+			return "log:read", true
+		}
+		return "log:write", true
+	}
+	return "", false
+}
+
+// bearerToken reads a personal access token from the Authorization header.
+func bearerToken(c echo.Context) (string, bool) {
+	scheme, token, found := strings.Cut(c.Request().Header.Get(echo.HeaderAuthorization), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return token, true
+}
+
+// authenticateAPIToken signs the request in as the token's owner, as long as
+// the token is live and its scopes cover the route.
+func authenticateAPIToken(c echo.Context, queries *db.Queries, token string, next echo.HandlerFunc) error {
+	ctx := c.Request().Context()
+	row, err := queries.GetAPITokenUser(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.JSON(http.StatusUnauthorized, map[string]string{
+				"error": "invalid or expired token",
+			})
+		}
+		log.Printf("get api token user error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "failed to verify token",
+		})
+	}
+
+	scope, ok := tokenScopeFor(c.Path(), c.Request().Method)
+	if !ok {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": "tokens cannot be used for this request",
+		})
+	}
+	if scope != "" && !slices.Contains(row.Scopes, scope) {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": "token is missing the " + scope + " scope",
+		})
+	}
+
+	if err := queries.TouchAPIToken(ctx, row.TokenID); err != nil {
+		log.Printf("touch api token %d error: %v", row.TokenID, err)
+	}
+
+	c.Set(sessionUserKey, db.GetSessionUserRow{
+		ID:          row.ID,
+		Username:    row.Username,
+		DisplayName: row.DisplayName,
+		AvatarUrl:   row.AvatarUrl,
+		Role:        row.Role,
+	})
+	return next(c)
+}
+
+// createAPIToken issues a personal access token for user. The token itself is
+// only returned here; just its hash is stored.
+func createAPIToken(ctx context.Context, queries *db.Queries, user db.GetSessionUserRow, req CreateAPITokenRequest) (db.ApiToken, string, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxAPITokenNameLength {
+		return db.ApiToken{}, "", errInvalidTokenName
+	}
+
+	if len(req.Scopes) == 0 {
+		return db.ApiToken{}, "", errInvalidTokenScopes
+	}
+	scopes := slices.Clone(req.Scopes)
+	slices.Sort(scopes)
+	scopes = slices.Compact(scopes)
+	for _, scope := range scopes {
+		if !slices.Contains(apiTokenScopes, scope) {
+			return db.ApiToken{}, "", errInvalidTokenScopes
+		}
+	}
+	if slices.Contains(scopes, "admin:import") && !hasRole(user.Role, "admin") {
+		return db.ApiToken{}, "", errTokenScopeForbidden
+	}
+
+	var expiresAt pgtype.Timestamptz
+	if req.ExpiresAt != nil {
+		parsed, err := time.Parse(time.RFC3339, *req.ExpiresAt)
+		if err != nil || !parsed.After(time.Now()) {
+			return db.ApiToken{}, "", errInvalidTokenExpiry
+		}
+		expiresAt = pgtype.Timestamptz{Time: parsed, Valid: true}
+	}
+
+	secret, err := randomToken()
+	if err != nil {
+		return db.ApiToken{}, "", err
+	}
+	token := apiTokenPrefix + secret
+
+	created, err := queries.CreateAPIToken(ctx, db.CreateAPITokenParams{
+		UserID:    user.ID,
+		Name:      name,
+		TokenHash: hashToken(token),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return db.ApiToken{}, "", fmt.Errorf("create api token: %w", err)
+	}
+	return created, token, nil
+}
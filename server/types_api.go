@@ -1,5 +1,28 @@
 package main
 
+import "encoding/json"
+
+// optional records whether a JSON field was present at all, so PATCH bodies
+// can tell an omitted field from an explicit null.
+type optional[T any] struct {
+	Set   bool
+	Value *T
+}
+
+func (o *optional[T]) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	if string(data) == "null" {
+		o.Value = nil
+		return nil
+	}
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	o.Value = &value
+	return nil
+}
+
 type MovieResult struct {
 	ID            int32   `json:"id"`
 	OriginalTitle string  `json:"original_title"`
@@ -15,6 +38,7 @@ type AdminUserResponse struct {
 	DisplayName *string `json:"display_name"`
 	Bio         *string `json:"bio"`
 	AvatarURL   *string `json:"avatar_url"`
+	Role        string  `json:"role"`
 	CreatedAt   string  `json:"created_at"`
 	UpdatedAt   string  `json:"updated_at"`
 }
@@ -23,24 +47,131 @@ type CreateAdminUserRequest struct {
 	Username string `json:"username"`
 }
 
+type SetUserRoleRequest struct {
+	Role string `json:"role"`
+}
+
+type SetUserPasswordRequest struct {
+	Password string `json:"password"`
+}
+
+type AuditEventResponse struct {
+	AuditEventID  int64           `json:"audit_event_id"`
+	ActorID       *int64          `json:"actor_id"`
+	ActorUsername *string         `json:"actor_username"`
+	Action        string          `json:"action"`
+	TargetType    string          `json:"target_type"`
+	TargetID      *int64          `json:"target_id"`
+	RequestID     *string         `json:"request_id"`
+	IP            *string         `json:"ip"`
+	Before        json.RawMessage `json:"before"`
+	After         json.RawMessage `json:"after"`
+	CreatedAt     string          `json:"created_at"`
+}
+
+type AuditEventPageResponse struct {
+	Events     []AuditEventResponse `json:"events"`
+	NextCursor *string              `json:"next_cursor"`
+}
+
+type AuthCredentialsRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type OIDCStatusResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
+type AuthUserResponse struct {
+	ID          int64   `json:"id"`
+	Username    string  `json:"username"`
+	DisplayName *string `json:"display_name"`
+	AvatarURL   *string `json:"avatar_url"`
+	Role        string  `json:"role"`
+}
+
 type MovieLogResponse struct {
-	LogID         int64   `json:"log_id"`
-	UserID        int64   `json:"user_id"`
-	MovieID       int32   `json:"movie_id"`
-	OriginalTitle string  `json:"original_title"`
-	WatchedOn     string  `json:"watched_on"`
-	Note          *string `json:"note"`
-	RankPosition  *int32  `json:"rank_position"`
-	CreatedAt     string  `json:"created_at"`
-	UpdatedAt     string  `json:"updated_at"`
+	LogID         int64    `json:"log_id"`
+	UserID        int64    `json:"user_id"`
+	MovieID       int32    `json:"movie_id"`
+	OriginalTitle string   `json:"original_title"`
+	WatchedOn     string   `json:"watched_on"`
+	Note          *string  `json:"note"`
+	RankPosition  *int32   `json:"rank_position"`
+	Sentiment     *string  `json:"sentiment"`
+	Score         *float64 `json:"score"`
+	Rating        *float64 `json:"rating"`
+	ViewingCount  int64    `json:"viewing_count"`
+	Tags          []string `json:"tags"`
+	LikeCount     int64    `json:"like_count"`
+	CommentCount  int64    `json:"comment_count"`
+	LikedByMe     bool     `json:"liked_by_me"`
+	Visibility    *string  `json:"visibility"`
+	Version       int64    `json:"version"`
+	CreatedAt     string   `json:"created_at"`
+	UpdatedAt     string   `json:"updated_at"`
+}
+
+type MovieLogPageResponse struct {
+	Entries    []MovieLogResponse `json:"entries"`
+	NextCursor *string            `json:"next_cursor"`
 }
 
-type UpsertMovieLogRequest struct {
-	MovieID   int32   `json:"movie_id"`
+type MovieLogViewingResponse struct {
+	ViewingID int64   `json:"viewing_id"`
+	LogID     int64   `json:"log_id"`
+	WatchedOn string  `json:"watched_on"`
+	Note      *string `json:"note"`
+	CreatedAt string  `json:"created_at"`
+	UpdatedAt string  `json:"updated_at"`
+}
+
+type CreateMovieLogViewingRequest struct {
 	WatchedOn *string `json:"watched_on"`
 	Note      *string `json:"note"`
 }
 
+type CreateMovieLogRequest struct {
+	MovieID    int32    `json:"movie_id"`
+	WatchedOn  *string  `json:"watched_on"`
+	Note       *string  `json:"note"`
+	Rating     *float64 `json:"rating"`
+	Tags       []string `json:"tags"`
+	Visibility *string  `json:"visibility"`
+}
+
+type PatchMovieLogRequest struct {
+	WatchedOn  optional[string]   `json:"watched_on"`
+	Note       optional[string]   `json:"note"`
+	Rating     optional[float64]  `json:"rating"`
+	Tags       optional[[]string] `json:"tags"`
+	Visibility optional[string]   `json:"visibility"`
+}
+
+type RatingBucketResponse struct {
+	Rating float64 `json:"rating"`
+	Count  int64   `json:"count"`
+}
+
+type RatingHistogramResponse struct {
+	UserID        int64                  `json:"user_id"`
+	RatedCount    int64                  `json:"rated_count"`
+	AverageRating *float64               `json:"average_rating"`
+	Buckets       []RatingBucketResponse `json:"buckets"`
+}
+
+type RatingDisagreementResponse struct {
+	LogID         int64   `json:"log_id"`
+	MovieID       int32   `json:"movie_id"`
+	OriginalTitle string  `json:"original_title"`
+	Rating        float64 `json:"rating"`
+	RankPosition  int32   `json:"rank_position"`
+	Score         float64 `json:"score"`
+	RankRating    float64 `json:"rank_rating"`
+	Difference    float64 `json:"difference"`
+}
+
 type MovieIDImportRow struct {
 	ID            int32   `json:"id"`
 	OriginalTitle string  `json:"original_title"`
@@ -58,3 +189,388 @@ type ImportStatusResponse struct {
 	UpsertedRows  int64   `json:"upserted_rows"`
 	Error         string  `json:"error"`
 }
+
+type StartRankSessionRequest struct {
+	LogID     int64  `json:"log_id"`
+	Sentiment string `json:"sentiment"`
+}
+
+type RankSessionAnswerRequest struct {
+	Answer string `json:"answer"`
+}
+
+type RankEntryResponse struct {
+	LogID         int64    `json:"log_id"`
+	MovieID       int32    `json:"movie_id"`
+	OriginalTitle string   `json:"original_title"`
+	RankPosition  *int32   `json:"rank_position"`
+	Sentiment     *string  `json:"sentiment"`
+	Score         *float64 `json:"score"`
+}
+
+type RankSessionResponse struct {
+	SessionID    *int64             `json:"session_id"`
+	LogID        int64              `json:"log_id"`
+	Status       string             `json:"status"`
+	Comparison   *RankEntryResponse `json:"comparison"`
+	RankPosition *int32             `json:"rank_position"`
+}
+
+type MoveRankRequest struct {
+	RankPosition int32   `json:"rank_position"`
+	Sentiment    *string `json:"sentiment"`
+}
+
+type SwapRankRequest struct {
+	OtherLogID int64 `json:"other_log_id"`
+}
+
+type ReplaceRankOrderRequest struct {
+	LogIDs []int64 `json:"log_ids"`
+}
+
+type TagResponse struct {
+	TagID    int64  `json:"tag_id"`
+	Name     string `json:"name"`
+	LogCount int64  `json:"log_count"`
+}
+
+type MovieLogTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+type MovieLogTagsResponse struct {
+	LogID int64    `json:"log_id"`
+	Tags  []string `json:"tags"`
+}
+
+type LogImportResponse struct {
+	ImportID       int64   `json:"import_id"`
+	Source         string  `json:"source"`
+	Status         string  `json:"status"`
+	FilmCount      int32   `json:"film_count"`
+	ProcessedCount int32   `json:"processed_count"`
+	ImportedCount  int32   `json:"imported_count"`
+	QueuedCount    int32   `json:"queued_count"`
+	ExistingCount  int32   `json:"existing_count"`
+	RankedCount    int32   `json:"ranked_count"`
+	Error          *string `json:"error"`
+	CreatedAt      string  `json:"created_at"`
+	FinishedAt     *string `json:"finished_at"`
+}
+
+type ImportCandidateResponse struct {
+	MovieID       int32  `json:"movie_id"`
+	OriginalTitle string `json:"original_title"`
+}
+
+type LogImportReviewResponse struct {
+	ReviewID     int64                     `json:"review_id"`
+	ImportID     int64                     `json:"import_id"`
+	Title        string                    `json:"title"`
+	ReleaseYear  *int32                    `json:"release_year"`
+	WatchedDates []string                  `json:"watched_dates"`
+	Rating       *float64                  `json:"rating"`
+	Review       *string                   `json:"review"`
+	Candidates   []ImportCandidateResponse `json:"candidates"`
+	Status       string                    `json:"status"`
+	MovieID      *int32                    `json:"movie_id"`
+	LogID        *int64                    `json:"log_id"`
+	CreatedAt    string                    `json:"created_at"`
+	UpdatedAt    string                    `json:"updated_at"`
+}
+
+type ConfirmLogImportReviewRequest struct {
+	MovieID int32 `json:"movie_id"`
+}
+
+type MovieLogExportEntry struct {
+	LogID         int64    `json:"log_id"`
+	TMDBID        int32    `json:"tmdb_id"`
+	OriginalTitle string   `json:"original_title"`
+	WatchedOn     string   `json:"watched_on"`
+	Note          *string  `json:"note"`
+	RankPosition  *int32   `json:"rank_position"`
+	Sentiment     *string  `json:"sentiment"`
+	Score         *float64 `json:"score"`
+	Rating        *float64 `json:"rating"`
+	ViewingCount  int64    `json:"viewing_count"`
+	Viewings      []string `json:"viewings"`
+	Tags          []string `json:"tags"`
+	CreatedAt     string   `json:"created_at"`
+	UpdatedAt     string   `json:"updated_at"`
+}
+
+// AccountArchive is a whole account in one portable document. Movies are
+// identified by TMDB id and followed users by username so an archive can be
+// restored on another instance. Comments and likes are the ones the user left
+// on other people's entries, which are identified by owner and movie.
+type AccountArchive struct {
+	Format     string                  `json:"format"`
+	Version    int                     `json:"version"`
+	ExportedAt string                  `json:"exported_at"`
+	Profile    AccountArchiveProfile   `json:"profile"`
+	Entries    []AccountArchiveEntry   `json:"entries"`
+	Following  []string                `json:"following"`
+	Comments   []AccountArchiveComment `json:"comments"`
+	Likes      []AccountArchiveLike    `json:"likes"`
+}
+
+type AccountArchiveProfile struct {
+	Username      string  `json:"username"`
+	DisplayName   *string `json:"display_name"`
+	Bio           *string `json:"bio"`
+	AvatarURL     *string `json:"avatar_url"`
+	LogVisibility *string `json:"log_visibility,omitempty"`
+	HideNotes     bool    `json:"hide_notes,omitempty"`
+	CreatedAt     string  `json:"created_at"`
+}
+
+type AccountArchiveEntry struct {
+	TMDBID        int32                   `json:"tmdb_id"`
+	OriginalTitle string                  `json:"original_title"`
+	WatchedOn     string                  `json:"watched_on"`
+	Note          *string                 `json:"note"`
+	Rating        *float64                `json:"rating"`
+	Sentiment     *string                 `json:"sentiment"`
+	RankPosition  *int32                  `json:"rank_position"`
+	Tags          []string                `json:"tags"`
+	Viewings      []AccountArchiveViewing `json:"viewings"`
+	Visibility    *string                 `json:"visibility,omitempty"`
+	CreatedAt     string                  `json:"created_at"`
+}
+
+type AccountArchiveViewing struct {
+	WatchedOn string  `json:"watched_on"`
+	Note      *string `json:"note"`
+}
+
+type AccountArchiveComment struct {
+	Owner         string  `json:"owner"`
+	TMDBID        int32   `json:"tmdb_id"`
+	OriginalTitle string  `json:"original_title"`
+	ReplyTo       *string `json:"reply_to"`
+	Body          string  `json:"body"`
+	CreatedAt     string  `json:"created_at"`
+	UpdatedAt     string  `json:"updated_at"`
+}
+
+type AccountArchiveLike struct {
+	Owner         string `json:"owner"`
+	TMDBID        int32  `json:"tmdb_id"`
+	OriginalTitle string `json:"original_title"`
+	CreatedAt     string `json:"created_at"`
+}
+
+type ArchiveRestoreResponse struct {
+	EntryCount         int                       `json:"entry_count"`
+	RestoredCount      int                       `json:"restored_count"`
+	FollowRequestCount int                       `json:"follow_request_count"`
+	Unrestored         []UnrestoredEntryResponse `json:"unrestored"`
+}
+
+type UnrestoredEntryResponse struct {
+	TMDBID        int32  `json:"tmdb_id"`
+	OriginalTitle string `json:"original_title"`
+	Reason        string `json:"reason"`
+}
+
+type FriendUserResponse struct {
+	UserID      int64   `json:"user_id"`
+	Username    string  `json:"username"`
+	DisplayName *string `json:"display_name"`
+	AvatarURL   *string `json:"avatar_url"`
+	Since       string  `json:"since"`
+}
+
+type FollowRequestRequest struct {
+	UserID int64 `json:"user_id"`
+}
+
+type FriendshipResponse struct {
+	FollowerID int64  `json:"follower_id"`
+	FolloweeID int64  `json:"followee_id"`
+	Status     string `json:"status"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+}
+
+type RelationshipResponse struct {
+	UserID          int64 `json:"user_id"`
+	OtherUserID     int64 `json:"other_user_id"`
+	Following       bool  `json:"following"`
+	FollowedBy      bool  `json:"followed_by"`
+	Friends         bool  `json:"friends"`
+	RequestSent     bool  `json:"request_sent"`
+	RequestReceived bool  `json:"request_received"`
+}
+
+type FeedActorResponse struct {
+	UserID      int64   `json:"user_id"`
+	Username    string  `json:"username"`
+	DisplayName *string `json:"display_name"`
+	AvatarURL   *string `json:"avatar_url"`
+}
+
+type FeedEventResponse struct {
+	EventID       int64    `json:"event_id"`
+	LogID         int64    `json:"log_id"`
+	MovieID       int32    `json:"movie_id"`
+	OriginalTitle string   `json:"original_title"`
+	Note          *string  `json:"note"`
+	Rating        *float64 `json:"rating"`
+	Sentiment     *string  `json:"sentiment"`
+	RankPosition  *int32   `json:"rank_position"`
+	WatchedOn     *string  `json:"watched_on"`
+	CreatedAt     string   `json:"created_at"`
+}
+
+// FeedItemResponse is a run of one friend's events of the same kind, newest
+// first, summarized as one line such as "Alice ranked 3 movies".
+type FeedItemResponse struct {
+	Actor      FeedActorResponse   `json:"actor"`
+	Kind       string              `json:"kind"`
+	Count      int                 `json:"count"`
+	Summary    string              `json:"summary"`
+	LatestAt   string              `json:"latest_at"`
+	EarliestAt string              `json:"earliest_at"`
+	Events     []FeedEventResponse `json:"events"`
+}
+
+type FeedPageResponse struct {
+	Items      []FeedItemResponse `json:"items"`
+	NextCursor *string            `json:"next_cursor"`
+}
+
+type LogCommentAuthorResponse struct {
+	UserID      int64   `json:"user_id"`
+	Username    string  `json:"username"`
+	DisplayName *string `json:"display_name"`
+	AvatarURL   *string `json:"avatar_url"`
+}
+
+type LogCommentResponse struct {
+	CommentID int64                     `json:"comment_id"`
+	LogID     int64                     `json:"log_id"`
+	ParentID  *int64                    `json:"parent_id"`
+	Author    *LogCommentAuthorResponse `json:"author,omitempty"`
+	UserID    int64                     `json:"user_id"`
+	Body      string                    `json:"body"`
+	Edited    bool                      `json:"edited"`
+	Replies   []LogCommentResponse      `json:"replies"`
+	CreatedAt string                    `json:"created_at"`
+	UpdatedAt string                    `json:"updated_at"`
+}
+
+type CreateLogCommentRequest struct {
+	ParentID *int64 `json:"parent_id"`
+	Body     string `json:"body"`
+}
+
+type UpdateLogCommentRequest struct {
+	Body string `json:"body"`
+}
+
+type LogLikeResponse struct {
+	UserID      int64   `json:"user_id"`
+	Username    string  `json:"username"`
+	DisplayName *string `json:"display_name"`
+	AvatarURL   *string `json:"avatar_url"`
+	LikedAt     string  `json:"liked_at"`
+}
+
+type LogEngagementResponse struct {
+	LogID        int64 `json:"log_id"`
+	LikeCount    int64 `json:"like_count"`
+	CommentCount int64 `json:"comment_count"`
+	LikedByMe    bool  `json:"liked_by_me"`
+}
+
+type NotificationResponse struct {
+	NotificationID int64             `json:"notification_id"`
+	Kind           string            `json:"kind"`
+	Actor          FeedActorResponse `json:"actor"`
+	LogID          *int64            `json:"log_id"`
+	MovieID        *int32            `json:"movie_id"`
+	OriginalTitle  *string           `json:"original_title"`
+	CommentID      *int64            `json:"comment_id"`
+	CommentBody    *string           `json:"comment_body"`
+	Read           bool              `json:"read"`
+	ReadAt         *string           `json:"read_at"`
+	CreatedAt      string            `json:"created_at"`
+}
+
+type NotificationPageResponse struct {
+	Notifications []NotificationResponse `json:"notifications"`
+	NextCursor    *string                `json:"next_cursor"`
+}
+
+type UnreadNotificationCountResponse struct {
+	UnreadCount int64 `json:"unread_count"`
+}
+
+type MarkNotificationsReadResponse struct {
+	MarkedCount int64 `json:"marked_count"`
+}
+
+type NotificationPreferencesResponse struct {
+	Preferences map[string]bool `json:"preferences"`
+}
+
+type UpdateNotificationPreferencesRequest struct {
+	Preferences map[string]bool `json:"preferences"`
+}
+
+type PrivacySettingsResponse struct {
+	LogVisibility string `json:"log_visibility"`
+	HideNotes     bool   `json:"hide_notes"`
+}
+
+type UpdatePrivacySettingsRequest struct {
+	LogVisibility *string `json:"log_visibility"`
+	HideNotes     *bool   `json:"hide_notes"`
+}
+
+type CompareUsersResponse struct {
+	UserID             int64                    `json:"user_id"`
+	OtherUserID        int64                    `json:"other_user_id"`
+	OverlapCount       int                      `json:"overlap_count"`
+	RankedOverlapCount int                      `json:"ranked_overlap_count"`
+	KendallTau         *float64                 `json:"kendall_tau"`
+	SpearmanRho        *float64                 `json:"spearman_rho"`
+	Compatibility      *int                     `json:"compatibility"`
+	Disagreements      []RankComparisonResponse `json:"disagreements"`
+	UnseenByOther      []RankEntryResponse      `json:"unseen_by_other"`
+	UnseenByUser       []RankEntryResponse      `json:"unseen_by_user"`
+}
+
+type RankComparisonResponse struct {
+	MovieID           int32   `json:"movie_id"`
+	OriginalTitle     string  `json:"original_title"`
+	RankPosition      int32   `json:"rank_position"`
+	OtherRankPosition int32   `json:"other_rank_position"`
+	Score             float64 `json:"score"`
+	OtherScore        float64 `json:"other_score"`
+	Difference        float64 `json:"difference"`
+}
+
+type CreateAPITokenRequest struct {
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes"`
+	ExpiresAt *string  `json:"expires_at"`
+}
+
+type APITokenResponse struct {
+	TokenID    int64    `json:"token_id"`
+	Name       string   `json:"name"`
+	Scopes     []string `json:"scopes"`
+	ExpiresAt  *string  `json:"expires_at"`
+	LastUsedAt *string  `json:"last_used_at"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+// CreatedAPITokenResponse carries the token itself, which is only shown once.
+type CreatedAPITokenResponse struct {
+	APITokenResponse
+	Token string `json:"token"`
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	db "github.com/seanlee/moviestack/db/sqlc"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var errUserNotFound = errors.New("user not found")
+
+// withUserTx runs fn inside a transaction that holds the user's row lock, so
+// changes to one user's log, viewings and rank positions are serialized.
+func withUserTx(ctx context.Context, pool *pgxpool.Pool, queries *db.Queries, userID int64, fn func(qtx *db.Queries) error) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := queries.WithTx(tx)
+	if _, err := qtx.LockUser(ctx, userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errUserNotFound
+		}
+		return fmt.Errorf("lock user: %w", err)
+	}
+
+	if err := fn(qtx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}